@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/download"
 )
 
@@ -218,6 +219,22 @@ func parseVersionSpec(spec string) string {
 	return spec
 }
 
+// installBundleManifest copies the manifest listing the plugins a bundle
+// serves next to its installed binary; single plugins have none
+func installBundleManifest(binary, dest string) error {
+	manifest, err := os.ReadFile(discovery.BundlePath(binary))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read bundle manifest: %w", err)
+	}
+	if err := os.WriteFile(discovery.BundlePath(dest), manifest, 0600); err != nil {
+		return fmt.Errorf("failed to copy bundle manifest: %w", err)
+	}
+	return nil
+}
+
 func installPluginWithItem(item download.DownloadItem, _ /* repo */ string) error {
 	osName := runtime.GOOS
 	archName := runtime.GOARCH
@@ -240,7 +257,7 @@ func installPluginWithItem(item download.DownloadItem, _ /* repo */ string) erro
 		if err := os.WriteFile(item.DestPath, input, 0755); err != nil { //nolint:gosec // G306: executable files need 0755
 			return fmt.Errorf("failed to copy binary: %w", err)
 		}
-		return nil
+		return installBundleManifest(localBinary, item.DestPath)
 	}
 
 	fmt.Printf("  Downloading %s_%s_%s_%s...\n", item.Name, actualVersion, osName, archName)
@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/plugin"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// mountPluginCommands registers the commands plugins contribute as
+// plugin-cli <plugin> <command> on the root command before it runs: those of
+// the plugin that args name in place of a built-in command, or those of every
+// plugin when args ask for the root command's help or completions, so they
+// are listed like the built-in ones. Other args load no plugin and are left
+// for cobra. The returned func stops the plugins once the command has run.
+func mountPluginCommands(args []string) (func(), error) {
+	noop := func() {}
+
+	name, all := pluginCommandTarget(args)
+	if name == "" && !all {
+		return noop, nil
+	}
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	if name != "" && isBuiltinCommand(name) {
+		return noop, nil
+	}
+
+	mgr := plugin.NewManager()
+	if all {
+		return mountAllPluginCommands(mgr), nil
+	}
+
+	d, err := mgr.FindPlugin(name)
+	if errors.Is(err, discovery.ErrNotFound) {
+		return noop, nil
+	}
+	if err != nil {
+		return noop, err
+	}
+	return mountPlugin(mgr, d)
+}
+
+// mountAllPluginCommands registers the commands of every plugin that
+// contributes some. Plugins that fail to load or list their commands are
+// left out rather than failing the help they are listed in.
+func mountAllPluginCommands(mgr *plugin.Manager) func() {
+	defer mgr.Close()
+	plugins, err := mgr.ListPlugins()
+	if err != nil {
+		return func() {}
+	}
+
+	var stops []func()
+	for _, d := range plugins {
+		if isBuiltinCommand(d.Name) {
+			continue
+		}
+		if stop, err := mountPlugin(mgr, d); err == nil {
+			stops = append(stops, stop)
+		}
+	}
+	return func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+}
+
+// mountPlugin loads d and registers the commands it contributes
+func mountPlugin(mgr *plugin.Manager, d discovery.DiscoveredPlugin) (func(), error) {
+	noop := func() {}
+
+	client, p, err := mgr.Load(d)
+	if err != nil {
+		return noop, fmt.Errorf("failed to load plugin: %w", err)
+	}
+	stop := func() { mgr.Stop(client, p) }
+
+	commands, err := mgr.Commands(p)
+	if err != nil {
+		stop()
+		return noop, fmt.Errorf("failed to get the commands of plugin %s: %w", d.Name, err)
+	}
+	if len(commands) == 0 {
+		stop()
+		return noop, fmt.Errorf("plugin %s has no commands", d.Name)
+	}
+
+	cmd, err := newPluginCommandGroup(d.Name, p.(types.CommandProvider), commands)
+	if err != nil {
+		stop()
+		return noop, err
+	}
+	rootCmd.AddCommand(cmd)
+	return stop, nil
+}
+
+func isBuiltinCommand(name string) bool {
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginCommandTarget returns the command args name, which may be a plugin's,
+// or whether they ask for the root command's help or completions and so
+// need every plugin's commands
+func pluginCommandTarget(args []string) (name string, all bool) {
+	if len(args) > 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd) {
+		// The last argument is the word being completed
+		args = args[1:]
+		i := commandIndex(args)
+		if i < 0 {
+			return "", false
+		}
+		if i == len(args)-1 {
+			return "", true
+		}
+		return args[i], false
+	}
+
+	i := commandIndex(args)
+	if i < 0 {
+		return "", len(args) == 0 || slices.Contains(args, "--help") || slices.Contains(args, "-h")
+	}
+	if args[i] == "help" {
+		name, _ := pluginCommandTarget(args[i+1:])
+		return name, name == ""
+	}
+	return args[i], false
+}
+
+// commandIndex returns the index of the first argument that is not a global
+// flag or the value of one, or -1 when there is none
+func commandIndex(args []string) int {
+	flags := rootCmd.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+
+		var flag *pflag.Flag
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			flag = flags.Lookup(name)
+		} else if len(arg) == 2 {
+			flag = flags.ShorthandLookup(arg[1:])
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++ // skip the flag's value
+		}
+	}
+	return -1
+}
+
+func newPluginCommandGroup(name string, provider types.CommandProvider, commands []types.Command) (*cobra.Command, error) {
+	group := &cobra.Command{
+		Use:   name,
+		Short: fmt.Sprintf("Commands contributed by the %s plugin", name),
+	}
+
+	for _, command := range commands {
+		cmd, err := newPluginCommand(provider, command)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s has an invalid command %s: %w", name, command.Name, err)
+		}
+		group.AddCommand(cmd)
+	}
+
+	return group, nil
+}
+
+// newPluginCommand builds a command that runs a plugin command, sending it
+// every flag the command declares
+func newPluginCommand(provider types.CommandProvider, command types.Command) (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:   strings.TrimSpace(command.Name + " " + command.Usage),
+		Short: command.Short,
+		Long:  command.Long,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := make(map[string]interface{}, len(command.Flags))
+			for _, flag := range command.Flags {
+				value, err := flag.Parse(cmd.Flags().Lookup(flag.Name).Value.String())
+				if err != nil {
+					return err
+				}
+				flags[flag.Name] = value
+			}
+
+			// Failures from here on are the plugin's, not a usage mistake
+			cmd.SilenceUsage = true
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return provider.RunCommand(ctx, types.CommandRequest{
+				Name:  command.Name,
+				Args:  args,
+				Flags: flags,
+			}, cmd.OutOrStdout())
+		},
+	}
+
+	for _, flag := range command.Flags {
+		if err := addPluginFlag(cmd.Flags(), flag); err != nil {
+			return nil, err
+		}
+	}
+
+	return cmd, nil
+}
+
+// addPluginFlag defines a plugin command's flag, refusing those that would
+// clash with the CLI's own
+func addPluginFlag(flags *pflag.FlagSet, flag types.CommandFlag) error {
+	global := rootCmd.PersistentFlags()
+	if flag.Name == "help" || global.Lookup(flag.Name) != nil || flags.Lookup(flag.Name) != nil {
+		return fmt.Errorf("flag %s is already defined", flag.Name)
+	}
+	if len(flag.Shorthand) > 1 {
+		return fmt.Errorf("flag %s has a shorthand longer than one letter", flag.Name)
+	}
+	if flag.Shorthand == "h" || (flag.Shorthand != "" && (global.ShorthandLookup(flag.Shorthand) != nil || flags.ShorthandLookup(flag.Shorthand) != nil)) {
+		return fmt.Errorf("flag %s has shorthand -%s, which is already defined", flag.Name, flag.Shorthand)
+	}
+
+	value, err := flag.Parse(flag.Default)
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		flags.BoolP(flag.Name, flag.Shorthand, v, flag.Usage)
+	case int64:
+		flags.Int64P(flag.Name, flag.Shorthand, v, flag.Usage)
+	default:
+		flags.StringP(flag.Name, flag.Shorthand, flag.Default, flag.Usage)
+	}
+	return nil
+}
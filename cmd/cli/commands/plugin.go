@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -43,12 +44,14 @@ func newPluginListCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List discovered plugins with their priorities",
-		Long: `List all plugins found in the configured discovery paths.
-Shows plugin name, priority, version, and description.`,
+		Long: `List all plugins found in the configured discovery paths and the remote
+plugins in the project config. Shows plugin name, priority, version, and description.`,
 		Example: `  plugin-cli plugin list
   plugin-cli plugin list --show-paths`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			plugins, err := discovery.DiscoverPlugins(discovery.GetPluginPaths())
+			mgr := plugin.NewManager()
+			defer mgr.Close()
+			plugins, err := mgr.ListPlugins()
 			if err != nil {
 				return fmt.Errorf("failed to discover plugins: %w", err)
 			}
@@ -65,25 +68,25 @@ Shows plugin name, priority, version, and description.`,
 			}
 
 			// Load each plugin to get metadata
-			mgr := plugin.NewManager()
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-			_, _ = fmt.Fprintln(w, "NAME\tPRIORITY\tVERSION\tDESCRIPTION")
-			_, _ = fmt.Fprintln(w, "----\t--------\t-------\t-----------")
+			_, _ = fmt.Fprintln(w, "NAME\tPRIORITY\tVERSION\tHEALTH\tDESCRIPTION")
+			_, _ = fmt.Fprintln(w, "----\t--------\t-------\t------\t-----------")
 
 			for _, p := range plugins {
-				client, plugin, err := mgr.LoadPluginFromPath(p.Path)
+				client, plugin, err := mgr.Load(p)
 				if err != nil {
-					_, _ = fmt.Fprintf(w, "%s\t?\t?\tError: %v\n", p.Name, err)
+					_, _ = fmt.Fprintf(w, "%s\t?\t?\t?\tError: %v\n", p.Name, err)
 					continue
 				}
 
-				_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
 					plugin.Name(),
 					plugin.Priority(),
 					plugin.Version(),
+					healthLabel(mgr.Health(plugin)),
 					plugin.Description())
 
-				client.Kill()
+				mgr.Stop(client, plugin)
 			}
 			_ = w.Flush() // Best effort
 
@@ -121,17 +124,9 @@ func newPluginInfoCommand() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to load plugin: %w", err)
 			}
-			defer client.Kill()
-
-			metadata := types.PluginMetadata{
-				Name:          p.Name(),
-				Version:       p.Version(),
-				BuildTime:     p.BuildTime(),
-				MinCLIVersion: p.MinCLIVersion(),
-				MaxCLIVersion: p.MaxCLIVersion(),
-				Description:   p.Description(),
-				Priority:      p.Priority(),
-			}
+			defer mgr.Stop(client, p)
+
+			metadata := mgr.GetPluginMetadata(p)
 
 			if outputJSON {
 				data, err := json.MarshalIndent(metadata, "", "  ")
@@ -148,6 +143,19 @@ func newPluginInfoCommand() *cobra.Command {
 				fmt.Printf("\nCompatibility:\n")
 				fmt.Printf("  Minimum CLI Version: %s\n", metadata.MinCLIVersion)
 				fmt.Printf("  Maximum CLI Version: %s\n", metadata.MaxCLIVersion)
+				fmt.Printf("  Protocol Version: %d\n", metadata.ProtocolVersion)
+				fmt.Printf("\nHealth: %s\n", healthLabel(mgr.Health(p)))
+				fmt.Printf("\nTimeouts (0s = pipeline default):\n")
+				fmt.Printf("  ShouldExecute: %s\n", metadata.Timeouts.ShouldExecute)
+				fmt.Printf("  Process: %s\n", metadata.Timeouts.Process)
+				if deps := metadata.Dependencies; len(deps.Requires) > 0 || len(deps.Provides) > 0 {
+					fmt.Printf("\nProperties:\n")
+					fmt.Printf("  Requires: %s\n", strings.Join(deps.Requires, ", "))
+					fmt.Printf("  Provides: %s\n", strings.Join(deps.Provides, ", "))
+				}
+				if metadata.Dependencies.RequiresHost {
+					fmt.Printf("\nRequires host services (cannot be served over TCP)\n")
+				}
 			}
 
 			return nil
@@ -159,6 +167,14 @@ func newPluginInfoCommand() *cobra.Command {
 	return cmd
 }
 
+// healthLabel formats a health check for display, e.g. "degraded (disk almost full)"
+func healthLabel(health types.Health) string {
+	if health.Message == "" {
+		return string(health.Status)
+	}
+	return fmt.Sprintf("%s (%s)", health.Status, health.Message)
+}
+
 func newPluginRemoveCommand() *cobra.Command {
 	var force bool
 
@@ -3,9 +3,17 @@ package commands
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/blobstore"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/pipeline"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
@@ -19,6 +27,13 @@ type ProcessFlags struct {
 	Metadata   string
 	OutputJSON bool
 	Quiet      bool
+	Trace      bool
+	TraceOut   string
+	Explain    bool
+	Assume     []string
+	Pipeline   string
+	Attach     []string
+	SaveFiles  string
 }
 
 // NewProcessCommand creates the process command
@@ -44,7 +59,25 @@ The final context with all modifications and responses is returned.`,
   plugin-cli process "Process data" --type command --metadata '{"priority": "high"}'
 
   # Quiet mode (only show results)
-  plugin-cli process "Test message" --quiet --json`,
+  plugin-cli process "Test message" --quiet --json
+
+  # Show what each plugin decided, how long it took and what it changed
+  plugin-cli process "Convert this video" --trace
+
+  # Save the execution trace for later inspection
+  plugin-cli process "Convert this video" --trace-out trace.json
+
+  # Ask which plugins would run, without running any of them
+  plugin-cli process "Convert this video" --explain
+
+  # Run a named pipeline from plugins.json instead of routing by match rules
+  plugin-cli process "Convert this video" --pipeline discord-media
+
+  # Attach a file and save the files plugins produce
+  plugin-cli process "Convert this video" --attach holiday.mov --save-files out/
+
+  # Explain with the values an upstream plugin would produce
+  plugin-cli process "Convert this video" --explain --assume action=convert --assume media_type=video`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runProcess(args[0], flags)
@@ -59,6 +92,13 @@ The final context with all modifications and responses is returned.`,
 	cmd.Flags().StringVarP(&flags.Metadata, "metadata", "m", "", "Additional metadata as JSON")
 	cmd.Flags().BoolVar(&flags.OutputJSON, "json", false, "Output result as JSON")
 	cmd.Flags().BoolVarP(&flags.Quiet, "quiet", "q", false, "Suppress processing logs")
+	cmd.Flags().BoolVar(&flags.Trace, "trace", false, "Print a per-plugin execution trace")
+	cmd.Flags().StringVar(&flags.TraceOut, "trace-out", "", "Save the execution trace as JSON to this file")
+	cmd.Flags().BoolVar(&flags.Explain, "explain", false, "Show which plugins would run and why, without processing the event")
+	cmd.Flags().StringVarP(&flags.Pipeline, "pipeline", "p", "", "Named pipeline to run (default: route by the event's source, type and channel)")
+	cmd.Flags().StringArrayVar(&flags.Attach, "attach", nil, "File to attach to the event (repeatable)")
+	cmd.Flags().StringVar(&flags.SaveFiles, "save-files", "", "Save files produced by plugins to this directory")
+	cmd.Flags().StringArrayVar(&flags.Assume, "assume", nil, "Property an upstream plugin would set, as key=value (with --explain)")
 
 	return cmd
 }
@@ -86,24 +126,304 @@ func runProcess(content string, flags *ProcessFlags) error {
 
 	// Process through pipeline
 	p := pipeline.NewPipeline()
-	ctx, err := p.ProcessEvent(context.Background(), event)
-	if err != nil {
+	defer func() { _ = p.Close() }()
+
+	// Attachments travel to plugins as blobs, not paths
+	for _, path := range flags.Attach {
+		blob, err := p.Blobs().PutFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to attach %s: %w", path, err)
+		}
+		event.Attachments = append(event.Attachments, blob)
+	}
+
+	runCtx := context.Background()
+	if flags.Pipeline != "" {
+		runCtx = pipeline.WithPipeline(runCtx, flags.Pipeline)
+	}
+
+	if flags.Explain {
+		return runExplain(runCtx, p, event, flags)
+	}
+
+	var trace *pipeline.Trace
+	if flags.Trace || flags.TraceOut != "" {
+		trace = &pipeline.Trace{}
+		runCtx = pipeline.WithTrace(runCtx, trace)
+	}
+
+	result, err := p.Execute(runCtx, event)
+	defer func() { _ = result.Release() }()
+
+	// Save the trace even when the run failed; that's when it's most useful
+	if flags.TraceOut != "" {
+		if writeErr := trace.WriteFile(flags.TraceOut); writeErr != nil {
+			return writeErr
+		}
+		fmt.Fprintf(os.Stderr, "Trace written to %s\n", flags.TraceOut)
+	}
+
+	// Plugin failures and rejections still produce a (partial) context worth showing
+	var execErr *pipeline.ExecutionError
+	rejected := errors.Is(err, pipeline.ErrEventRejected)
+	if err != nil && !errors.As(err, &execErr) && !rejected {
 		return fmt.Errorf("pipeline processing failed: %w", err)
 	}
 
+	ctx := result.Context
+
+	// Blobs are removed once the result is released, so save them now
+	if flags.SaveFiles != "" {
+		if err := saveFiles(p.Blobs(), ctx.Responses, flags.SaveFiles); err != nil {
+			return err
+		}
+	}
+
 	// Output results
 	switch {
 	case flags.OutputJSON:
 		outputJSON(ctx)
 	case !flags.Quiet:
+		if result.Pipeline != "" {
+			fmt.Printf("\nPipeline: %s\n", result.Pipeline)
+		}
 		outputFormatted(ctx)
+		if len(result.FollowUps) > 0 {
+			fmt.Println("\nFollow-up Events:")
+			outputFollowUps(os.Stdout, result.FollowUps, "  ")
+		}
 	default:
 		outputMinimal(ctx)
 	}
 
+	if flags.Trace {
+		// Keep stdout machine-readable when JSON output was requested
+		w := os.Stdout
+		if flags.OutputJSON {
+			w = os.Stderr
+		}
+		outputTrace(w, trace)
+	}
+
+	if execErr != nil {
+		outputFailures(execErr)
+		return fmt.Errorf("pipeline completed with errors: %w", err)
+	}
+
+	if rejected {
+		return err
+	}
+
+	return nil
+}
+
+// saveFiles writes the blob of every file response into dir
+func saveFiles(blobs *blobstore.Store, responses []types.Response, dir string) error {
+	for _, resp := range responses {
+		blob, ok := resp.Blob()
+		if !ok {
+			continue
+		}
+
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		// Plugins name their files; keep them inside dir
+		path := filepath.Join(dir, filepath.Base(blob.Name))
+		if err := saveBlob(blobs, blob.Handle, path); err != nil {
+			return fmt.Errorf("failed to save %s: %w", blob.Name, err)
+		}
+		fmt.Fprintf(os.Stderr, "Saved %s from %s\n", path, resp.PluginName)
+	}
+	return nil
+}
+
+func saveBlob(blobs *blobstore.Store, handle, path string) error {
+	r, _, err := blobs.Open(handle)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600) //nolint:gosec // G304: path is inside the directory the user chose
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runExplain(ctx context.Context, p *pipeline.Pipeline, event types.Event, flags *ProcessFlags) error {
+	assumed, err := parseAssumptions(flags.Assume)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := p.Explain(ctx, event, assumed)
+	if err != nil {
+		return fmt.Errorf("failed to explain event: %w", err)
+	}
+
+	if flags.OutputJSON {
+		data, _ := json.MarshalIndent(explanation, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	outputExplanation(os.Stdout, explanation)
 	return nil
 }
 
+// parseAssumptions turns key=value pairs into Properties. Values are read as
+// JSON when possible (true, 42, {"a":1}) and as plain strings otherwise.
+func parseAssumptions(pairs []string) (map[string]interface{}, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	assumed := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		key, raw, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --assume %q: expected key=value", pair)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		assumed[key] = value
+	}
+	return assumed, nil
+}
+
+func outputExplanation(w io.Writer, explanation *pipeline.Explanation) {
+	_, _ = fmt.Fprintf(w, "\n=== Explain: %s from %s ===\n", explanation.Event.Type, explanation.Event.Source)
+	_, _ = fmt.Fprintf(w, "Content: %s\n", explanation.Event.Content)
+	if explanation.Pipeline != "" {
+		_, _ = fmt.Fprintf(w, "Pipeline: %s\n", explanation.Pipeline)
+	}
+	_, _ = fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STAGE\tPLUGIN\tINITIAL\tPREDICTED\tREASON\tPREDICTED_KEYS")
+	for _, pe := range explanation.Plugins {
+		reason := pe.Predicted.Reason
+		if pe.Error != "" {
+			reason = pe.Error
+		}
+
+		keys := "-"
+		if len(pe.PredictedKeys) > 0 {
+			keys = strings.Join(pe.PredictedKeys, ", ")
+		}
+
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			pe.Stage,
+			pe.Plugin,
+			decisionLabel(pe.Initial, pe.Error),
+			predictedLabel(pe),
+			reason,
+			keys)
+	}
+	_ = tw.Flush() // Best effort
+
+	_, _ = fmt.Fprintln(w, "\nNo plugin was run. PREDICTED assumes upstream plugins set the properties they declare,")
+	_, _ = fmt.Fprintln(w, "to their example values where they give one; use --assume to set the others.")
+}
+
+// predictedLabel is "unknown" for a plugin that declined placeholder values
+func predictedLabel(pe pipeline.PluginExplanation) string {
+	if pe.Unknown && pe.Error == "" {
+		return "unknown"
+	}
+	return decisionLabel(pe.Predicted, pe.Error)
+}
+
+func decisionLabel(decision types.ExecutionDecision, errMsg string) string {
+	switch {
+	case errMsg != "":
+		return "error"
+	case decision.ShouldExecute:
+		return "run"
+	default:
+		return "skip"
+	}
+}
+
+func outputFailures(execErr *pipeline.ExecutionError) {
+	if execErr.Aborted {
+		fmt.Fprintln(os.Stderr, "\nPipeline aborted after plugin failure:")
+	} else {
+		fmt.Fprintln(os.Stderr, "\nPlugin Failures:")
+	}
+	for _, f := range execErr.Failures {
+		outcome := string(f.Outcome)
+		if f.Code != "" {
+			outcome = fmt.Sprintf("%s (%s)", f.Outcome, f.Code)
+		}
+		if f.Attempts > 1 {
+			fmt.Fprintf(os.Stderr, "  [%s] %s after %d attempts: %s\n", f.Plugin, outcome, f.Attempts, f.Reason)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  [%s] %s: %s\n", f.Plugin, outcome, f.Reason)
+	}
+}
+
+func outputTrace(w io.Writer, trace *pipeline.Trace) {
+	_, _ = fmt.Fprintf(w, "\n=== Execution Trace (%s) ===\n", trace.Duration.Round(time.Microsecond))
+	if trace.Pipeline != "" {
+		_, _ = fmt.Fprintf(w, "Pipeline: %s\n", trace.Pipeline)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STAGE\tPLUGIN\tDECISION\tOUTCOME\tSHOULD_EXECUTE\tPROCESS\tREASON")
+	for _, pt := range trace.Plugins {
+		processTime := "-"
+		if pt.ProcessTime > 0 {
+			processTime = pt.ProcessTime.Round(time.Microsecond).String()
+		}
+
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			pt.Stage,
+			pt.Plugin,
+			decisionLabel(pt.Decision, ""),
+			pt.Outcome,
+			pt.ShouldExecuteTime.Round(time.Microsecond),
+			processTime,
+			pt.Reason)
+	}
+	_ = tw.Flush() // Best effort
+
+	for _, pt := range trace.Plugins {
+		if pt.Diff == nil || pt.Diff.Empty() {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(w, "\n[%s] changes:\n", pt.Plugin)
+		for _, key := range pt.Diff.Keys() {
+			if value, ok := pt.Diff.Set[key]; ok {
+				_, _ = fmt.Fprintf(w, "  + %s: %v\n", key, value)
+			} else {
+				_, _ = fmt.Fprintf(w, "  - %s\n", key)
+			}
+		}
+		for _, resp := range pt.Diff.Responses {
+			_, _ = fmt.Fprintf(w, "  > response (%s): %s\n", resp.Type, resp.Content)
+		}
+		if control := pt.Diff.Control; control != nil {
+			_, _ = fmt.Fprintf(w, "  ! %s: %s\n", control.Signal, control.Reason)
+		}
+	}
+
+	if trace.Error != "" {
+		_, _ = fmt.Fprintf(w, "\nError: %s\n", trace.Error)
+	}
+}
+
 func outputJSON(ctx *types.Context) {
 	data, _ := json.MarshalIndent(ctx, "", "  ")
 	fmt.Println(string(data))
@@ -112,7 +432,15 @@ func outputJSON(ctx *types.Context) {
 func outputFormatted(ctx *types.Context) {
 	fmt.Println("\n=== Pipeline Execution Complete ===")
 	fmt.Printf("Event: %s from %s\n", ctx.Event.Type, ctx.Event.Source)
-	fmt.Printf("Content: %s\n\n", ctx.Event.Content)
+	fmt.Printf("Content: %s\n", ctx.Event.Content)
+	if ctx.Halted() {
+		fmt.Printf("Control: %s by %s", ctx.Control.Signal, ctx.Control.Plugin)
+		if ctx.Control.Reason != "" {
+			fmt.Printf(" (%s)", ctx.Control.Reason)
+		}
+		fmt.Println()
+	}
+	fmt.Println()
 
 	if len(ctx.Properties) > 0 {
 		fmt.Println("Context Properties:")
@@ -134,10 +462,28 @@ func outputFormatted(ctx *types.Context) {
 	}
 }
 
+// outputFollowUps lists events emitted by plugins and what handling them produced
+func outputFollowUps(w io.Writer, followUps []pipeline.FollowUp, indent string) {
+	for _, followUp := range followUps {
+		event := followUp.Result.Context.Event
+		_, _ = fmt.Fprintf(w, "%s%s from %s: %s\n", indent, event.Type, event.Source, event.Content)
+		for _, resp := range followUp.Result.Context.Responses {
+			_, _ = fmt.Fprintf(w, "%s  [%s] %s\n", indent, resp.PluginName, resp.Content)
+		}
+		if followUp.Error != "" {
+			_, _ = fmt.Fprintf(w, "%s  Error: %s\n", indent, followUp.Error)
+		}
+		outputFollowUps(w, followUp.Result.FollowUps, indent+"  ")
+	}
+}
+
 func outputMinimal(ctx *types.Context) {
 	for _, resp := range ctx.Responses {
 		fmt.Printf("[%s] %s\n", resp.PluginName, resp.Content)
 	}
+	if ctx.Halted() {
+		fmt.Printf("[%s] %s: %s\n", ctx.Control.Plugin, ctx.Control.Signal, ctx.Control.Reason)
+	}
 }
 
 func isVerbose() bool {
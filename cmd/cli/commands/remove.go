@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
 )
 
 // NewRemoveCommand creates the remove command
@@ -79,6 +80,9 @@ func runRemove(_ *cobra.Command, args []string, keepBinary bool) error {
 		} else {
 			fmt.Printf("✓ Removed plugin binary from .plugins/\n")
 		}
+		if err := os.Remove(discovery.BundlePath(pluginPath)); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Warning: Failed to remove bundle manifest: %v\n", err)
+		}
 	}
 
 	// Update plugins.json
@@ -22,9 +22,15 @@ This architecture enables:
 	Version: "1.0.0",
 }
 
-// Execute runs the root command
+// Execute runs the root command, or a command contributed by the plugin
+// named in place of one
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	stop, err := mountPluginCommands(os.Args[1:])
+	if err == nil {
+		err = rootCmd.Execute()
+	}
+	stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -42,6 +43,7 @@ func newSimulateDiscordCommand() *cobra.Command {
 			fmt.Println()
 
 			p := pipeline.NewPipeline()
+			defer func() { _ = p.Close() }()
 			ctx, err := p.ProcessMessage(
 				context.Background(),
 				"discord",
@@ -50,12 +52,12 @@ func newSimulateDiscordCommand() *cobra.Command {
 				"general",
 			)
 
-			if err != nil {
+			if !partialResult(err) {
 				return fmt.Errorf("simulation failed: %w", err)
 			}
 
 			printSimulationResult(ctx)
-			return nil
+			return simulationError(err)
 		},
 	}
 }
@@ -73,6 +75,7 @@ func newSimulateVideoCommand() *cobra.Command {
 			fmt.Println()
 
 			p := pipeline.NewPipeline()
+			defer func() { _ = p.Close() }()
 
 			event := types.Event{
 				Type:      types.EventCommand,
@@ -89,12 +92,12 @@ func newSimulateVideoCommand() *cobra.Command {
 			}
 
 			ctx, err := p.ProcessEvent(context.Background(), event)
-			if err != nil {
+			if !partialResult(err) {
 				return fmt.Errorf("simulation failed: %w", err)
 			}
 
 			printSimulationResult(ctx)
-			return nil
+			return simulationError(err)
 		},
 	}
 }
@@ -112,6 +115,7 @@ func newSimulateTelegramCommand() *cobra.Command {
 			fmt.Println()
 
 			p := pipeline.NewPipeline()
+			defer func() { _ = p.Close() }()
 
 			event := types.Event{
 				Type:      types.EventCommand,
@@ -131,12 +135,12 @@ func newSimulateTelegramCommand() *cobra.Command {
 			}
 
 			ctx, err := p.ProcessEvent(context.Background(), event)
-			if err != nil {
+			if !partialResult(err) {
 				return fmt.Errorf("simulation failed: %w", err)
 			}
 
 			printSimulationResult(ctx)
-			return nil
+			return simulationError(err)
 		},
 	}
 }
@@ -154,6 +158,7 @@ demonstrating context enrichment and plugin cooperation.`,
 			fmt.Println()
 
 			p := pipeline.NewPipeline()
+			defer func() { _ = p.Close() }()
 
 			event := types.Event{
 				Type:      types.EventWebhook,
@@ -180,7 +185,7 @@ demonstrating context enrichment and plugin cooperation.`,
 			}
 
 			ctx, err := p.ProcessEvent(context.Background(), event)
-			if err != nil {
+			if !partialResult(err) {
 				return fmt.Errorf("simulation failed: %w", err)
 			}
 
@@ -195,11 +200,30 @@ demonstrating context enrichment and plugin cooperation.`,
 				fmt.Printf("  Final Output: %v\n", uploadURL)
 			}
 
-			return nil
+			return simulationError(err)
 		},
 	}
 }
 
+// partialResult reports whether the pipeline left a context worth showing
+// despite err: plugin failures under the continue policy and rejected events
+// still produce one
+func partialResult(err error) bool {
+	var execErr *pipeline.ExecutionError
+	return err == nil || errors.As(err, &execErr) || errors.Is(err, pipeline.ErrEventRejected)
+}
+
+// simulationError lists the plugins that failed, once the result has been
+// shown, and returns what the simulation ends with
+func simulationError(err error) error {
+	var execErr *pipeline.ExecutionError
+	if errors.As(err, &execErr) {
+		outputFailures(execErr)
+		return fmt.Errorf("simulation completed with errors: %w", err)
+	}
+	return err
+}
+
 func printSimulationResult(ctx *types.Context) {
 	fmt.Println("╔══════════════════════════════════════╗")
 	fmt.Println("║      SIMULATION RESULT               ║")
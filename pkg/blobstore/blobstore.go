@@ -0,0 +1,162 @@
+// Package blobstore holds the blobs the host and plugins pass each other by
+// handle: event attachments and the files plugins produce. Each blob is a
+// file in a temporary directory, removed when the blob is deleted or the
+// store is closed.
+package blobstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Store is safe for concurrent use. The zero value is not usable; use New.
+type Store struct {
+	mu     sync.Mutex
+	dir    string // created on the first Put
+	blobs  map[string]types.Blob
+	closed bool
+}
+
+// New returns an empty store
+func New() *Store {
+	return &Store{blobs: make(map[string]types.Blob)}
+}
+
+// Put copies r into a new blob. An empty contentType is guessed from the
+// name's extension.
+func (s *Store) Put(name, contentType string, r io.Reader) (types.Blob, error) {
+	dir, err := s.directory()
+	if err != nil {
+		return types.Blob{}, err
+	}
+
+	handle, err := newHandle()
+	if err != nil {
+		return types.Blob{}, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, handle), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return types.Blob{}, fmt.Errorf("failed to create blob: %w", err)
+	}
+	size, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return types.Blob{}, fmt.Errorf("failed to write blob %s: %w", name, err)
+	}
+
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	blob := types.Blob{Handle: handle, Name: name, ContentType: contentType, Size: size}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		_ = os.Remove(f.Name())
+		return types.Blob{}, fmt.Errorf("blob store is closed")
+	}
+	s.blobs[handle] = blob
+	return blob, nil
+}
+
+// PutFile copies the file at path into a new blob named after the file
+func (s *Store) PutFile(path string) (types.Blob, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: the CLI user names the file
+	if err != nil {
+		return types.Blob{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return s.Put(filepath.Base(path), "", f)
+}
+
+// Open returns a reader for the blob's contents
+func (s *Store) Open(handle string) (io.ReadCloser, types.Blob, error) {
+	s.mu.Lock()
+	blob, ok := s.blobs[handle]
+	dir := s.dir
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, types.Blob{}, fmt.Errorf("%w: %q", types.ErrBlobNotFound, handle)
+	}
+
+	f, err := os.Open(filepath.Join(dir, handle)) //nolint:gosec // G304: handle is a key of s.blobs
+	if err != nil {
+		return nil, types.Blob{}, fmt.Errorf("failed to open blob %s: %w", blob.Name, err)
+	}
+	return f, blob, nil
+}
+
+// Delete removes a blob; deleting one that is already gone is not an error
+func (s *Store) Delete(handle string) error {
+	s.mu.Lock()
+	_, ok := s.blobs[handle]
+	delete(s.blobs, handle)
+	dir := s.dir
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(dir, handle)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete blob %s: %w", handle, err)
+	}
+	return nil
+}
+
+// Close removes every blob; later Puts fail
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.blobs = make(map[string]types.Blob)
+	if s.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.dir)
+}
+
+func (s *Store) directory() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return "", fmt.Errorf("blob store is closed")
+	}
+	if s.dir == "" {
+		dir, err := os.MkdirTemp("", "plugin-blobs-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create blob directory: %w", err)
+		}
+		s.dir = dir
+	}
+	return s.dir, nil
+}
+
+// newHandle returns a random handle, so plugins cannot guess each other's
+// blobs or name files outside the store
+func newHandle() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate blob handle: %w", err)
+	}
+	return "blob-" + hex.EncodeToString(b), nil
+}
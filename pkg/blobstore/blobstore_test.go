@@ -0,0 +1,109 @@
+package blobstore
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestStore_PutOpen(t *testing.T) {
+	store := New()
+	defer func() { _ = store.Close() }()
+
+	blob, err := store.Put("clip.mp4", "video/mp4", strings.NewReader("frames"))
+	require.NoError(t, err)
+	assert.Equal(t, "clip.mp4", blob.Name)
+	assert.Equal(t, "video/mp4", blob.ContentType)
+	assert.Equal(t, int64(6), blob.Size)
+	assert.True(t, strings.HasPrefix(blob.Handle, "blob-"))
+
+	r, opened, err := store.Open(blob.Handle)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "frames", string(data))
+	assert.Equal(t, blob, opened)
+
+	// Content types are guessed from the name when not given
+	guessed, err := store.Put("photo.png", "", strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", guessed.ContentType)
+
+	unknown, err := store.Put("data", "", strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, "application/octet-stream", unknown.ContentType)
+}
+
+func TestStore_OpenUnknown(t *testing.T) {
+	store := New()
+	defer func() { _ = store.Close() }()
+
+	for _, handle := range []string{"blob-missing", "../../etc/passwd", ""} {
+		_, _, err := store.Open(handle)
+		assert.ErrorIs(t, err, types.ErrBlobNotFound)
+	}
+}
+
+func TestStore_PutFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0600))
+
+	store := New()
+	defer func() { _ = store.Close() }()
+
+	blob, err := store.PutFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt", blob.Name)
+	assert.Equal(t, int64(5), blob.Size)
+	assert.True(t, strings.HasPrefix(blob.ContentType, "text/plain"))
+
+	_, err = store.PutFile(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestStore_Delete(t *testing.T) {
+	store := New()
+	defer func() { _ = store.Close() }()
+
+	blob, err := store.Put("a.bin", "", strings.NewReader("a"))
+	require.NoError(t, err)
+	kept, err := store.Put("b.bin", "", strings.NewReader("b"))
+	require.NoError(t, err)
+
+	require.NoError(t, store.Delete(blob.Handle))
+	assert.NoFileExists(t, filepath.Join(store.dir, blob.Handle))
+	_, _, err = store.Open(blob.Handle)
+	assert.ErrorIs(t, err, types.ErrBlobNotFound)
+
+	// Deleting twice or an unknown handle is fine, and other blobs stay
+	assert.NoError(t, store.Delete(blob.Handle))
+	assert.NoError(t, store.Delete("blob-unknown"))
+	r, _, err := store.Open(kept.Handle)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+}
+
+func TestStore_Close(t *testing.T) {
+	store := New()
+	blob, err := store.Put("a.bin", "", strings.NewReader("a"))
+	require.NoError(t, err)
+	dir := store.dir
+
+	require.NoError(t, store.Close())
+	assert.NoDirExists(t, dir)
+
+	_, _, err = store.Open(blob.Handle)
+	assert.ErrorIs(t, err, types.ErrBlobNotFound)
+	_, err = store.Put("b.bin", "", strings.NewReader("b"))
+	assert.Error(t, err)
+
+	// Closing a store that never stored anything is fine
+	assert.NoError(t, New().Close())
+}
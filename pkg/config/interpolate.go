@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envReference matches ${VAR} and ${VAR:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Interpolate returns a copy of value with ${VAR} references in its strings,
+// at any depth, replaced by environment variables. ${VAR:-default} falls
+// back to default when VAR is unset or empty, and $${ is a literal ${. A
+// reference to an unset variable without a default is an error, so missing
+// secrets are caught before a plugin starts.
+func Interpolate(value interface{}) (interface{}, error) {
+	return interpolate(value, os.LookupEnv)
+}
+
+func interpolate(value interface{}, lookup func(string) (string, bool)) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return interpolateString(v, lookup)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			interpolated, err := interpolate(item, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			out[key] = interpolated
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			interpolated, err := interpolate(item, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = interpolated
+		}
+		return out, nil
+	default:
+		return value, nil
+	}
+}
+
+func interpolateString(s string, lookup func(string) (string, bool)) (string, error) {
+	parts := strings.Split(s, "$${")
+	for i, part := range parts {
+		var missing string
+		parts[i] = envReference.ReplaceAllStringFunc(part, func(ref string) string {
+			match := envReference.FindStringSubmatch(ref)
+			name, hasDefault, fallback := match[1], match[2] != "", match[3]
+			if value, ok := lookup(name); ok && (value != "" || !hasDefault) {
+				return value
+			}
+			if hasDefault {
+				return fallback
+			}
+			if missing == "" {
+				missing = name
+			}
+			return ""
+		})
+		if missing != "" {
+			return "", fmt.Errorf("environment variable %s is not set", missing)
+		}
+	}
+	return strings.Join(parts, "${"), nil
+}
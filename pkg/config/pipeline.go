@@ -0,0 +1,325 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Duration is a time.Duration that reads and writes as a string such as "5s"
+type Duration = types.Duration
+
+// ErrorMode selects what the pipeline does when a plugin fails
+type ErrorMode string
+
+const (
+	// ErrorModeContinue records the failure and moves on to the next plugin
+	ErrorModeContinue ErrorMode = "continue"
+	// ErrorModeFailFast aborts the event at the first failure
+	ErrorModeFailFast ErrorMode = "fail_fast"
+	// ErrorModeRetry retries Process with exponential backoff, then continues
+	ErrorModeRetry ErrorMode = "retry"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultBackoff    = Duration(100 * time.Millisecond)
+)
+
+// DefaultShutdownGrace is how long plugins get to shut down before being killed
+const DefaultShutdownGrace = Duration(5 * time.Second)
+
+// ErrorPolicy describes how plugin failures are handled
+type ErrorPolicy struct {
+	Mode       ErrorMode `json:"mode,omitempty"`
+	MaxRetries int       `json:"max_retries,omitempty"` // retry mode only
+	Backoff    Duration  `json:"backoff,omitempty"`     // initial delay, doubled per attempt
+}
+
+// Validate checks the policy for unknown modes and negative values
+func (p ErrorPolicy) Validate() error {
+	switch p.Mode {
+	case "", ErrorModeContinue, ErrorModeFailFast, ErrorModeRetry:
+	default:
+		return fmt.Errorf("unknown error policy mode: %s", p.Mode)
+	}
+	if p.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("backoff must not be negative")
+	}
+	return nil
+}
+
+// Attempts returns how many times Process may be called, including the first try
+func (p ErrorPolicy) Attempts() int {
+	if p.Mode != ErrorModeRetry {
+		return 1
+	}
+	if p.MaxRetries == 0 {
+		return 1 + DefaultMaxRetries
+	}
+	return 1 + p.MaxRetries
+}
+
+// Delay returns the backoff before the given retry (1 for the first retry)
+func (p ErrorPolicy) Delay(retry int) time.Duration {
+	backoff := p.Backoff
+	if backoff == 0 {
+		backoff = DefaultBackoff
+	}
+	return time.Duration(backoff) << (retry - 1)
+}
+
+// ExecutionMode selects how plugins are scheduled within an event
+type ExecutionMode string
+
+const (
+	// ExecutionSequential runs one plugin at a time (the default)
+	ExecutionSequential ExecutionMode = "sequential"
+	// ExecutionParallel runs independent plugins concurrently
+	ExecutionParallel ExecutionMode = "parallel"
+)
+
+const (
+	DefaultFailureThreshold  = 5
+	DefaultCooldown          = Duration(30 * time.Second)
+	DefaultRestartBackoff    = Duration(time.Second)
+	DefaultMaxRestartBackoff = Duration(time.Minute)
+)
+
+// CircuitBreaker controls how the pipeline treats plugins that keep failing
+// or whose processes keep exiting
+type CircuitBreaker struct {
+	// FailureThreshold is how many consecutive failed events open the circuit
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+
+	// Cooldown is how long an open circuit skips the plugin before it is tried again
+	Cooldown Duration `json:"cooldown,omitempty"`
+
+	// RestartBackoff is the wait before restarting a plugin process that
+	// exited again, doubled for each restart without a successful event
+	RestartBackoff    Duration `json:"restart_backoff,omitempty"`
+	MaxRestartBackoff Duration `json:"max_restart_backoff,omitempty"`
+}
+
+// WithDefaults returns a copy with unset fields filled in
+func (b CircuitBreaker) WithDefaults() CircuitBreaker {
+	if b.FailureThreshold == 0 {
+		b.FailureThreshold = DefaultFailureThreshold
+	}
+	if b.Cooldown == 0 {
+		b.Cooldown = DefaultCooldown
+	}
+	if b.RestartBackoff == 0 {
+		b.RestartBackoff = DefaultRestartBackoff
+	}
+	if b.MaxRestartBackoff == 0 {
+		b.MaxRestartBackoff = DefaultMaxRestartBackoff
+	}
+	return b
+}
+
+// Validate rejects negative values
+func (b CircuitBreaker) Validate() error {
+	if b.FailureThreshold < 0 {
+		return fmt.Errorf("failure_threshold must not be negative")
+	}
+	if b.Cooldown < 0 || b.RestartBackoff < 0 || b.MaxRestartBackoff < 0 {
+		return fmt.Errorf("circuit breaker durations must not be negative")
+	}
+	return nil
+}
+
+// RestartDelay returns how long to wait after the given restart (1 for the
+// first) before the plugin may be restarted again
+func (b CircuitBreaker) RestartDelay(restarts int) time.Duration {
+	b = b.WithDefaults()
+	delay, limit := time.Duration(b.RestartBackoff), time.Duration(b.MaxRestartBackoff)
+	for i := 1; i < restarts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// PipelineConfig holds project-level settings for the event pipeline
+type PipelineConfig struct {
+	// EventTimeout bounds the whole event across all plugins; zero means no limit
+	EventTimeout Duration `json:"event_timeout,omitempty"`
+
+	// Execution is "sequential" (default) or "parallel"
+	Execution ExecutionMode `json:"execution,omitempty"`
+
+	// ErrorPolicy applies to every plugin without its own policy
+	ErrorPolicy ErrorPolicy `json:"error_policy,omitzero"`
+
+	// CircuitBreaker takes repeatedly failing plugins out of the pipeline
+	CircuitBreaker CircuitBreaker `json:"circuit_breaker,omitzero"`
+
+	// ShutdownGrace is how long a plugin may take to shut down before its
+	// process is killed; zero means DefaultShutdownGrace
+	ShutdownGrace Duration `json:"shutdown_grace,omitempty"`
+
+	// Plugins holds per-plugin overrides keyed by discovered plugin name
+	Plugins map[string]PluginSettings `json:"plugins,omitempty"`
+
+	// Pipelines routes events to named plugin sets; the first match wins and
+	// unmatched events run through every plugin
+	Pipelines []NamedPipeline `json:"pipelines,omitempty"`
+}
+
+// NamedPipeline is an explicit, ordered plugin list for the events it matches
+type NamedPipeline struct {
+	Name    string     `json:"name"`
+	Plugins []string   `json:"plugins"` // discovered plugin names, in run order
+	Match   EventMatch `json:"match,omitzero"`
+}
+
+// EventMatch selects events. Each non-empty list must contain the event's
+// value; an empty match accepts every event.
+type EventMatch struct {
+	Types    []types.EventType `json:"types,omitempty"`
+	Sources  []string          `json:"sources,omitempty"`
+	Channels []string          `json:"channels,omitempty"`
+}
+
+// Matches reports whether the event satisfies every rule
+func (m EventMatch) Matches(event types.Event) bool {
+	return matchesAny(m.Types, event.Type) &&
+		matchesAny(m.Sources, event.Source) &&
+		matchesAny(m.Channels, event.ChannelID)
+}
+
+func matchesAny[T comparable](allowed []T, value T) bool {
+	return len(allowed) == 0 || slices.Contains(allowed, value)
+}
+
+// PipelineFor returns the first named pipeline matching the event, if any
+func (c *PipelineConfig) PipelineFor(event types.Event) (NamedPipeline, bool) {
+	for _, named := range c.Pipelines {
+		if named.Match.Matches(event) {
+			return named, true
+		}
+	}
+	return NamedPipeline{}, false
+}
+
+// NamedPipeline returns the pipeline with the given name, if configured
+func (c *PipelineConfig) NamedPipeline(name string) (NamedPipeline, bool) {
+	for _, named := range c.Pipelines {
+		if named.Name == name {
+			return named, true
+		}
+	}
+	return NamedPipeline{}, false
+}
+
+// PluginSettings overrides pipeline behavior for a single plugin
+type PluginSettings struct {
+	ShouldExecuteTimeout Duration    `json:"should_execute_timeout,omitempty"`
+	ProcessTimeout       Duration    `json:"process_timeout,omitempty"`
+	ErrorPolicy          ErrorPolicy `json:"error_policy,omitzero"`
+
+	// Config is passed to the plugin's Configure RPC when it starts; see Interpolate
+	Config map[string]interface{} `json:"config,omitempty"`
+
+	// Address connects to the plugin served at unix:///path, tcp://host:port
+	// or host:port instead of starting a local binary; see RemotePlugins
+	Address string `json:"address,omitempty"`
+
+	// TLS secures the connection to Address
+	TLS *TLSSettings `json:"tls,omitempty"`
+}
+
+// PluginSettings returns the overrides configured for a plugin, if any
+func (c *PipelineConfig) PluginSettings(name string) PluginSettings {
+	if c == nil || c.Plugins == nil {
+		return PluginSettings{}
+	}
+	return c.Plugins[name]
+}
+
+// PluginConfig returns the plugin's config with environment variables
+// interpolated. Plugins without a config block get an empty map.
+func (c *PipelineConfig) PluginConfig(name string) (map[string]interface{}, error) {
+	interpolated, err := Interpolate(c.PluginSettings(name).Config)
+	if err != nil {
+		return nil, fmt.Errorf("config of plugin %s: %w", name, err)
+	}
+	config, _ := interpolated.(map[string]interface{})
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	return config, nil
+}
+
+// ErrorPolicyFor returns the plugin's own error policy if it sets a mode,
+// otherwise the pipeline-wide one
+func (c *PipelineConfig) ErrorPolicyFor(name string) ErrorPolicy {
+	if settings := c.PluginSettings(name); settings.ErrorPolicy.Mode != "" {
+		return settings.ErrorPolicy
+	}
+	if c == nil {
+		return ErrorPolicy{}
+	}
+	return c.ErrorPolicy
+}
+
+// GracePeriod returns the configured shutdown grace period or the default
+func (c *PipelineConfig) GracePeriod() time.Duration {
+	if c == nil || c.ShutdownGrace == 0 {
+		return time.Duration(DefaultShutdownGrace)
+	}
+	return time.Duration(c.ShutdownGrace)
+}
+
+// Validate checks the execution mode and every error policy in the pipeline config
+func (c *PipelineConfig) Validate() error {
+	switch c.Execution {
+	case "", ExecutionSequential, ExecutionParallel:
+	default:
+		return fmt.Errorf("pipeline: unknown execution mode: %s", c.Execution)
+	}
+	if c.ShutdownGrace < 0 {
+		return fmt.Errorf("pipeline: shutdown_grace must not be negative")
+	}
+	if err := c.ErrorPolicy.Validate(); err != nil {
+		return fmt.Errorf("pipeline: %w", err)
+	}
+	if err := c.CircuitBreaker.Validate(); err != nil {
+		return fmt.Errorf("pipeline: %w", err)
+	}
+	for name, settings := range c.Plugins {
+		if err := settings.ErrorPolicy.Validate(); err != nil {
+			return fmt.Errorf("pipeline plugin %s: %w", name, err)
+		}
+		if err := settings.validateRemote(); err != nil {
+			return fmt.Errorf("pipeline plugin %s: %w", name, err)
+		}
+	}
+
+	names := make(map[string]bool, len(c.Pipelines))
+	for _, named := range c.Pipelines {
+		switch {
+		case named.Name == "":
+			return fmt.Errorf("pipeline: named pipeline without a name")
+		case names[named.Name]:
+			return fmt.Errorf("pipeline: duplicate pipeline name: %s", named.Name)
+		case len(named.Plugins) == 0:
+			return fmt.Errorf("pipeline %s: no plugins listed", named.Name)
+		}
+		names[named.Name] = true
+
+		seen := make(map[string]bool, len(named.Plugins))
+		for _, plugin := range named.Plugins {
+			if seen[plugin] {
+				return fmt.Errorf("pipeline %s: plugin %s listed twice", named.Name, plugin)
+			}
+			seen[plugin] = true
+		}
+	}
+	return nil
+}
@@ -9,7 +9,8 @@ import (
 
 // PluginsConfig represents the plugins.json configuration file
 type PluginsConfig struct {
-	Plugins map[string]string `json:"plugins"` // name -> version
+	Plugins  map[string]string `json:"plugins"` // name -> version
+	Pipeline PipelineConfig    `json:"pipeline,omitzero"`
 }
 
 const PluginsConfigFile = "plugins.json"
@@ -38,9 +39,24 @@ func LoadPluginsConfig() (*PluginsConfig, error) {
 		config.Plugins = make(map[string]string)
 	}
 
+	if err := config.Pipeline.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plugins config: %w", err)
+	}
+
 	return &config, nil
 }
 
+// LoadPipelineConfig loads the pipeline settings from plugins.json. When the
+// file cannot be read it returns the default settings with the error, so
+// callers can warn and carry on.
+func LoadPipelineConfig() (PipelineConfig, error) {
+	projectConfig, err := LoadPluginsConfig()
+	if err != nil {
+		return PipelineConfig{}, err
+	}
+	return projectConfig.Pipeline, nil
+}
+
 // SavePluginsConfig saves the plugins configuration to plugins.json
 func SavePluginsConfig(config *PluginsConfig) error {
 	data, err := json.MarshalIndent(config, "", "  ")
@@ -159,3 +175,8 @@ func IsProjectInitialized() bool {
 func GetPluginsDirectory() string {
 	return filepath.Join(".", ".plugins")
 }
+
+// GetStateDirectory returns the path where plugins' persistent key-value data is kept
+func GetStateDirectory() string {
+	return filepath.Join(GetPluginsDirectory(), "state")
+}
@@ -4,9 +4,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
 
 func TestLoadPluginsConfig(t *testing.T) {
@@ -86,6 +88,23 @@ func TestLoadPluginsConfig(t *testing.T) {
 	}
 }
 
+func TestLoadPipelineConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.WriteFile(PluginsConfigFile, []byte(`{"pipeline": {"shutdown_grace": "3s"}}`), 0644))
+	cfg, err := LoadPipelineConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, cfg.GracePeriod())
+
+	require.NoError(t, os.WriteFile(PluginsConfigFile, []byte(`{invalid json}`), 0644))
+	cfg, err = LoadPipelineConfig()
+	assert.Error(t, err)
+	assert.Equal(t, PipelineConfig{}, cfg)
+}
+
 func TestSavePluginsConfig(t *testing.T) {
 	// Setup test directory
 	tempDir := t.TempDir()
@@ -351,3 +370,281 @@ func TestSavePluginsLock(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, lock, loaded)
 }
+
+func TestLoadPluginsConfig_Pipeline(t *testing.T) {
+	tempDir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(tempDir))
+
+	content := `{
+		"plugins": {"plugin-converter": "latest"},
+		"pipeline": {
+			"event_timeout": "1m",
+			"plugins": {
+				"converter": {"should_execute_timeout": "2s", "process_timeout": "5m"}
+			}
+		}
+	}`
+	require.NoError(t, os.WriteFile(PluginsConfigFile, []byte(content), 0644))
+
+	cfg, err := LoadPluginsConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, Duration(time.Minute), cfg.Pipeline.EventTimeout)
+	settings := cfg.Pipeline.PluginSettings("converter")
+	assert.Equal(t, Duration(2*time.Second), settings.ShouldExecuteTimeout)
+	assert.Equal(t, Duration(5*time.Minute), settings.ProcessTimeout)
+	assert.Equal(t, PluginSettings{}, cfg.Pipeline.PluginSettings("missing"))
+
+	// Round-trips as duration strings
+	require.NoError(t, SavePluginsConfig(cfg))
+	data, err := os.ReadFile(PluginsConfigFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"event_timeout": "1m0s"`)
+}
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	var d Duration
+	assert.Error(t, d.UnmarshalJSON([]byte(`"soon"`)))
+	assert.Error(t, d.UnmarshalJSON([]byte(`true`)))
+	require.NoError(t, d.UnmarshalJSON([]byte(`"150ms"`)))
+	assert.Equal(t, Duration(150*time.Millisecond), d)
+}
+
+func TestPipelineConfig_ErrorPolicyFor(t *testing.T) {
+	cfg := &PipelineConfig{
+		ErrorPolicy: ErrorPolicy{Mode: ErrorModeFailFast},
+		Plugins: map[string]PluginSettings{
+			"uploader": {ErrorPolicy: ErrorPolicy{Mode: ErrorModeRetry, MaxRetries: 5}},
+			"filter":   {ProcessTimeout: Duration(time.Second)},
+		},
+	}
+
+	assert.Equal(t, ErrorModeRetry, cfg.ErrorPolicyFor("uploader").Mode)
+	assert.Equal(t, 6, cfg.ErrorPolicyFor("uploader").Attempts())
+	assert.Equal(t, ErrorModeFailFast, cfg.ErrorPolicyFor("filter").Mode)
+	assert.Equal(t, 1, cfg.ErrorPolicyFor("filter").Attempts())
+	assert.Equal(t, ErrorModeFailFast, cfg.ErrorPolicyFor("unknown").Mode)
+}
+
+func TestErrorPolicy_Delay(t *testing.T) {
+	policy := ErrorPolicy{Mode: ErrorModeRetry, Backoff: Duration(50 * time.Millisecond)}
+
+	assert.Equal(t, 1+DefaultMaxRetries, policy.Attempts())
+	assert.Equal(t, 50*time.Millisecond, policy.Delay(1))
+	assert.Equal(t, 100*time.Millisecond, policy.Delay(2))
+	assert.Equal(t, 200*time.Millisecond, policy.Delay(3))
+	assert.Equal(t, time.Duration(DefaultBackoff), ErrorPolicy{}.Delay(1))
+}
+
+func TestCircuitBreaker_RestartDelay(t *testing.T) {
+	breaker := CircuitBreaker{RestartBackoff: Duration(time.Second), MaxRestartBackoff: Duration(5 * time.Second)}
+
+	assert.Equal(t, time.Second, breaker.RestartDelay(1))
+	assert.Equal(t, 2*time.Second, breaker.RestartDelay(2))
+	assert.Equal(t, 4*time.Second, breaker.RestartDelay(3))
+	assert.Equal(t, 5*time.Second, breaker.RestartDelay(4))
+	assert.Equal(t, 5*time.Second, breaker.RestartDelay(100))
+
+	defaults := CircuitBreaker{}.WithDefaults()
+	assert.Equal(t, DefaultFailureThreshold, defaults.FailureThreshold)
+	assert.Equal(t, DefaultCooldown, defaults.Cooldown)
+	assert.Equal(t, time.Duration(DefaultRestartBackoff), CircuitBreaker{}.RestartDelay(1))
+
+	assert.Error(t, (&PipelineConfig{CircuitBreaker: CircuitBreaker{FailureThreshold: -1}}).Validate())
+}
+
+func TestPipelineConfig_GracePeriod(t *testing.T) {
+	assert.Equal(t, time.Duration(DefaultShutdownGrace), (*PipelineConfig)(nil).GracePeriod())
+	assert.Equal(t, time.Duration(DefaultShutdownGrace), (&PipelineConfig{}).GracePeriod())
+	assert.Equal(t, 2*time.Second, (&PipelineConfig{ShutdownGrace: Duration(2 * time.Second)}).GracePeriod())
+
+	assert.Error(t, (&PipelineConfig{ShutdownGrace: Duration(-time.Second)}).Validate())
+}
+
+func TestLoadPluginsConfig_InvalidErrorPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(tempDir))
+
+	content := `{"plugins": {}, "pipeline": {"plugins": {"filter": {"error_policy": {"mode": "explode"}}}}}`
+	require.NoError(t, os.WriteFile(PluginsConfigFile, []byte(content), 0644))
+
+	_, err := LoadPluginsConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown error policy mode")
+}
+
+func TestPipelineConfig_PipelineFor(t *testing.T) {
+	tempDir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	require.NoError(t, os.Chdir(tempDir))
+
+	content := `{
+		"plugins": {},
+		"pipeline": {
+			"pipelines": [
+				{"name": "discord-media", "plugins": ["filter", "converter"], "match": {"sources": ["discord"], "channels": ["media"]}},
+				{"name": "webhooks", "plugins": ["uploader"], "match": {"types": ["webhook"]}},
+				{"name": "chat", "plugins": ["filter"], "match": {"sources": ["discord", "telegram"]}}
+			]
+		}
+	}`
+	require.NoError(t, os.WriteFile(PluginsConfigFile, []byte(content), 0644))
+
+	cfg, err := LoadPluginsConfig()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		event types.Event
+		want  string
+	}{
+		{name: "all rules match", event: types.Event{Type: types.EventMessage, Source: "discord", ChannelID: "media"}, want: "discord-media"},
+		{name: "first match wins", event: types.Event{Type: types.EventWebhook, Source: "discord", ChannelID: "media"}, want: "discord-media"},
+		{name: "type rule", event: types.Event{Type: types.EventWebhook, Source: "github"}, want: "webhooks"},
+		{name: "other channel falls through", event: types.Event{Type: types.EventMessage, Source: "discord", ChannelID: "general"}, want: "chat"},
+		{name: "no match", event: types.Event{Type: types.EventMessage, Source: "cli"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			named, ok := cfg.Pipeline.PipelineFor(tt.event)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, named.Name)
+		})
+	}
+
+	named, ok := cfg.Pipeline.NamedPipeline("webhooks")
+	require.True(t, ok)
+	assert.Equal(t, []string{"uploader"}, named.Plugins)
+}
+
+func TestPipelineConfig_ValidatePipelines(t *testing.T) {
+	tests := []struct {
+		name      string
+		pipelines []NamedPipeline
+		err       string
+	}{
+		{name: "missing name", pipelines: []NamedPipeline{{Plugins: []string{"a"}}}, err: "without a name"},
+		{name: "duplicate name", pipelines: []NamedPipeline{{Name: "x", Plugins: []string{"a"}}, {Name: "x", Plugins: []string{"b"}}}, err: "duplicate pipeline name: x"},
+		{name: "no plugins", pipelines: []NamedPipeline{{Name: "x"}}, err: "no plugins listed"},
+		{name: "plugin twice", pipelines: []NamedPipeline{{Name: "x", Plugins: []string{"a", "a"}}}, err: "plugin a listed twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &PipelineConfig{Pipelines: tt.pipelines}
+			assert.ErrorContains(t, cfg.Validate(), tt.err)
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"BUCKET": "media", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{name: "plain string", value: "media", want: "media"},
+		{name: "whole value", value: "${BUCKET}", want: "media"},
+		{name: "embedded", value: "s3://${BUCKET}/in", want: "s3://media/in"},
+		{name: "default when unset", value: "${REGION:-eu-west-1}", want: "eu-west-1"},
+		{name: "default when empty", value: "${EMPTY:-fallback}", want: "fallback"},
+		{name: "empty without default", value: "x${EMPTY}x", want: "xx"},
+		{name: "escaped", value: "$${BUCKET}", want: "${BUCKET}"},
+		{name: "non-strings untouched", value: float64(3), want: float64(3)},
+		{
+			name:  "nested",
+			value: map[string]interface{}{"keys": []interface{}{"${BUCKET}", true}, "inner": map[string]interface{}{"b": "${BUCKET}"}},
+			want:  map[string]interface{}{"keys": []interface{}{"media", true}, "inner": map[string]interface{}{"b": "media"}},
+		},
+		{name: "unset", value: "${TOKEN}", wantErr: "environment variable TOKEN is not set"},
+		{name: "unset nested", value: map[string]interface{}{"auth": []interface{}{"${TOKEN}"}}, wantErr: "auth: [0]: environment variable TOKEN is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interpolate(tt.value, lookup)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPipelineConfig_PluginConfig(t *testing.T) {
+	t.Setenv("UPLOAD_BUCKET", "media")
+
+	cfg := &PipelineConfig{
+		Plugins: map[string]PluginSettings{
+			"uploader": {Config: map[string]interface{}{"bucket": "${UPLOAD_BUCKET}", "retries": float64(2)}},
+			"broken":   {Config: map[string]interface{}{"token": "${PLUGIN_TEST_UNSET_TOKEN}"}},
+		},
+	}
+
+	values, err := cfg.PluginConfig("uploader")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"bucket": "media", "retries": float64(2)}, values)
+	assert.Equal(t, "${UPLOAD_BUCKET}", cfg.Plugins["uploader"].Config["bucket"], "the config itself is not modified")
+
+	values, err = cfg.PluginConfig("filter")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, values)
+
+	_, err = cfg.PluginConfig("broken")
+	assert.EqualError(t, err, "config of plugin broken: token: environment variable PLUGIN_TEST_UNSET_TOKEN is not set")
+}
+
+func TestPipelineConfig_ValidateRemote(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings PluginSettings
+		err      string
+	}{
+		{name: "address only", settings: PluginSettings{Address: "unix:///run/converter.sock"}},
+		{name: "address with tls", settings: PluginSettings{Address: "media.internal:7000", TLS: &TLSSettings{CAFile: "ca.pem"}}},
+		{name: "tls without address", settings: PluginSettings{TLS: &TLSSettings{}}, err: "tls is set without an address"},
+		{name: "config for a remote plugin", settings: PluginSettings{Address: "media.internal:7000", Config: map[string]interface{}{"format": "webm"}}, err: "remote plugins are configured where they run, not with config"},
+		{name: "cert without key", settings: PluginSettings{Address: "media.internal:7000", TLS: &TLSSettings{CertFile: "cli.pem"}}, err: "tls needs both cert_file and key_file for a client certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &PipelineConfig{Plugins: map[string]PluginSettings{"converter": tt.settings}}
+			err := cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, "pipeline plugin converter: "+tt.err)
+		})
+	}
+}
+
+func TestPipelineConfig_RemotePlugins(t *testing.T) {
+	cfg := &PipelineConfig{
+		Plugins: map[string]PluginSettings{
+			"uploader":  {Address: "tcp://uploads.internal:7000"},
+			"filter":    {ProcessTimeout: Duration(time.Second)},
+			"converter": {Address: "unix:///run/converter.sock"},
+		},
+	}
+	assert.Equal(t, []string{"converter", "uploader"}, cfg.RemotePlugins())
+
+	var empty *PipelineConfig
+	assert.Empty(t, empty.RemotePlugins())
+}
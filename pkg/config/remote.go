@@ -0,0 +1,76 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// TLSSettings configures TLS for a remote plugin. With no CA file the
+// system roots verify the plugin's certificate; a certificate and key are
+// only needed when the plugin requires client certificates.
+type TLSSettings struct {
+	CAFile     string `json:"ca_file,omitempty"`
+	CertFile   string `json:"cert_file,omitempty"`
+	KeyFile    string `json:"key_file,omitempty"`
+	ServerName string `json:"server_name,omitempty"` // overrides the name checked against the certificate
+}
+
+// ClientConfig loads the files into a TLS client config
+func (t *TLSSettings) ClientConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: t.ServerName}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile) //nolint:gosec // G304: path comes from the project config
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", t.CAFile)
+		}
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// RemotePlugins returns the names of the plugins configured with an
+// address, sorted
+func (c *PipelineConfig) RemotePlugins() []string {
+	if c == nil {
+		return nil
+	}
+	var names []string
+	for name, settings := range c.Plugins {
+		if settings.Address != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// validateRemote checks the settings that only apply to remote plugins. A
+// remote plugin is configured by whoever runs it, not by each CLI.
+func (s PluginSettings) validateRemote() error {
+	switch {
+	case s.Address == "" && s.TLS != nil:
+		return errors.New("tls is set without an address")
+	case s.Address != "" && len(s.Config) > 0:
+		return errors.New("remote plugins are configured where they run, not with config")
+	case s.TLS != nil && (s.TLS.CertFile == "") != (s.TLS.KeyFile == ""):
+		return errors.New("tls needs both cert_file and key_file for a client certificate")
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,13 +16,41 @@ const (
 
 const (
 	PluginPrefix = "plugin-"
+	HookPrefix   = "hook-"
+)
+
+// BundleSuffix names the manifest of a binary serving several plugins: the
+// plugins of plugin-text are listed in plugin-text.bundle next to it, one
+// name per line, so they are known without starting the binary
+const BundleSuffix = ".bundle"
+
+// Kind is how the CLI talks to a discovered plugin
+type Kind string
+
+const (
+	// KindPlugin is a plugin-* binary serving gRPC through go-plugin
+	KindPlugin Kind = "plugin"
+	// KindHook is a hook-* executable exchanging JSON over stdin and stdout
+	KindHook Kind = "hook"
+	// KindRemote is a plugin served at a network address from the project config
+	KindRemote Kind = "remote"
+	// KindReattach is a plugin serving in debug mode that the CLI attaches to
+	KindReattach Kind = "reattach"
 )
 
 type DiscoveredPlugin struct {
 	Name string
-	Path string
+	Path string // the plugin's address for KindRemote and KindReattach
+	Kind Kind
+
+	// Member is the plugin's name within a process serving several, whose
+	// plugins are each listed by plugin.Manager as <name>.<member>
+	Member string
 }
 
+// ErrNotFound is returned by FindPlugin for names no plugin has
+var ErrNotFound = errors.New("not found")
+
 func DiscoverPlugins(paths []string) ([]DiscoveredPlugin, error) {
 	var plugins []DiscoveredPlugin
 
@@ -46,6 +75,9 @@ func DiscoverPlugins(paths []string) ([]DiscoveredPlugin, error) {
 			}
 
 			name := entry.Name()
+			if strings.HasSuffix(name, BundleSuffix) {
+				continue
+			}
 
 			if runtime.GOOS == osWindows {
 				if !strings.HasSuffix(name, exeSuffix) {
@@ -54,7 +86,8 @@ func DiscoverPlugins(paths []string) ([]DiscoveredPlugin, error) {
 				name = strings.TrimSuffix(name, exeSuffix)
 			}
 
-			if !strings.HasPrefix(name, PluginPrefix) {
+			kind, ok := kindOf(name)
+			if !ok {
 				continue
 			}
 
@@ -76,23 +109,109 @@ func DiscoverPlugins(paths []string) ([]DiscoveredPlugin, error) {
 				}
 			}
 
-			pluginName := strings.TrimPrefix(name, PluginPrefix)
+			pluginName := NameFromPath(pluginPath)
 
 			// Skip the CLI itself (plugin-cli is not a plugin)
-			if pluginName == "cli" {
+			if kind == KindPlugin && pluginName == "cli" {
 				continue
 			}
 
-			plugins = append(plugins, DiscoveredPlugin{
+			plugin := DiscoveredPlugin{
 				Name: pluginName,
 				Path: pluginPath,
-			})
+				Kind: kind,
+			}
+			if kind != KindPlugin {
+				plugins = append(plugins, plugin)
+				continue
+			}
+
+			// A bundle is listed as its plugins in place of its binary
+			members, err := ReadBundle(pluginPath)
+			if err != nil || len(members) == 0 {
+				plugins = append(plugins, plugin)
+				continue
+			}
+			for _, member := range members {
+				plugins = append(plugins, DiscoveredPlugin{
+					Name:   pluginName + "." + member,
+					Path:   pluginPath,
+					Kind:   kind,
+					Member: member,
+				})
+			}
 		}
 	}
 
 	return plugins, nil
 }
 
+// BundlePath returns where the manifest of the plugin binary at path is
+func BundlePath(path string) string {
+	if runtime.GOOS == osWindows {
+		path = strings.TrimSuffix(path, exeSuffix)
+	}
+	return path + BundleSuffix
+}
+
+// ReadBundle returns the plugins the manifest of the plugin binary at path
+// lists, or nil when it has none and serves a single plugin. Blank lines and
+// lines starting with # are skipped.
+func ReadBundle(path string) ([]string, error) {
+	data, err := os.ReadFile(BundlePath(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle manifest: %w", err)
+	}
+
+	members := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			members = append(members, line)
+		}
+	}
+	return members, nil
+}
+
+// NameFromPath returns the name DiscoverPlugins gives the plugin binary at path
+func NameFromPath(path string) string {
+	name := baseName(path)
+	if kind, _ := kindOf(name); kind == KindHook {
+		return strings.TrimPrefix(name, HookPrefix)
+	}
+	return strings.TrimPrefix(name, PluginPrefix)
+}
+
+// KindFromPath returns the kind of the plugin binary at path, judged by its name
+func KindFromPath(path string) Kind {
+	if kind, ok := kindOf(baseName(path)); ok {
+		return kind
+	}
+	return KindPlugin
+}
+
+func baseName(path string) string {
+	name := filepath.Base(path)
+	if runtime.GOOS == osWindows {
+		name = strings.TrimSuffix(name, exeSuffix)
+	}
+	return name
+}
+
+func kindOf(name string) (Kind, bool) {
+	switch {
+	case strings.HasPrefix(name, PluginPrefix):
+		return KindPlugin, true
+	case strings.HasPrefix(name, HookPrefix):
+		return KindHook, true
+	default:
+		return "", false
+	}
+}
+
 func GetPluginPaths() []string {
 	paths := []string{}
 
@@ -145,5 +264,5 @@ func FindPlugin(name string) (*DiscoveredPlugin, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("plugin '%s' not found", name)
+	return nil, fmt.Errorf("plugin '%s' %w", name, ErrNotFound)
 }
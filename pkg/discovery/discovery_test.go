@@ -32,6 +32,19 @@ func TestDiscoverPlugins(t *testing.T) {
 			wantPlugins: []string{"test", "another"},
 			wantErr:     false,
 		},
+		{
+			name: "discovers hooks alongside plugins",
+			setup: func(t *testing.T) string {
+				dir := t.TempDir()
+				createExecutableFile(t, filepath.Join(dir, "plugin-converter"))
+				createExecutableFile(t, filepath.Join(dir, "hook-notify"))
+				createNonExecutableFile(t, filepath.Join(dir, "hook-noexec"))
+				return dir
+			},
+			want:        2,
+			wantPlugins: []string{"converter", "notify"},
+			wantErr:     false,
+		},
 		{
 			name: "ignores directories",
 			setup: func(t *testing.T) string {
@@ -65,6 +78,20 @@ func TestDiscoverPlugins(t *testing.T) {
 			wantPlugins: []string{"exec"},
 			wantErr:     false,
 		},
+		{
+			name: "lists the plugins of a bundle from its manifest",
+			setup: func(t *testing.T) string {
+				dir := t.TempDir()
+				createExecutableFile(t, filepath.Join(dir, "plugin-text"))
+				// Installs may leave the manifest executable; it is not a plugin
+				manifest := "# text plugins\nwords\n\nmentions\n"
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin-text.bundle"), []byte(manifest), 0o755)) //nolint:gosec // G306: see above
+				createExecutableFile(t, filepath.Join(dir, "plugin-single"))
+				return dir
+			},
+			want:        3,
+			wantPlugins: []string{"text.words", "text.mentions", "single"},
+		},
 		{
 			name: "handles windows exe extension",
 			setup: func(t *testing.T) string {
@@ -109,6 +136,19 @@ func TestDiscoverPlugins(t *testing.T) {
 	}
 }
 
+func TestDiscoverPlugins_Bundle(t *testing.T) {
+	dir := t.TempDir()
+	binary := createExecutableFile(t, filepath.Join(dir, "plugin-text"))
+	require.NoError(t, os.WriteFile(BundlePath(binary), []byte("words\nmentions\n"), 0o600))
+
+	got, err := DiscoverPlugins([]string{dir})
+	require.NoError(t, err)
+	assert.Equal(t, []DiscoveredPlugin{
+		{Name: "text.words", Path: binary, Kind: KindPlugin, Member: "words"},
+		{Name: "text.mentions", Path: binary, Kind: KindPlugin, Member: "mentions"},
+	}, got)
+}
+
 func TestFindPlugin(t *testing.T) {
 	// Setup test directory with plugins
 	dir := t.TempDir()
@@ -147,7 +187,7 @@ func TestFindPlugin(t *testing.T) {
 			plugin, err := FindPlugin(tt.pluginName)
 
 			if tt.wantErr {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, ErrNotFound)
 				assert.Nil(t, plugin)
 				return
 			}
@@ -297,3 +337,19 @@ func createNonExecutableFile(t *testing.T, path string) {
 	err = os.Chmod(path, 0o644)
 	require.NoError(t, err)
 }
+
+func TestNameFromPath(t *testing.T) {
+	path := filepath.Join("project", ".plugins", PluginPrefix+"converter")
+	if runtime.GOOS == osWindows {
+		path += exeSuffix
+	}
+	assert.Equal(t, "converter", NameFromPath(path))
+	assert.Equal(t, KindPlugin, KindFromPath(path))
+
+	hook := filepath.Join("project", ".plugins", HookPrefix+"notify")
+	if runtime.GOOS == osWindows {
+		hook += exeSuffix
+	}
+	assert.Equal(t, "notify", NameFromPath(hook))
+	assert.Equal(t, KindHook, KindFromPath(hook))
+}
@@ -0,0 +1,375 @@
+// Package hook runs script plugins: hook-* executables, written in any
+// language, that exchange JSON with the CLI over stdin and stdout instead of
+// serving gRPC. The CLI starts the hook once per call with the phase as its
+// only argument (also set in PLUGIN_HOOK_PHASE), writes a Request holding the
+// serialized types.Context to its stdin and reads a Response from its stdout.
+// An empty stdout means "run, and leave the context as it is", so the
+// smallest hook is:
+//
+//	#!/bin/sh
+//	# hook-audit: logs every event it sees
+//	echo "saw $(cat)" >&2
+//
+// The context a hook prints in the process phase is merged onto the one it
+// was sent, field by field: a missing field is left as it is, so
+//
+//	{"context": {"properties": {"on_call": "ana"}}}
+//
+// sets one property and keeps the event, the other properties and the
+// responses. A property set to null is removed. "event" and "control"
+// replace their value; "responses" replaces the list and must start with
+// the responses the hook was sent, as a hook may only append its own.
+//
+// Anything a hook writes to stderr is logged by the CLI.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Phase is the call the hook is asked to answer
+type Phase string
+
+const (
+	// PhaseDescribe asks for the hook's metadata, once when it is loaded
+	PhaseDescribe Phase = "describe"
+	// PhaseShouldExecute asks whether the hook wants the event
+	PhaseShouldExecute Phase = "should_execute"
+	// PhaseProcess asks the hook to handle the event
+	PhaseProcess Phase = "process"
+)
+
+const (
+	// DefaultPriority places hooks that don't declare one after the bundled
+	// processing plugins
+	DefaultPriority = 50
+
+	// DescribeTimeout bounds the describe call made when a hook is loaded
+	DescribeTimeout = 10 * time.Second
+
+	// ExitTempFail is the exit status (EX_TEMPFAIL) a hook uses to report a
+	// failure worth retrying
+	ExitTempFail = 75
+)
+
+// Request is written to the hook's stdin
+type Request struct {
+	Phase   Phase                  `json:"phase"`
+	Config  map[string]interface{} `json:"config,omitempty"`
+	Context *types.Context         `json:"context,omitempty"`
+}
+
+// Description is read from the hook's stdout in the describe phase. Every
+// field is optional.
+type Description struct {
+	Name                 string                 `json:"name"`
+	Description          string                 `json:"description"`
+	Version              string                 `json:"version"`
+	Priority             *int                   `json:"priority"`
+	MinCLIVersion        string                 `json:"min_cli_version"`
+	MaxCLIVersion        string                 `json:"max_cli_version"`
+	Requires             []string               `json:"requires"`
+	Provides             []string               `json:"provides"`
+	Examples             map[string]interface{} `json:"examples"`
+	ShouldExecuteTimeout config.Duration        `json:"should_execute_timeout"`
+	ProcessTimeout       config.Duration        `json:"process_timeout"`
+}
+
+// Response is read from the hook's stdout in the should_execute and process
+// phases. An empty stdout is an empty response.
+type Response struct {
+	// ShouldExecute defaults to true, so a hook with nothing to decide runs
+	// for every event
+	ShouldExecute *bool  `json:"should_execute"`
+	Reason        string `json:"reason"`
+
+	// Context holds the changes the process phase makes to the context.
+	// When it is missing the context is left unchanged.
+	Context *ContextUpdate `json:"context"`
+
+	// Error fails the call with a classified error
+	Error *Error `json:"error"`
+}
+
+// ContextUpdate is the context a hook prints, merged onto the one it was
+// sent. Fields it leaves out keep their value, so a hook can print just the
+// properties it sets.
+type ContextUpdate struct {
+	// Event replaces the event
+	Event *types.Event `json:"event"`
+
+	// Properties are set on the existing ones; a key set to null is removed
+	Properties map[string]interface{} `json:"properties"`
+
+	// Responses replaces the responses and must start with the ones sent,
+	// that is the hook appends its own
+	Responses []types.Response `json:"responses"`
+
+	// Control replaces the control signal
+	Control *types.Control `json:"control"`
+}
+
+// Error is a failure reported by a hook
+type Error struct {
+	Code    types.ErrorCode `json:"code"`
+	Message string          `json:"message"`
+}
+
+// Plugin is a hook executable, presented to the pipeline like any other plugin
+type Plugin struct {
+	path        string
+	description Description
+	config      map[string]interface{}
+}
+
+var (
+	_ types.VersionedPlugin    = (*Plugin)(nil)
+	_ types.FallibleDecider    = (*Plugin)(nil)
+	_ types.Configurable       = (*Plugin)(nil)
+	_ types.TimeoutProvider    = (*Plugin)(nil)
+	_ types.DependencyProvider = (*Plugin)(nil)
+)
+
+// Load asks the hook at path to describe itself
+func Load(ctx context.Context, path string) (*Plugin, error) {
+	ctx, cancel := context.WithTimeout(ctx, DescribeTimeout)
+	defer cancel()
+
+	p := &Plugin{path: path}
+	out, err := p.run(ctx, Request{Phase: PhaseDescribe})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe hook: %w", err)
+	}
+	if len(bytes.TrimSpace(out)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(out))
+		decoder.UseNumber()
+		if err := decoder.Decode(&p.description); err != nil {
+			return nil, fmt.Errorf("hook printed an invalid description: %w", err)
+		}
+		types.NormalizeNumbers(p.description.Examples)
+	}
+	return p, nil
+}
+
+// Configure keeps the hook's config block; it is sent with every call
+func (p *Plugin) Configure(ctx context.Context, values map[string]interface{}) error {
+	p.config = values
+	return nil
+}
+
+// ShouldExecute runs the hook's should_execute phase. A failed call is a
+// decision not to execute; use Decide to see the error.
+func (p *Plugin) ShouldExecute(ctx context.Context, c *types.Context) types.ExecutionDecision {
+	decision, err := p.Decide(ctx, c)
+	if err != nil {
+		return types.ExecutionDecision{ShouldExecute: false, Reason: err.Error()}
+	}
+	return decision
+}
+
+// Decide runs the hook's should_execute phase
+func (p *Plugin) Decide(ctx context.Context, c *types.Context) (types.ExecutionDecision, error) {
+	resp, err := p.call(ctx, PhaseShouldExecute, c)
+	if err != nil {
+		return types.ExecutionDecision{}, err
+	}
+
+	decision := types.ExecutionDecision{ShouldExecute: true, Reason: resp.Reason}
+	if resp.ShouldExecute != nil {
+		decision.ShouldExecute = *resp.ShouldExecute
+	}
+	if decision.Reason == "" && decision.ShouldExecute {
+		decision.Reason = "hook accepted the event"
+	}
+	return decision, nil
+}
+
+// Process runs the hook's process phase and returns the context it printed
+func (p *Plugin) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	resp, err := p.call(ctx, PhaseProcess, c)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Context == nil {
+		return c, nil
+	}
+	return p.merge(c, resp.Context)
+}
+
+// merge applies the update a hook printed to a copy of c
+func (p *Plugin) merge(c *types.Context, update *ContextUpdate) (*types.Context, error) {
+	result := c.Clone()
+	if update.Event != nil {
+		result.Event = *update.Event
+	}
+	if result.Properties == nil && len(update.Properties) > 0 {
+		result.Properties = make(map[string]interface{}, len(update.Properties))
+	}
+	for key, value := range update.Properties {
+		if value == nil {
+			delete(result.Properties, key)
+			continue
+		}
+		result.Properties[key] = value
+	}
+	if update.Control != nil {
+		result.Control = update.Control
+	}
+
+	if update.Responses != nil {
+		if len(update.Responses) < len(c.Responses) {
+			return nil, types.NewError(types.ErrorCodeInternal, fmt.Sprintf(
+				"hook returned %d responses but was sent %d: responses must keep those of earlier plugins",
+				len(update.Responses), len(c.Responses)), nil)
+		}
+		// Attribute the responses the hook added
+		for i := len(c.Responses); i < len(update.Responses); i++ {
+			if update.Responses[i].PluginName == "" {
+				update.Responses[i].PluginName = p.Name()
+			}
+		}
+		result.Responses = update.Responses
+	}
+	return result, nil
+}
+
+// call runs one phase of the hook on c and decodes its response
+func (p *Plugin) call(ctx context.Context, phase Phase, c *types.Context) (*Response, error) {
+	out, err := p.run(ctx, Request{Phase: phase, Config: p.config, Context: c})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{}
+	if len(bytes.TrimSpace(out)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(out))
+		decoder.UseNumber()
+		if err := decoder.Decode(resp); err != nil {
+			return nil, types.NewError(types.ErrorCodeInternal, "hook printed an invalid response", err)
+		}
+	}
+	if c := resp.Context; c != nil && c.Event != nil {
+		types.NormalizeNumbers(c.Event.Metadata)
+	}
+	if c := resp.Context; c != nil {
+		types.NormalizeNumbers(c.Properties)
+		for _, r := range c.Responses {
+			types.NormalizeNumbers(r.Data)
+		}
+	}
+
+	if resp.Error != nil {
+		code := resp.Error.Code
+		if code == "" {
+			code = types.ErrorCodeInternal
+		}
+		return nil, types.NewError(code, resp.Error.Message, nil)
+	}
+	return resp, nil
+}
+
+// run starts the hook for one phase and returns its stdout. Each line of
+// stderr is logged through the call's host services.
+func (p *Plugin) run(ctx context.Context, req Request) ([]byte, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, types.InvalidInput(fmt.Sprintf("cannot encode hook request: %v", err))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path, string(req.Phase)) //nolint:gosec // G204: the path is a discovered hook
+	cmd.Env = append(os.Environ(), "PLUGIN_HOOK_PHASE="+string(req.Phase))
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait forever on children of the hook that keep its pipes open
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	p.log(ctx, stderr.String())
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("hook %s: %w", p.Name(), ctxErr)
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == ExitTempFail:
+		return nil, types.Unavailable(fmt.Sprintf("hook %s failed temporarily", p.Name()), stderrError(stderr.String()))
+	case errors.As(err, &exitErr):
+		return nil, types.NewError(types.ErrorCodeInternal, fmt.Sprintf("hook %s exited with status %d", p.Name(), exitErr.ExitCode()), stderrError(stderr.String()))
+	case err != nil:
+		return nil, types.NewError(types.ErrorCodeInternal, fmt.Sprintf("hook %s could not be run", p.Name()), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func (p *Plugin) log(ctx context.Context, stderr string) {
+	host, _ := types.HostFrom(ctx)
+	for _, line := range strings.Split(strings.TrimSpace(stderr), "\n") {
+		if line != "" {
+			host.Log(types.LogInfo, line, nil)
+		}
+	}
+}
+
+// Name defaults to the name the hook was discovered under, e.g. "notify"
+// for hook-notify
+func (p *Plugin) Name() string {
+	if p.description.Name != "" {
+		return p.description.Name
+	}
+	return discovery.NameFromPath(p.path)
+}
+
+func (p *Plugin) Description() string {
+	if p.description.Description != "" {
+		return p.description.Description
+	}
+	return "Script hook " + p.path
+}
+
+func (p *Plugin) Priority() int {
+	if p.description.Priority != nil {
+		return *p.description.Priority
+	}
+	return DefaultPriority
+}
+
+func (p *Plugin) Version() string       { return p.description.Version }
+func (p *Plugin) BuildTime() string     { return "" }
+func (p *Plugin) MinCLIVersion() string { return p.description.MinCLIVersion }
+func (p *Plugin) MaxCLIVersion() string { return p.description.MaxCLIVersion }
+
+func (p *Plugin) Timeouts() types.Timeouts {
+	return types.Timeouts{
+		ShouldExecute: time.Duration(p.description.ShouldExecuteTimeout),
+		Process:       time.Duration(p.description.ProcessTimeout),
+	}
+}
+
+func (p *Plugin) Dependencies() types.Dependencies {
+	return types.Dependencies{Requires: p.description.Requires, Provides: p.description.Provides, Examples: p.description.Examples}
+}
+
+// stderrError returns the last line of a failed hook's stderr, which is
+// usually the reason it failed, or nil when it wrote nothing
+func stderrError(stderr string) error {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if last := lines[len(lines)-1]; last != "" {
+		return errors.New(last)
+	}
+	return nil
+}
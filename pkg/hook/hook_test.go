@@ -0,0 +1,271 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/plugintest"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// writeHook writes a shell script hook to a temporary directory
+func writeHook(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts need a POSIX shell")
+	}
+
+	path := filepath.Join(t.TempDir(), "hook-test")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755)) //nolint:gosec // G306: the hook must be executable
+	return path
+}
+
+func newContext() *types.Context {
+	return &types.Context{
+		Event:      types.Event{Type: types.EventMessage, Content: "ping"},
+		Properties: map[string]interface{}{"seen": true},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name         string
+		script       string
+		wantName     string
+		wantPriority int
+		wantTimeouts types.Timeouts
+		wantDeps     types.Dependencies
+		wantErr      string
+	}{
+		{
+			name:         "empty description uses defaults",
+			script:       "exit 0",
+			wantName:     "test",
+			wantPriority: DefaultPriority,
+		},
+		{
+			name: "full description",
+			script: `[ "$1" = describe ] && [ "$PLUGIN_HOOK_PHASE" = describe ] || exit 1
+cat <<'EOF'
+{"name": "pinger", "priority": 5, "version": "1.2.0", "requires": ["user"], "provides": ["pong"],
+ "should_execute_timeout": "2s", "process_timeout": "1m"}
+EOF`,
+			wantName:     "pinger",
+			wantPriority: 5,
+			wantTimeouts: types.Timeouts{ShouldExecute: 2 * time.Second, Process: time.Minute},
+			wantDeps:     types.Dependencies{Requires: []string{"user"}, Provides: []string{"pong"}},
+		},
+		{
+			name:    "invalid description",
+			script:  "echo not json",
+			wantErr: "invalid description",
+		},
+		{
+			name:    "failing describe",
+			script:  "echo 'python3: not found' >&2; exit 127",
+			wantErr: "exited with status 127: python3: not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Load(context.Background(), writeHook(t, tt.script))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantName, p.Name())
+			assert.Equal(t, tt.wantPriority, p.Priority())
+			assert.Equal(t, tt.wantTimeouts, p.Timeouts())
+			assert.Equal(t, tt.wantDeps, p.Dependencies())
+		})
+	}
+}
+
+func TestPlugin_Decide(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		want     types.ExecutionDecision
+		wantCode types.ErrorCode
+	}{
+		{
+			name:   "empty response runs",
+			script: "exit 0",
+			want:   types.ExecutionDecision{ShouldExecute: true, Reason: "hook accepted the event"},
+		},
+		{
+			name:   "declines with a reason",
+			script: `[ "$1" = should_execute ] && echo '{"should_execute": false, "reason": "not a ping"}'; exit 0`,
+			want:   types.ExecutionDecision{ShouldExecute: false, Reason: "not a ping"},
+		},
+		{
+			name:     "reported error",
+			script:   `[ "$1" = should_execute ] && echo '{"error": {"code": "rejected", "message": "blocked user"}}'; exit 0`,
+			wantCode: types.ErrorCodeRejected,
+		},
+		{
+			name:     "temporary failure",
+			script:   `[ "$1" = should_execute ] && exit 75; exit 0`,
+			wantCode: types.ErrorCodeUnavailable,
+		},
+		{
+			name:     "invalid response",
+			script:   `[ "$1" = should_execute ] && echo '{"should_execute": "yes"}'; exit 0`,
+			wantCode: types.ErrorCodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Load(context.Background(), writeHook(t, tt.script))
+			require.NoError(t, err)
+
+			decision, err := p.Decide(context.Background(), newContext())
+			if tt.wantCode != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, types.AsPluginError(err).Code)
+				assert.False(t, p.ShouldExecute(context.Background(), newContext()).ShouldExecute)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, decision)
+		})
+	}
+}
+
+func TestPlugin_Process(t *testing.T) {
+	script := `input=$(cat)
+[ "$1" = process ] || exit 0
+printf '%s' "$input" > "$(dirname "$0")/request.json"
+echo "replying to ping" >&2
+cat <<'EOF'
+{"context": {
+  "event": {"type": "message", "content": "ping"},
+  "properties": {"seen": true, "count": 3, "ratio": 0.5, "nested": {"ids": [1, 2]}},
+  "responses": [{"type": "text", "content": "pong"}]
+}}
+EOF`
+	path := writeHook(t, script)
+
+	p, err := Load(context.Background(), path)
+	require.NoError(t, err)
+	require.NoError(t, p.Configure(context.Background(), map[string]interface{}{"reply": "pong"}))
+
+	host := plugintest.NewHost()
+	result, err := p.Process(types.WithHost(context.Background(), host), newContext())
+	require.NoError(t, err)
+
+	// The hook got its config and the serialized context
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(path), "request.json")) //nolint:gosec // G304: written by the test hook
+	require.NoError(t, err)
+	var req Request
+	require.NoError(t, json.Unmarshal(data, &req))
+	assert.Equal(t, PhaseProcess, req.Phase)
+	assert.Equal(t, map[string]interface{}{"reply": "pong"}, req.Config)
+	assert.Equal(t, newContext(), req.Context)
+
+	// Numbers come back as int64 and float64, like they do from gRPC plugins
+	assert.Equal(t, map[string]interface{}{
+		"seen":   true,
+		"count":  int64(3),
+		"ratio":  0.5,
+		"nested": map[string]interface{}{"ids": []interface{}{int64(1), int64(2)}},
+	}, result.Properties)
+	require.Len(t, result.Responses, 1)
+	assert.Equal(t, "pong", result.Responses[0].Content)
+	assert.Equal(t, "test", result.Responses[0].PluginName)
+
+	require.Len(t, host.Logs, 1)
+	assert.Equal(t, "replying to ping", host.Logs[0].Message)
+}
+
+func TestPlugin_ProcessUnchanged(t *testing.T) {
+	p, err := Load(context.Background(), writeHook(t, "cat > /dev/null"))
+	require.NoError(t, err)
+
+	c := newContext()
+	result, err := p.Process(context.Background(), c)
+	require.NoError(t, err)
+	assert.Same(t, c, result)
+}
+
+func TestPlugin_ProcessMerge(t *testing.T) {
+	sent := func() *types.Context {
+		c := newContext()
+		c.Properties["stale"] = "yes"
+		c.Responses = []types.Response{{PluginName: "earlier", Content: "first"}}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		want    func(c *types.Context)
+		wantErr string
+	}{
+		{
+			name:   "properties only keep the rest",
+			output: `{"context": {"properties": {"on_call": "ana", "stale": null}}}`,
+			want: func(c *types.Context) {
+				delete(c.Properties, "stale")
+				c.Properties["on_call"] = "ana"
+			},
+		},
+		{
+			name:   "responses appended",
+			output: `{"context": {"responses": [{"plugin_name": "earlier", "content": "first"}, {"content": "second"}]}}`,
+			want: func(c *types.Context) {
+				c.Responses = append(c.Responses, types.Response{PluginName: "test", Content: "second"})
+			},
+		},
+		{
+			name:    "responses dropped",
+			output:  `{"context": {"responses": []}}`,
+			wantErr: "hook returned 0 responses but was sent 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Load(context.Background(), writeHook(t, "cat > /dev/null\n[ \"$1\" = process ] || exit 0\necho '"+tt.output+"'"))
+			require.NoError(t, err)
+
+			c := sent()
+			result, err := p.Process(context.Background(), c)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			want := sent()
+			tt.want(want)
+			assert.Equal(t, want, result)
+			assert.Equal(t, sent(), c, "the context sent is left untouched")
+		})
+	}
+}
+
+func TestPlugin_Deadline(t *testing.T) {
+	p, err := Load(context.Background(), writeHook(t, `[ "$1" = process ] && sleep 10; exit 0`))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = p.Process(ctx, newContext())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -14,6 +14,7 @@ import (
 type PluginManager interface {
 	LoadPlugin(name string) (*plugin.Client, types.VersionedPlugin, error)
 	LoadPluginFromPath(path string) (*plugin.Client, types.VersionedPlugin, error)
+	Load(d discovery.DiscoveredPlugin) (*plugin.Client, types.VersionedPlugin, error)
 	ListPlugins() ([]discovery.DiscoveredPlugin, error)
 	GetPluginMetadata(p types.VersionedPlugin) types.PluginMetadata
 }
@@ -30,6 +31,7 @@ type Pipeline interface {
 	ProcessEvent(ctx context.Context, event types.Event) (*types.Context, error)
 	ProcessMessage(ctx context.Context, source, content, userID, channelID string) (*types.Context, error)
 	ProcessCommand(ctx context.Context, source, command, userID, channelID string) (*types.Context, error)
+	Close() error
 }
 
 //counterfeiter:generate . PackageManager
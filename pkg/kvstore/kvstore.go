@@ -0,0 +1,139 @@
+// Package kvstore is the persistent key-value store the host offers plugins.
+// Each namespace (one per plugin) is kept as a JSON file in the store's directory.
+package kvstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Store is safe for concurrent use within one process
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// New returns a store rooted at dir; the directory is created on the first write
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Get returns the value of key in namespace. Numbers come back as int64 when
+// they are integers and float64 otherwise, like other values read from JSON.
+func (s *Store) Get(namespace, key string) (interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load(namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := data[key]
+	return value, ok, nil
+}
+
+// Set stores value under key in namespace. The value must be JSON encodable.
+func (s *Store) Set(namespace, key string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load(namespace)
+	if err != nil {
+		return err
+	}
+	data[key] = value
+	return s.save(namespace, data)
+}
+
+// Delete removes key from namespace; deleting a missing key is not an error
+func (s *Store) Delete(namespace, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.load(namespace)
+	if err != nil {
+		return err
+	}
+	if _, ok := data[key]; !ok {
+		return nil
+	}
+	delete(data, key)
+	return s.save(namespace, data)
+}
+
+func (s *Store) path(namespace string) (string, error) {
+	if namespace == "" || namespace == "." || namespace == ".." || filepath.Base(namespace) != namespace {
+		return "", fmt.Errorf("invalid namespace %q", namespace)
+	}
+	return filepath.Join(s.dir, namespace+".json"), nil
+}
+
+func (s *Store) load(namespace string) (map[string]interface{}, error) {
+	path, err := s.path(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return make(map[string]interface{}), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	data := make(map[string]interface{})
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for k, v := range data {
+		data[k] = types.NormalizeNumbers(v)
+	}
+	return data, nil
+}
+
+// save writes the namespace to a temporary file and renames it into place so
+// a crash never leaves a half-written file behind
+func (s *Store) save(namespace string, data map[string]interface{}) error {
+	path, err := s.path(namespace)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", namespace, err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, namespace+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package kvstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SetGetDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := New(filepath.Join(dir, "state"))
+
+	_, found, err := store.Get("uploader", "last_url")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.Set("uploader", "last_url", "https://example.com/a"))
+	require.NoError(t, store.Set("uploader", "uploads", 3))
+	require.NoError(t, store.Set("converter", "uploads", 1.5))
+
+	// A fresh store reads what the first one persisted
+	reopened := New(filepath.Join(dir, "state"))
+	value, found, err := reopened.Get("uploader", "last_url")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "https://example.com/a", value)
+
+	value, _, err = reopened.Get("uploader", "uploads")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), value)
+
+	// Namespaces don't share keys
+	value, _, err = reopened.Get("converter", "uploads")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+
+	require.NoError(t, reopened.Delete("uploader", "uploads"))
+	require.NoError(t, reopened.Delete("uploader", "missing"))
+	_, found, err = store.Get("uploader", "uploads")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestStore_InvalidNamespace(t *testing.T) {
+	store := New(t.TempDir())
+
+	for _, namespace := range []string{"", ".", "..", "../escape", "a/b"} {
+		err := store.Set(namespace, "k", "v")
+		assert.Error(t, err, "namespace %q", namespace)
+	}
+}
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// ContextDiff is what a plugin changed in the context it was given
+type ContextDiff struct {
+	Set       map[string]interface{} `json:"set,omitempty"`       // added or changed Properties
+	Deleted   []string               `json:"deleted,omitempty"`   // removed Properties keys
+	Responses []types.Response       `json:"responses,omitempty"` // responses appended by the plugin
+	Control   *types.Control         `json:"control,omitempty"`   // control signal set by the plugin
+}
+
+// Empty reports whether the plugin left the context unchanged
+func (d ContextDiff) Empty() bool {
+	return len(d.Set) == 0 && len(d.Deleted) == 0 && len(d.Responses) == 0 && d.Control == nil
+}
+
+// Keys returns every Properties key the diff touches, sorted
+func (d ContextDiff) Keys() []string {
+	keys := make([]string, 0, len(d.Set)+len(d.Deleted))
+	for k := range d.Set {
+		keys = append(keys, k)
+	}
+	keys = append(keys, d.Deleted...)
+	sort.Strings(keys)
+	return keys
+}
+
+// diffContext compares a plugin's output with the context it received
+func diffContext(before, after *types.Context) ContextDiff {
+	diff := ContextDiff{Set: make(map[string]interface{})}
+
+	for k, v := range after.Properties {
+		if old, ok := before.Properties[k]; !ok || !reflect.DeepEqual(old, v) {
+			diff.Set[k] = v
+		}
+	}
+
+	for k := range before.Properties {
+		if _, ok := after.Properties[k]; !ok {
+			diff.Deleted = append(diff.Deleted, k)
+		}
+	}
+	sort.Strings(diff.Deleted)
+
+	if len(after.Responses) > len(before.Responses) {
+		diff.Responses = append(diff.Responses, after.Responses[len(before.Responses):]...)
+	}
+
+	if after.Control != nil && !reflect.DeepEqual(before.Control, after.Control) {
+		diff.Control = after.Control
+	}
+
+	return diff
+}
+
+// apply writes the diff onto ctx in place. A control signal already halting
+// ctx is kept, so the first plugin to stop or reject the event wins.
+func (d ContextDiff) apply(ctx *types.Context) {
+	for k, v := range d.Set {
+		ctx.Properties[k] = v
+	}
+	for _, k := range d.Deleted {
+		delete(ctx.Properties, k)
+	}
+	ctx.Responses = append(ctx.Responses, d.Responses...)
+	if d.Control != nil && !ctx.Halted() {
+		ctx.Control = d.Control
+	}
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestDiffContext(t *testing.T) {
+	before := &types.Context{
+		Properties: map[string]interface{}{
+			"unchanged": "same",
+			"changed":   1,
+			"removed":   true,
+			"nested":    map[string]interface{}{"k": "v"},
+		},
+		Responses: []types.Response{{PluginName: "earlier"}},
+	}
+
+	after := before.Clone()
+	after.Properties["changed"] = 2
+	after.Properties["added"] = "new"
+	delete(after.Properties, "removed")
+	after.Responses = append(after.Responses, types.Response{PluginName: "plugin"})
+
+	diff := diffContext(before, after)
+	assert.Equal(t, map[string]interface{}{"changed": 2, "added": "new"}, diff.Set)
+	assert.Equal(t, []string{"removed"}, diff.Deleted)
+	assert.Equal(t, []types.Response{{PluginName: "plugin"}}, diff.Responses)
+	assert.Equal(t, []string{"added", "changed", "removed"}, diff.Keys())
+	assert.False(t, diff.Empty())
+
+	// Applying the diff to the original reproduces the plugin's output
+	diff.apply(before)
+	assert.Equal(t, after, before)
+
+	assert.True(t, diffContext(after, after.Clone()).Empty())
+}
+
+func TestDiffContext_Control(t *testing.T) {
+	base := &types.Context{Properties: map[string]interface{}{}}
+
+	stopped := base.Clone()
+	stopped.Stop("handled")
+	rejected := base.Clone()
+	rejected.Reject("spam")
+
+	stopDiff := diffContext(base, stopped)
+	assert.False(t, stopDiff.Empty())
+	assert.Equal(t, stopped.Control, stopDiff.Control)
+
+	// The first halting signal applied is kept
+	merged := base.Clone()
+	stopDiff.apply(merged)
+	diffContext(base, rejected).apply(merged)
+	assert.Equal(t, types.SignalStop, merged.Control.Signal)
+
+	assert.Nil(t, diffContext(stopped, stopped.Clone()).Control)
+}
@@ -0,0 +1,188 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// PredictedValue stands in for a Properties key that an upstream plugin
+// declares it provides without an example value, since Explain never runs
+// Process to learn the real value
+const PredictedValue = true
+
+// Explanation shows which plugins would run for an event, without running any
+type Explanation struct {
+	Event    types.Event `json:"event"`
+	Pipeline string      `json:"pipeline,omitempty"` // named pipeline the event was routed to
+
+	// Assumed holds Properties supplied by the caller in place of predictions
+	Assumed map[string]interface{} `json:"assumed,omitempty"`
+
+	Plugins []PluginExplanation `json:"plugins"`
+}
+
+// PluginExplanation is one plugin's entry in an Explanation
+type PluginExplanation struct {
+	Plugin string `json:"plugin"`
+	Stage  int    `json:"stage"`
+
+	// Initial is what ShouldExecute returned for the bare event context
+	Initial types.ExecutionDecision `json:"initial"`
+
+	// Predicted is what ShouldExecute returned for the context the plugin
+	// would see at its place in the pipeline
+	Predicted types.ExecutionDecision `json:"predicted"`
+
+	// PredictedKeys lists the Properties keys in that context that came from
+	// upstream declarations or caller assumptions rather than the event
+	PredictedKeys []string `json:"predicted_keys,omitempty"`
+
+	// Unknown is set when the plugin declined the predicted context while
+	// keys it requires (any key, if it declares none) held PredictedValue
+	// placeholders: the real values may well change its answer, so the
+	// prediction is neither run nor skip
+	Unknown bool `json:"unknown,omitempty"`
+
+	// Error is set when ShouldExecute did not answer in time
+	Error string `json:"error,omitempty"`
+}
+
+// Explain asks every plugin whether it would handle the event, calling only
+// ShouldExecute and never Process. Each plugin is asked twice: once with the
+// initial context and once with a predicted context, where plugins expected
+// to run upstream have set the keys they declare in Provides: to the
+// caller's value when it appears in assumed, else to the plugin's example
+// value (see types.Dependencies), else to PredictedValue.
+func (p *Pipeline) Explain(ctx context.Context, event types.Event, assumed map[string]interface{}) (*Explanation, error) {
+	explanation := &Explanation{
+		Event:   event,
+		Assumed: assumed,
+		Plugins: []PluginExplanation{},
+	}
+
+	plugins, err := p.pool.Acquire()
+	if err != nil {
+		return explanation, fmt.Errorf("failed to load plugins: %w", err)
+	}
+
+	explanation.Pipeline, plugins, err = p.route(ctx, event, plugins)
+	if err != nil {
+		return explanation, err
+	}
+
+	if timeout := time.Duration(p.config.EventTimeout); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	initial := &types.Context{
+		Event:      event,
+		Properties: make(map[string]interface{}),
+		Responses:  []types.Response{},
+	}
+
+	predicted := initial.Clone()
+	for key, value := range assumed {
+		predicted.Properties[key] = value
+	}
+	// placeholders are the predicted keys set to PredictedValue
+	placeholders := make(map[string]bool)
+
+	for stageIndex, stage := range p.stages(plugins) {
+		if ctx.Err() != nil {
+			return explanation, p.eventError(ctx)
+		}
+
+		// Members of a stage all see the context as it was before the stage
+		provided := make(map[string]interface{})
+		guessed := make(map[string]bool) // provided without an example
+		for _, loadedPlugin := range stage {
+			entry := p.explainPlugin(ctx, loadedPlugin, initial, predicted, placeholders)
+			entry.Stage = stageIndex
+			explanation.Plugins = append(explanation.Plugins, entry)
+
+			if !entry.Predicted.ShouldExecute || entry.Error != "" {
+				continue
+			}
+			deps := dependenciesOf(loadedPlugin.Plugin)
+			for _, key := range deps.Provides {
+				if _, seen := provided[key]; seen {
+					continue
+				}
+				value, ok := deps.Examples[key]
+				if !ok {
+					value = PredictedValue
+				}
+				provided[key] = value
+				guessed[key] = !ok
+			}
+		}
+
+		for key, value := range provided {
+			if _, ok := predicted.Properties[key]; !ok {
+				predicted.Properties[key] = value
+				placeholders[key] = guessed[key]
+			}
+		}
+	}
+
+	return explanation, nil
+}
+
+func (p *Pipeline) explainPlugin(ctx context.Context, loadedPlugin LoadedPlugin, initial, predicted *types.Context, placeholders map[string]bool) PluginExplanation {
+	entry := PluginExplanation{
+		Plugin:        loadedPlugin.Plugin.Name(),
+		PredictedKeys: propertyKeys(predicted),
+	}
+	timeout := p.timeoutsFor(loadedPlugin).ShouldExecute
+
+	// Plugins get copies so an in-process plugin cannot leak writes into the prediction
+	decision, _, err := p.decide(ctx, loadedPlugin, initial.Clone(), timeout)
+	if err != nil {
+		entry.Error = p.timeoutReason(ctx, "ShouldExecute", timeout)
+		return entry
+	}
+	entry.Initial = decision
+	entry.Predicted = decision
+
+	if len(entry.PredictedKeys) == 0 {
+		return entry
+	}
+
+	decision, _, err = p.decide(ctx, loadedPlugin, predicted.Clone(), timeout)
+	if err != nil {
+		entry.Predicted = types.ExecutionDecision{}
+		entry.Error = p.timeoutReason(ctx, "ShouldExecute", timeout)
+		return entry
+	}
+	entry.Predicted = decision
+
+	if !decision.ShouldExecute {
+		// Plugins that declare what they read can only have declined those keys
+		keys := dependenciesOf(loadedPlugin.Plugin).Requires
+		if len(keys) == 0 {
+			keys = entry.PredictedKeys
+		}
+		for _, key := range keys {
+			entry.Unknown = entry.Unknown || placeholders[key]
+		}
+	}
+
+	return entry
+}
+
+// propertyKeys lists a context's Properties keys; in a predicted context
+// none of them come from the event itself
+func propertyKeys(c *types.Context) []string {
+	var keys []string
+	for key := range c.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,173 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// wantsKey runs only when key is set, optionally to a specific value
+func wantsKey(key string, value interface{}) func(ctx context.Context, c *types.Context) types.ExecutionDecision {
+	return func(ctx context.Context, c *types.Context) types.ExecutionDecision {
+		got, ok := c.Properties[key]
+		if !ok {
+			return types.ExecutionDecision{ShouldExecute: false, Reason: "missing " + key}
+		}
+		if value != nil && got != value {
+			return types.ExecutionDecision{ShouldExecute: false, Reason: "wrong " + key}
+		}
+		return types.ExecutionDecision{ShouldExecute: true, Reason: "has " + key}
+	}
+}
+
+func TestPipeline_ExplainNeverCallsProcess(t *testing.T) {
+	processed := false
+	neverProcess := func(ctx context.Context, c *types.Context) (*types.Context, error) {
+		processed = true
+		return c, nil
+	}
+
+	p := newTestPipeline(t, config.PipelineConfig{},
+		&fakePlugin{name: "filter", priority: 10, deps: types.Dependencies{Provides: []string{"action"}}, process: neverProcess},
+		&fakePlugin{name: "converter", priority: 20, deps: types.Dependencies{Requires: []string{"action"}, Provides: []string{"file"}},
+			shouldExecute: wantsKey("action", nil), process: neverProcess},
+		&fakePlugin{name: "uploader", priority: 30, deps: types.Dependencies{Requires: []string{"file"}},
+			shouldExecute: wantsKey("file", nil), process: neverProcess},
+	)
+
+	explanation, err := p.Explain(context.Background(), testEvent(), nil)
+	require.NoError(t, err)
+	assert.False(t, processed)
+	require.Len(t, explanation.Plugins, 3)
+
+	filter := explanation.Plugins[0]
+	assert.True(t, filter.Initial.ShouldExecute)
+	assert.True(t, filter.Predicted.ShouldExecute)
+	assert.Empty(t, filter.PredictedKeys)
+
+	converter := explanation.Plugins[1]
+	assert.False(t, converter.Initial.ShouldExecute)
+	assert.Equal(t, "missing action", converter.Initial.Reason)
+	assert.True(t, converter.Predicted.ShouldExecute)
+	assert.Equal(t, []string{"action"}, converter.PredictedKeys)
+
+	uploader := explanation.Plugins[2]
+	assert.False(t, uploader.Initial.ShouldExecute)
+	assert.True(t, uploader.Predicted.ShouldExecute)
+	assert.Equal(t, []string{"action", "file"}, uploader.PredictedKeys)
+}
+
+func TestPipeline_ExplainSkippedPluginsProvideNothing(t *testing.T) {
+	p := newTestPipeline(t, config.PipelineConfig{},
+		&fakePlugin{name: "filter", priority: 10, deps: types.Dependencies{Provides: []string{"action"}},
+			shouldExecute: wantsKey("never", nil)},
+		&fakePlugin{name: "converter", priority: 20, deps: types.Dependencies{Requires: []string{"action"}},
+			shouldExecute: wantsKey("action", nil)},
+	)
+
+	explanation, err := p.Explain(context.Background(), testEvent(), nil)
+	require.NoError(t, err)
+	require.Len(t, explanation.Plugins, 2)
+	assert.False(t, explanation.Plugins[1].Predicted.ShouldExecute)
+	assert.Empty(t, explanation.Plugins[1].PredictedKeys)
+}
+
+func TestPipeline_ExplainAssumptions(t *testing.T) {
+	p := newTestPipeline(t, config.PipelineConfig{},
+		&fakePlugin{name: "filter", priority: 10, deps: types.Dependencies{Provides: []string{"action"}}},
+		&fakePlugin{name: "converter", priority: 20, deps: types.Dependencies{Requires: []string{"action"}},
+			shouldExecute: wantsKey("action", "convert")},
+	)
+
+	// The placeholder value does not satisfy a plugin that checks the value,
+	// so the prediction is unknown rather than a skip
+	explanation, err := p.Explain(context.Background(), testEvent(), nil)
+	require.NoError(t, err)
+	assert.False(t, explanation.Plugins[1].Predicted.ShouldExecute)
+	assert.Equal(t, "wrong action", explanation.Plugins[1].Predicted.Reason)
+	assert.True(t, explanation.Plugins[1].Unknown)
+
+	explanation, err = p.Explain(context.Background(), testEvent(), map[string]interface{}{"action": "convert"})
+	require.NoError(t, err)
+	assert.True(t, explanation.Plugins[1].Predicted.ShouldExecute)
+	assert.False(t, explanation.Plugins[1].Initial.ShouldExecute)
+	assert.False(t, explanation.Plugins[1].Unknown)
+
+	// A value the caller assumed is no placeholder: declining it is a skip
+	explanation, err = p.Explain(context.Background(), testEvent(), map[string]interface{}{"action": "upload"})
+	require.NoError(t, err)
+	assert.False(t, explanation.Plugins[1].Predicted.ShouldExecute)
+	assert.False(t, explanation.Plugins[1].Unknown)
+}
+
+func TestPipeline_ExplainExamples(t *testing.T) {
+	p := newTestPipeline(t, config.PipelineConfig{},
+		&fakePlugin{name: "filter", priority: 10, deps: types.Dependencies{
+			Provides: []string{"action", "media_type"},
+			Examples: map[string]interface{}{"media_type": "video"},
+		}},
+		&fakePlugin{name: "converter", priority: 20, deps: types.Dependencies{Requires: []string{"media_type"}},
+			shouldExecute: wantsKey("media_type", "video")},
+		&fakePlugin{name: "resizer", priority: 20, deps: types.Dependencies{Requires: []string{"media_type"}},
+			shouldExecute: wantsKey("media_type", "image")},
+	)
+
+	explanation, err := p.Explain(context.Background(), testEvent(), nil)
+	require.NoError(t, err)
+	require.Len(t, explanation.Plugins, 3)
+
+	converter := explanation.Plugins[1]
+	assert.True(t, converter.Predicted.ShouldExecute)
+	assert.Equal(t, []string{"action", "media_type"}, converter.PredictedKeys)
+
+	// Declining the example value is a skip; action is a placeholder, but
+	// not one the resizer reads
+	resizer := explanation.Plugins[2]
+	assert.False(t, resizer.Predicted.ShouldExecute)
+	assert.Equal(t, "wrong media_type", resizer.Predicted.Reason)
+	assert.False(t, resizer.Unknown)
+}
+
+func TestPipeline_ExplainParallelStageSeesStageBase(t *testing.T) {
+	cfg := config.PipelineConfig{Execution: config.ExecutionParallel}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "a", priority: 10, deps: types.Dependencies{Provides: []string{"a_out"}}},
+		&fakePlugin{name: "b", priority: 10, deps: types.Dependencies{Provides: []string{"b_out"}},
+			shouldExecute: wantsKey("a_out", nil)},
+		&fakePlugin{name: "c", priority: 20, deps: types.Dependencies{Requires: []string{"a_out"}},
+			shouldExecute: wantsKey("a_out", nil)},
+	)
+
+	explanation, err := p.Explain(context.Background(), testEvent(), nil)
+	require.NoError(t, err)
+	require.Len(t, explanation.Plugins, 3)
+
+	assert.Equal(t, 0, explanation.Plugins[1].Stage)
+	assert.False(t, explanation.Plugins[1].Predicted.ShouldExecute)
+	assert.Equal(t, 1, explanation.Plugins[2].Stage)
+	assert.True(t, explanation.Plugins[2].Predicted.ShouldExecute)
+}
+
+func TestPipeline_ExplainShouldExecuteTimeout(t *testing.T) {
+	p := newTestPipeline(t, config.PipelineConfig{},
+		&fakePlugin{
+			name:     "stuck",
+			timeouts: types.Timeouts{ShouldExecute: 20 * time.Millisecond},
+			shouldExecute: func(ctx context.Context, c *types.Context) types.ExecutionDecision {
+				<-ctx.Done()
+				return types.ExecutionDecision{}
+			},
+		},
+	)
+
+	explanation, err := p.Explain(context.Background(), testEvent(), nil)
+	require.NoError(t, err)
+	require.Len(t, explanation.Plugins, 1)
+	assert.Equal(t, "ShouldExecute exceeded plugin deadline of 20ms", explanation.Plugins[0].Error)
+	assert.False(t, explanation.Plugins[0].Predicted.ShouldExecute)
+}
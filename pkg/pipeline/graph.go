@@ -0,0 +1,158 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// dependenciesOf returns the Properties keys a plugin declares, if any
+func dependenciesOf(p types.VersionedPlugin) types.Dependencies {
+	if dp, ok := p.(types.DependencyProvider); ok {
+		return dp.Dependencies()
+	}
+	return types.Dependencies{}
+}
+
+// orderPlugins sorts plugins so every producer of a Properties key runs
+// before its consumers, using Priority (then name) to break ties. It fails
+// when a required key has no producer or the declarations form a cycle.
+func orderPlugins(plugins []LoadedPlugin) ([]LoadedPlugin, error) {
+	n := len(plugins)
+	names := make([]string, n)
+	priorities := make([]int, n)
+	deps := make([]types.Dependencies, n)
+	producers := make(map[string][]int)
+
+	for i, lp := range plugins {
+		names[i] = lp.Plugin.Name()
+		priorities[i] = lp.Plugin.Priority()
+		deps[i] = dependenciesOf(lp.Plugin)
+		for _, key := range deps[i].Provides {
+			producers[key] = append(producers[key], i)
+		}
+	}
+
+	// Edge producer -> consumer for every required key
+	edges := make([][]int, n)
+	inDegree := make([]int, n)
+	for i := range plugins {
+		seen := make(map[int]bool)
+		for _, key := range deps[i].Requires {
+			providers, ok := producers[key]
+			if !ok {
+				return nil, fmt.Errorf("plugin %s requires property %q but no plugin provides it", names[i], key)
+			}
+			for _, producer := range providers {
+				// A plugin may read and rewrite the same key
+				if producer == i || seen[producer] {
+					continue
+				}
+				seen[producer] = true
+				edges[producer] = append(edges[producer], i)
+				inDegree[i]++
+			}
+		}
+	}
+
+	less := func(a, b int) bool {
+		if priorities[a] != priorities[b] {
+			return priorities[a] < priorities[b]
+		}
+		return names[a] < names[b]
+	}
+
+	// Kahn's algorithm, always picking the ready plugin that sorts first
+	var ready []int
+	for i := range plugins {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	ordered := make([]LoadedPlugin, 0, n)
+	for len(ready) > 0 {
+		sort.Slice(ready, func(a, b int) bool { return less(ready[a], ready[b]) })
+		next := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, plugins[next])
+
+		for _, consumer := range edges[next] {
+			inDegree[consumer]--
+			if inDegree[consumer] == 0 {
+				ready = append(ready, consumer)
+			}
+		}
+	}
+
+	if len(ordered) != n {
+		var cycle []string
+		for i := range plugins {
+			if inDegree[i] > 0 {
+				cycle = append(cycle, names[i])
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("plugin dependency cycle between: %s", strings.Join(cycle, ", "))
+	}
+
+	return ordered, nil
+}
+
+// groupStages splits an ordered plugin list into stages whose members may
+// run concurrently. A plugin joins the current stage when it has no
+// dependency on (or shared output with) any member, and either it and the
+// member both declare their dependencies or they share a priority.
+func groupStages(ordered []LoadedPlugin) [][]LoadedPlugin {
+	var stages [][]LoadedPlugin
+	for _, lp := range ordered {
+		if n := len(stages); n > 0 && canJoinStage(stages[n-1], lp) {
+			stages[n-1] = append(stages[n-1], lp)
+			continue
+		}
+		stages = append(stages, []LoadedPlugin{lp})
+	}
+	return stages
+}
+
+func canJoinStage(stage []LoadedPlugin, candidate LoadedPlugin) bool {
+	candidateDeps := dependenciesOf(candidate.Plugin)
+
+	for _, member := range stage {
+		memberDeps := dependenciesOf(member.Plugin)
+
+		if overlaps(candidateDeps.Requires, memberDeps.Provides) ||
+			overlaps(memberDeps.Requires, candidateDeps.Provides) ||
+			overlaps(candidateDeps.Provides, memberDeps.Provides) {
+			return false
+		}
+
+		if declared(candidateDeps) && declared(memberDeps) {
+			continue
+		}
+
+		// Without declarations, only the priority says the two are unrelated
+		if member.Plugin.Priority() != candidate.Plugin.Priority() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func declared(deps types.Dependencies) bool {
+	return len(deps.Requires) > 0 || len(deps.Provides) > 0
+}
+
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
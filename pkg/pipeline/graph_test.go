@@ -0,0 +1,170 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func loadedPlugins(plugins ...*fakePlugin) []LoadedPlugin {
+	loaded := make([]LoadedPlugin, len(plugins))
+	for i, p := range plugins {
+		loaded[i] = LoadedPlugin{Name: p.name, Client: &plugin.Client{}, Plugin: p}
+	}
+	return loaded
+}
+
+func orderedNames(loaded []LoadedPlugin) []string {
+	names := make([]string, len(loaded))
+	for i, lp := range loaded {
+		names[i] = lp.Plugin.Name()
+	}
+	return names
+}
+
+func TestOrderPlugins(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugins []*fakePlugin
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "priority only when nothing is declared",
+			plugins: []*fakePlugin{
+				{name: "uploader", priority: 50},
+				{name: "filter", priority: 10},
+				{name: "converter", priority: 30},
+			},
+			want: []string{"filter", "converter", "uploader"},
+		},
+		{
+			name: "producers run before consumers regardless of priority",
+			plugins: []*fakePlugin{
+				{name: "uploader", priority: 1, deps: types.Dependencies{Requires: []string{"file_path"}}},
+				{name: "converter", priority: 30, deps: types.Dependencies{Requires: []string{"action"}, Provides: []string{"file_path"}}},
+				{name: "filter", priority: 90, deps: types.Dependencies{Provides: []string{"action"}}},
+			},
+			want: []string{"filter", "converter", "uploader"},
+		},
+		{
+			name: "priority then name break ties between independent plugins",
+			plugins: []*fakePlugin{
+				{name: "b", priority: 10},
+				{name: "a", priority: 10},
+				{name: "consumer", priority: 0, deps: types.Dependencies{Requires: []string{"x"}}},
+				{name: "producer", priority: 20, deps: types.Dependencies{Provides: []string{"x"}}},
+			},
+			want: []string{"a", "b", "producer", "consumer"},
+		},
+		{
+			name: "plugin may rewrite a key it requires",
+			plugins: []*fakePlugin{
+				{name: "normalizer", priority: 20, deps: types.Dependencies{Requires: []string{"text"}, Provides: []string{"text"}}},
+				{name: "reader", priority: 10, deps: types.Dependencies{Provides: []string{"text"}}},
+			},
+			want: []string{"reader", "normalizer"},
+		},
+		{
+			name: "missing producer",
+			plugins: []*fakePlugin{
+				{name: "uploader", priority: 50, deps: types.Dependencies{Requires: []string{"file_path"}}},
+			},
+			wantErr: `plugin uploader requires property "file_path" but no plugin provides it`,
+		},
+		{
+			name: "cycle",
+			plugins: []*fakePlugin{
+				{name: "a", deps: types.Dependencies{Requires: []string{"y"}, Provides: []string{"x"}}},
+				{name: "b", deps: types.Dependencies{Requires: []string{"x"}, Provides: []string{"y"}}},
+				{name: "c"},
+			},
+			wantErr: "plugin dependency cycle between: a, b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := orderPlugins(loadedPlugins(tt.plugins...))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, orderedNames(ordered))
+		})
+	}
+}
+
+func TestPool_AcquireFailsOnDependencyCycle(t *testing.T) {
+	plugins := map[string]*fakePlugin{
+		"/plugins/plugin-a": {name: "a", deps: types.Dependencies{Requires: []string{"y"}, Provides: []string{"x"}}},
+		"/plugins/plugin-b": {name: "b", deps: types.Dependencies{Requires: []string{"x"}, Provides: []string{"y"}}},
+	}
+	p := newTestPool(t, plugins, map[string]int{})
+	defer p.Close()
+
+	_, err := p.Acquire()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle")
+	assert.Empty(t, p.plugins)
+}
+
+func TestGroupStages(t *testing.T) {
+	tests := []struct {
+		name    string
+		plugins []*fakePlugin
+		want    [][]string
+	}{
+		{
+			name: "undeclared plugins group by priority",
+			plugins: []*fakePlugin{
+				{name: "a", priority: 10},
+				{name: "b", priority: 10},
+				{name: "c", priority: 20},
+			},
+			want: [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name: "declared independent plugins group across priorities",
+			plugins: []*fakePlugin{
+				{name: "filter", priority: 10, deps: types.Dependencies{Provides: []string{"action"}}},
+				{name: "audit", priority: 90, deps: types.Dependencies{Provides: []string{"audit_id"}}},
+			},
+			want: [][]string{{"filter", "audit"}},
+		},
+		{
+			name: "dependent plugins never share a stage",
+			plugins: []*fakePlugin{
+				{name: "filter", priority: 10, deps: types.Dependencies{Provides: []string{"action"}}},
+				{name: "converter", priority: 10, deps: types.Dependencies{Requires: []string{"action"}}},
+			},
+			want: [][]string{{"filter"}, {"converter"}},
+		},
+		{
+			name: "plugins providing the same key stay apart",
+			plugins: []*fakePlugin{
+				{name: "a", priority: 10, deps: types.Dependencies{Provides: []string{"x"}}},
+				{name: "b", priority: 10, deps: types.Dependencies{Provides: []string{"x"}}},
+			},
+			want: [][]string{{"a"}, {"b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stages := groupStages(loadedPlugins(tt.plugins...))
+
+			got := make([][]string, len(stages))
+			for i, stage := range stages {
+				got[i] = orderedNames(stage)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
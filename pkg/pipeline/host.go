@@ -0,0 +1,207 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/blobstore"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/kvstore"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// MaxFollowUpDepth bounds chains of follow-up events: an event emitted while
+// handling a follow-up that is this many levels deep is dropped
+const MaxFollowUpDepth = 3
+
+// FollowUp is the outcome of an event a plugin emitted while this one was
+// being handled
+type FollowUp struct {
+	Result *Result `json:"result"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// pluginHost is the types.Host given to one plugin for one event
+type pluginHost struct {
+	plugin  string
+	logger  hclog.Logger
+	store   *kvstore.Store
+	blobs   *blobstore.Store
+	stored  *stored
+	emitted *emitted
+}
+
+// host returns the services offered to a plugin while it handles the event
+// whose follow-ups are collected in ctx
+func (p *Pipeline) host(ctx context.Context, pluginName string) types.Host {
+	queue, _ := ctx.Value(emittedKey{}).(*emitted)
+	blobs, _ := ctx.Value(storedKey{}).(*stored)
+	return &pluginHost{
+		plugin:  pluginName,
+		logger:  p.logger.With("plugin", pluginName),
+		store:   p.store,
+		blobs:   p.blobs,
+		stored:  blobs,
+		emitted: queue,
+	}
+}
+
+func (h *pluginHost) Log(level, message string, fields map[string]interface{}) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		args = append(args, k, fields[k])
+	}
+
+	switch level {
+	case types.LogDebug:
+		h.logger.Debug(message, args...)
+	case types.LogWarn:
+		h.logger.Warn(message, args...)
+	case types.LogError:
+		h.logger.Error(message, args...)
+	default:
+		h.logger.Info(message, args...)
+	}
+}
+
+func (h *pluginHost) Get(key string) (interface{}, bool, error) {
+	if h.store == nil {
+		return nil, false, types.ErrNoHost
+	}
+	return h.store.Get(h.plugin, key)
+}
+
+func (h *pluginHost) Set(key string, value interface{}) error {
+	if h.store == nil {
+		return types.ErrNoHost
+	}
+	return h.store.Set(h.plugin, key, value)
+}
+
+func (h *pluginHost) Delete(key string) error {
+	if h.store == nil {
+		return types.ErrNoHost
+	}
+	return h.store.Delete(h.plugin, key)
+}
+
+func (h *pluginHost) Emit(event types.Event) error {
+	if h.emitted == nil {
+		return types.ErrNoHost
+	}
+	h.logger.Debug("plugin emitted event", "type", event.Type, "source", event.Source)
+	h.emitted.add(event)
+	return nil
+}
+
+func (h *pluginHost) Progress(percent float64, message string) {
+	h.logger.Info("plugin progress", "percent", percent, "message", message)
+}
+
+func (h *pluginHost) PutBlob(name, contentType string, r io.Reader) (types.Blob, error) {
+	if h.blobs == nil {
+		return types.Blob{}, types.ErrNoHost
+	}
+	blob, err := h.blobs.Put(name, contentType, r)
+	if err != nil {
+		return types.Blob{}, err
+	}
+	h.logger.Debug("plugin stored blob", "handle", blob.Handle, "name", blob.Name, "size", blob.Size)
+	if h.stored != nil {
+		h.stored.add(blob.Handle)
+	}
+	return blob, nil
+}
+
+func (h *pluginHost) OpenBlob(handle string) (io.ReadCloser, types.Blob, error) {
+	if h.blobs == nil {
+		return nil, types.Blob{}, types.ErrNoHost
+	}
+	return h.blobs.Open(handle)
+}
+
+type emittedKey struct{}
+
+type storedKey struct{}
+
+type followUpDepthKey struct{}
+
+// emitted collects the events plugins emit while one event is handled
+type emitted struct {
+	mu     sync.Mutex
+	events []types.Event
+}
+
+func (e *emitted) add(event types.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *emitted) drain() []types.Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	events := e.events
+	e.events = nil
+	return events
+}
+
+// stored collects the handles of the blobs plugins store while one event and
+// its follow-ups are handled
+type stored struct {
+	mu      sync.Mutex
+	handles []string
+}
+
+func (s *stored) add(handle string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handles = append(s.handles, handle)
+}
+
+func (s *stored) drain() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handles := s.handles
+	s.handles = nil
+	return handles
+}
+
+// runFollowUps runs the events emitted while handling an event, in the order
+// they were emitted, each routed by its own match rules and untraced
+func (p *Pipeline) runFollowUps(ctx context.Context, result *Result, events []types.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	depth, _ := ctx.Value(followUpDepthKey{}).(int)
+	if depth >= MaxFollowUpDepth {
+		p.logger.Warn("dropping follow-up events", "count", len(events), "depth", depth)
+		return
+	}
+
+	ctx = context.WithValue(ctx, followUpDepthKey{}, depth+1)
+	ctx = WithTrace(WithPipeline(ctx, ""), nil)
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.logger.Info("running follow-up event", "type", event.Type, "source", event.Source)
+		followUp, err := p.Execute(ctx, event)
+		entry := FollowUp{Result: followUp}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		result.FollowUps = append(result.FollowUps, entry)
+	}
+}
@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestHost_Store(t *testing.T) {
+	counter := &fakePlugin{
+		name:     "counter",
+		priority: 10,
+		process: func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			host, ok := types.HostFrom(ctx)
+			require.True(t, ok)
+
+			count, _, err := host.Get("count")
+			if err != nil {
+				return nil, err
+			}
+			next := int64(1)
+			if n, ok := count.(int64); ok {
+				next = n + 1
+			}
+			if err := host.Set("count", next); err != nil {
+				return nil, err
+			}
+			c.Properties["count"] = next
+			return c, nil
+		},
+	}
+	p := newTestPipeline(t, config.PipelineConfig{}, counter)
+
+	for want := int64(1); want <= 3; want++ {
+		result, err := p.Execute(context.Background(), testEvent())
+		require.NoError(t, err)
+		assert.Equal(t, want, result.Context.Properties["count"])
+	}
+
+	// Keys belong to the plugin that wrote them
+	value, found, err := p.store.Get("counter", "count")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(3), value)
+}
+
+func TestHost_Blobs(t *testing.T) {
+	shouter := &fakePlugin{
+		name:     "shouter",
+		priority: 10,
+		process: func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			host, _ := types.HostFrom(ctx)
+
+			r, attachment, err := host.OpenBlob(c.Event.Attachments[0].Handle)
+			if err != nil {
+				return nil, err
+			}
+			defer func() { _ = r.Close() }()
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+
+			blob, err := host.PutBlob("loud-"+attachment.Name, "", strings.NewReader(strings.ToUpper(string(data))))
+			if err != nil {
+				return nil, err
+			}
+			c.Properties["artifact"] = blob.Handle
+			return c, nil
+		},
+	}
+	p := newTestPipeline(t, config.PipelineConfig{}, shouter)
+
+	attachment, err := p.Blobs().Put("note.txt", "", strings.NewReader("hello"))
+	require.NoError(t, err)
+	event := testEvent()
+	event.Attachments = []types.Blob{attachment}
+
+	result, err := p.Execute(context.Background(), event)
+	require.NoError(t, err)
+
+	artifact := result.Context.Properties["artifact"].(string)
+	r, blob, err := p.Blobs().Open(artifact)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "HELLO", string(data))
+	assert.Equal(t, "loud-note.txt", blob.Name)
+
+	// Releasing the result deletes the event's blobs, not the pipeline's
+	other, err := p.Blobs().Put("other.txt", "", strings.NewReader("kept"))
+	require.NoError(t, err)
+	require.NoError(t, result.Release())
+	for _, handle := range []string{attachment.Handle, artifact} {
+		_, _, err = p.Blobs().Open(handle)
+		assert.ErrorIs(t, err, types.ErrBlobNotFound)
+	}
+	r, _, err = p.Blobs().Open(other.Handle)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.NoError(t, result.Release())
+}
+
+func TestHost_FollowUps(t *testing.T) {
+	var seen []string
+	emitter := &fakePlugin{
+		name:     "emitter",
+		priority: 10,
+		process: func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			seen = append(seen, c.Event.Content)
+			host, _ := types.HostFrom(ctx)
+			host.Progress(50, "halfway")
+			if err := host.Emit(types.Event{Type: types.EventCommand, Source: "emitter", Content: c.Event.Content + "+"}); err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+	}
+	p := newTestPipeline(t, config.PipelineConfig{}, emitter)
+
+	result, err := p.Execute(context.Background(), testEvent())
+	require.NoError(t, err)
+
+	// Each follow-up emits another, until the depth limit drops the chain
+	assert.Equal(t, []string{"hello", "hello+", "hello++", "hello+++"}, seen)
+	require.Len(t, result.FollowUps, 1)
+	assert.Equal(t, "hello+", result.FollowUps[0].Result.Context.Event.Content)
+	assert.Equal(t, types.EventCommand, result.FollowUps[0].Result.Context.Event.Type)
+	assert.Empty(t, result.FollowUps[0].Error)
+
+	depth := 0
+	for r := result; len(r.FollowUps) > 0; r = r.FollowUps[0].Result {
+		depth++
+	}
+	assert.Equal(t, MaxFollowUpDepth, depth)
+}
+
+func TestHost_ExplainHasNoHost(t *testing.T) {
+	var available bool
+	plugin := &fakePlugin{
+		name:     "probe",
+		priority: 10,
+		shouldExecute: func(ctx context.Context, c *types.Context) types.ExecutionDecision {
+			_, available = types.HostFrom(ctx)
+			return types.ExecutionDecision{ShouldExecute: true}
+		},
+	}
+	p := newTestPipeline(t, config.PipelineConfig{}, plugin)
+
+	_, err := p.Explain(context.Background(), testEvent(), nil)
+	require.NoError(t, err)
+	assert.False(t, available, "explain must not let plugins cause side effects")
+}
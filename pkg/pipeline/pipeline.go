@@ -2,85 +2,564 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
-	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/blobstore"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/kvstore"
 	pluginpkg "github.com/williamokano/hashicorp-plugin-example/pkg/plugin"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
 
+// ErrPropertyConflict is reported when plugins running in the same parallel
+// stage write the same Properties key
+var ErrPropertyConflict = errors.New("conflicting property write")
+
+// ErrEventRejected is returned when a plugin rejected the event
+var ErrEventRejected = errors.New("event rejected")
+
+const (
+	// DefaultShouldExecuteTimeout applies when neither the plugin nor the
+	// project config sets a ShouldExecute deadline
+	DefaultShouldExecuteTimeout = 5 * time.Second
+
+	// DefaultProcessTimeout applies when neither the plugin nor the project
+	// config sets a Process deadline
+	DefaultProcessTimeout = 30 * time.Second
+)
+
 type Pipeline struct {
 	manager *pluginpkg.Manager
+	pool    *pool
+	config  config.PipelineConfig
 	logger  hclog.Logger
+	store   *kvstore.Store
+	blobs   *blobstore.Store
 }
 
 type LoadedPlugin struct {
+	Name   string // discovered name, used to look up project config
 	Client *plugin.Client
 	Plugin types.VersionedPlugin
 }
 
+// NewPipeline creates a pipeline configured from the project's plugins.json.
+// Plugin processes are started on the first event and kept running until
+// Close is called.
 func NewPipeline() *Pipeline {
+	cfg, err := config.LoadPipelineConfig()
+	p := NewPipelineWithConfig(cfg)
+	if err != nil {
+		p.logger.Warn("failed to load project config, using defaults", "error", err)
+	}
+	return p
+}
+
+// NewPipelineWithConfig creates a pipeline with explicit pipeline settings.
+// Its plugin manager configures plugins from the same cfg, so plugins.json is
+// never read again behind the caller's back.
+func NewPipelineWithConfig(cfg config.PipelineConfig) *Pipeline {
+	manager := pluginpkg.NewManagerWithConfig(cfg)
+	logger := hclog.New(&hclog.LoggerOptions{
+		Name:  "pipeline",
+		Level: hclog.Info,
+	})
+
 	return &Pipeline{
-		manager: pluginpkg.NewManager(),
-		logger: hclog.New(&hclog.LoggerOptions{
-			Name:  "pipeline",
-			Level: hclog.Info,
-		}),
+		manager: manager,
+		pool:    newPool(manager.Load, manager.Stop, manager.ListPlugins, cfg.CircuitBreaker, logger),
+		config:  cfg,
+		logger:  logger,
+		store:   kvstore.New(config.GetStateDirectory()),
+		blobs:   blobstore.New(),
 	}
 }
 
-// ProcessEvent runs all plugins in priority order
+// Close stops all plugin processes owned by the pipeline and removes the
+// blobs they stored
+func (p *Pipeline) Close() error {
+	p.pool.Close()
+	if p.manager != nil {
+		// Processes discovery started that were never loaded from
+		p.manager.Close()
+	}
+	return p.blobs.Close()
+}
+
+// Blobs returns the store of blobs passed between the CLI and plugins. Put
+// event attachments in it before Execute; an event's blobs can be read from
+// it until its Result is released.
+func (p *Pipeline) Blobs() *blobstore.Store {
+	return p.blobs
+}
+
+// ProcessEvent runs the event through the plugins of its pipeline. The
+// event's blobs are released before it returns; use Execute to read them.
 func (p *Pipeline) ProcessEvent(ctx context.Context, event types.Event) (*types.Context, error) {
-	// Initialize context
-	context := &types.Context{
-		Event:      event,
-		Properties: make(map[string]interface{}),
-		Responses:  []types.Response{},
+	result, err := p.Execute(ctx, event)
+	if releaseErr := result.Release(); releaseErr != nil {
+		p.logger.Warn("failed to release blobs", "error", releaseErr)
+	}
+	return result.Context, err
+}
+
+// Execute runs the event through the plugins of its pipeline (see
+// WithPipeline and the project's named pipelines) and reports the outcome of
+// each one alongside the final context. If ctx carries a Trace (see
+// WithTrace) it is filled in and also attached to the result. Events emitted
+// by plugins through their types.Host run afterwards as follow-ups. Release
+// the result once done with it to delete the event's blobs.
+func (p *Pipeline) Execute(ctx context.Context, event types.Event) (*Result, error) {
+	queue := &emitted{}
+	ctx = context.WithValue(ctx, emittedKey{}, queue)
+
+	// Follow-ups keep their blobs with the event they came from
+	blobs, followUp := ctx.Value(storedKey{}).(*stored)
+	if !followUp {
+		blobs = &stored{}
+		ctx = context.WithValue(ctx, storedKey{}, blobs)
 	}
 
-	// Discover and load all plugins
-	plugins, err := p.loadAllPlugins()
+	var result *Result
+	var err error
+	if trace := TraceFrom(ctx); trace == nil {
+		result, err = p.execute(ctx, event, nil)
+	} else {
+		trace.start(event)
+		result, err = p.execute(ctx, event, trace)
+		trace.finish(result.Pipeline, err)
+		result.Trace = trace
+	}
+
+	p.runFollowUps(ctx, result, queue.drain())
+
+	if !followUp {
+		handles := blobs.drain()
+		for _, attachment := range event.Attachments {
+			handles = append(handles, attachment.Handle)
+		}
+		result.release = func() error { return p.deleteBlobs(handles) }
+	}
+	return result, err
+}
+
+// deleteBlobs removes blobs from the store, trying every one
+func (p *Pipeline) deleteBlobs(handles []string) error {
+	var errs []error
+	for _, handle := range handles {
+		errs = append(errs, p.blobs.Delete(handle))
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Pipeline) execute(ctx context.Context, event types.Event, trace *Trace) (*Result, error) {
+	result := &Result{
+		Context: &types.Context{
+			Event:      event,
+			Properties: make(map[string]interface{}),
+			Responses:  []types.Response{},
+		},
+		Plugins: []PluginResult{},
+	}
+
+	// Reuse warm plugin processes, already sorted by priority
+	plugins, err := p.pool.Acquire()
 	if err != nil {
-		return context, fmt.Errorf("failed to load plugins: %w", err)
+		return result, fmt.Errorf("failed to load plugins: %w", err)
 	}
-	defer p.cleanupPlugins(plugins)
 
-	// Sort plugins by priority
-	sort.Slice(plugins, func(i, j int) bool {
-		return plugins[i].Plugin.Priority() < plugins[j].Plugin.Priority()
-	})
+	result.Pipeline, plugins, err = p.route(ctx, event, plugins)
+	if err != nil {
+		return result, err
+	}
 
-	// Execute plugins in order
-	for _, loadedPlugin := range plugins {
-		pluginName := loadedPlugin.Plugin.Name()
-		p.logger.Info("checking plugin", "name", pluginName, "priority", loadedPlugin.Plugin.Priority())
+	if timeout := time.Duration(p.config.EventTimeout); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-		// Check if plugin should execute
-		decision := loadedPlugin.Plugin.ShouldExecute(ctx, context)
-		if !decision.ShouldExecute {
-			p.logger.Info("plugin skipped", "name", pluginName, "reason", decision.Reason)
-			continue
+	// Execute plugins in order, one stage at a time
+	stages := p.stages(plugins)
+	for stageIndex, stage := range stages {
+		if ctx.Err() != nil {
+			return result, p.eventError(ctx)
+		}
+
+		var stageResults []PluginResult
+		var diffs []*ContextDiff
+		if len(stage) == 1 {
+			// Snapshot before the plugin runs; in-process plugins mutate in place
+			var before *types.Context
+			if trace != nil {
+				before = result.Context.Clone()
+			}
+
+			pluginResult, newContext := p.runPlugin(ctx, stage[0], result.Context, p.config.ErrorPolicyFor(stage[0].Name))
+			stageResults = []PluginResult{pluginResult}
+			diffs = []*ContextDiff{nil}
+
+			// Update context for next plugin
+			if newContext != nil {
+				if trace != nil {
+					diff := diffContext(before, newContext)
+					diffs[0] = &diff
+				}
+				result.Context = newContext
+			}
+		} else {
+			stageResults, diffs, result.Context = p.runStage(ctx, stage, result.Context)
+		}
+
+		if trace != nil {
+			for i, pluginResult := range stageResults {
+				trace.record(stageIndex, pluginResult, diffs[i])
+			}
+		}
+		result.Plugins = append(result.Plugins, stageResults...)
+
+		// Failures caused by the event running out of time say nothing about
+		// the plugin's health
+		for i, pluginResult := range stageResults {
+			if !pluginResult.Failed() || ctx.Err() == nil {
+				p.pool.Report(stage[i].Name, pluginResult)
+			}
+		}
+
+		for i, pluginResult := range stageResults {
+			if pluginResult.Failed() && p.config.ErrorPolicyFor(stage[i].Name).Mode == config.ErrorModeFailFast {
+				p.logger.Error("aborting event", "name", pluginResult.Plugin, "reason", pluginResult.Reason)
+				return result, &ExecutionError{Failures: result.Failures(), Aborted: true}
+			}
+		}
+
+		if result.Context.Halted() {
+			p.skipRemaining(result, stages[stageIndex+1:], stageIndex+1, trace)
+			break
+		}
+	}
+
+	if ctx.Err() != nil {
+		return result, p.eventError(ctx)
+	}
+
+	var errs []error
+	if control := result.Context.Control; control != nil && control.Signal == types.SignalReject {
+		errs = append(errs, fmt.Errorf("%w by %s: %s", ErrEventRejected, control.Plugin, control.Reason))
+	}
+	if failures := result.Failures(); len(failures) > 0 {
+		errs = append(errs, &ExecutionError{Failures: failures})
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// skipRemaining records the plugins that never ran because a plugin stopped
+// or rejected the event
+func (p *Pipeline) skipRemaining(result *Result, stages [][]LoadedPlugin, firstStage int, trace *Trace) {
+	control := result.Context.Control
+	p.logger.Info("pipeline halted", "signal", control.Signal, "plugin", control.Plugin, "reason", control.Reason)
+
+	verb := "stopped"
+	if control.Signal == types.SignalReject {
+		verb = "rejected"
+	}
+	reason := fmt.Sprintf("event %s by %s", verb, control.Plugin)
+	if control.Reason != "" {
+		reason += ": " + control.Reason
+	}
+
+	for i, stage := range stages {
+		for _, loadedPlugin := range stage {
+			skipped := PluginResult{Plugin: loadedPlugin.Plugin.Name(), Outcome: OutcomeSkipped, Reason: reason}
+			if trace != nil {
+				trace.record(firstStage+i, skipped, nil)
+			}
+			result.Plugins = append(result.Plugins, skipped)
+		}
+	}
+}
+
+// runPlugin asks a plugin whether it wants the event and, if so, runs it
+// under its own deadlines, retrying Process as the error policy allows.
+// The returned context is nil unless Process succeeded.
+func (p *Pipeline) runPlugin(ctx context.Context, loadedPlugin LoadedPlugin, current *types.Context, policy config.ErrorPolicy) (result PluginResult, output *types.Context) {
+	pluginName := loadedPlugin.Plugin.Name()
+	timeouts := p.timeoutsFor(loadedPlugin)
+	result = PluginResult{Plugin: pluginName}
+	ctx = types.WithHost(ctx, p.host(ctx, pluginName))
+
+	p.logger.Info("checking plugin", "name", pluginName, "priority", loadedPlugin.Plugin.Priority())
+
+	// Check if plugin should execute
+	decision, elapsed, decisionErr := p.decide(ctx, loadedPlugin, current, timeouts.ShouldExecute)
+	result.ShouldExecuteTime = elapsed
+	result.Decision = decision
+
+	if errors.Is(decisionErr, context.DeadlineExceeded) {
+		result.Outcome = OutcomeTimedOut
+		result.Reason = p.timeoutReason(ctx, "ShouldExecute", timeouts.ShouldExecute)
+		result.Error = decisionErr
+		result.Code = types.ErrorCodeTimeout
+		p.logger.Error("plugin timed out", "name", pluginName, "rpc", "ShouldExecute", "reason", result.Reason)
+		return result, nil
+	}
+
+	if decisionErr != nil {
+		pe := types.AsPluginError(decisionErr)
+		result.Outcome = OutcomeFailed
+		result.Reason = pe.Message
+		result.Error = decisionErr
+		result.Code = pe.Code
+		p.logger.Error("plugin decision failed", "name", pluginName, "code", pe.Code, "error", decisionErr)
+		return result, nil
+	}
+
+	if !decision.ShouldExecute {
+		result.Outcome = OutcomeSkipped
+		result.Reason = decision.Reason
+		p.logger.Info("plugin skipped", "name", pluginName, "reason", decision.Reason)
+		return result, nil
+	}
+
+	started := time.Now()
+	defer func() { result.ProcessTime = time.Since(started) }()
+
+	attempts := policy.Attempts()
+	for attempt := 1; ; attempt++ {
+		result.Attempts = attempt
+		p.logger.Info("executing plugin", "name", pluginName, "attempt", attempt)
+
+		newContext, err := p.process(ctx, loadedPlugin, current, timeouts.Process, &result)
+		if err == nil {
+			if newContext != nil && newContext.Control != nil && newContext.Control.Plugin == "" {
+				newContext.Control.Plugin = pluginName
+			}
+			result.Outcome = OutcomeExecuted
+			result.Reason = decision.Reason
+			p.logger.Info("plugin executed successfully", "name", pluginName)
+			return result, newContext
+		}
+
+		if result.Outcome == OutcomeRejected {
+			return result, nil
 		}
 
-		p.logger.Info("executing plugin", "name", pluginName)
+		// Retrying a dead process is pointless; the pool restarts it before the next event
+		if p.pool.processExited(loadedPlugin) {
+			result.Reason = "plugin process exited: " + result.Reason
+			p.logger.Error("plugin process exited", "name", pluginName)
+			return result, nil
+		}
+
+		if attempt >= attempts || ctx.Err() != nil || !retryable(err) {
+			return result, nil
+		}
+
+		delay := policy.Delay(attempt)
+		p.logger.Warn("retrying plugin", "name", pluginName, "attempt", attempt+1, "backoff", delay)
+		if !sleep(ctx, delay) {
+			return result, nil
+		}
+	}
+}
+
+// decide calls ShouldExecute under its deadline. The error is
+// context.DeadlineExceeded when the deadline fired, or the call's failure
+// for plugins that report one; either way the decision should be ignored.
+func (p *Pipeline) decide(ctx context.Context, loadedPlugin LoadedPlugin, current *types.Context, timeout time.Duration) (types.ExecutionDecision, time.Duration, error) {
+	decisionCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	var decision types.ExecutionDecision
+	var err error
+	if decider, ok := loadedPlugin.Plugin.(types.FallibleDecider); ok {
+		decision, err = decider.Decide(decisionCtx, current)
+	} else {
+		decision = loadedPlugin.Plugin.ShouldExecute(decisionCtx, current)
+	}
+	elapsed := time.Since(started)
+
+	if deadlineErr := decisionCtx.Err(); errors.Is(deadlineErr, context.DeadlineExceeded) {
+		return decision, elapsed, deadlineErr
+	}
+	return decision, elapsed, err
+}
+
+// process makes a single Process call, recording a failure or timeout on result
+func (p *Pipeline) process(ctx context.Context, loadedPlugin LoadedPlugin, current *types.Context, timeout time.Duration, result *PluginResult) (*types.Context, error) {
+	processCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	newContext, err := loadedPlugin.Plugin.Process(processCtx, current)
+
+	if processErr := processCtx.Err(); errors.Is(processErr, context.DeadlineExceeded) {
+		result.Outcome = OutcomeTimedOut
+		result.Reason = p.timeoutReason(ctx, "Process", timeout)
+		result.Error = processErr
+		result.Code = types.ErrorCodeTimeout
+		p.logger.Error("plugin timed out", "name", result.Plugin, "rpc", "Process", "reason", result.Reason)
+		return nil, processErr
+	}
+
+	if err != nil {
+		pe := types.AsPluginError(err)
+		result.Reason = pe.Message
+		result.Error = err
+		result.Code = pe.Code
+
+		if pe.Code == types.ErrorCodeRejected {
+			result.Outcome = OutcomeRejected
+			p.logger.Info("plugin rejected the event", "name", result.Plugin, "reason", pe.Message)
+			return nil, err
+		}
+
+		result.Outcome = OutcomeFailed
+		p.logger.Error("plugin execution failed", "name", result.Plugin, "code", pe.Code, "retryable", pe.Retryable, "error", err)
+		return nil, err
+	}
+
+	return newContext, nil
+}
+
+// retryable reports whether a failed Process call is worth repeating: the
+// plugin said so, or it ran out of time
+func retryable(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || types.IsRetryable(err)
+}
+
+// sleep waits for d or until ctx is done, reporting whether the full wait elapsed
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// stages splits the ordered plugins into groups that run together. In
+// sequential mode every plugin is its own stage.
+func (p *Pipeline) stages(plugins []LoadedPlugin) [][]LoadedPlugin {
+	if p.config.Execution == config.ExecutionParallel {
+		return groupStages(plugins)
+	}
+
+	stages := make([][]LoadedPlugin, len(plugins))
+	for i, lp := range plugins {
+		stages[i] = []LoadedPlugin{lp}
+	}
+	return stages
+}
+
+// runStage runs independent plugins concurrently, each on its own copy of
+// the context, then merges their changes back in stage order. A plugin that
+// writes a Properties key already written by an earlier member of the stage
+// fails with ErrPropertyConflict and its changes are dropped. The returned
+// diffs hold what each plugin contributed to the merged context.
+func (p *Pipeline) runStage(ctx context.Context, stage []LoadedPlugin, base *types.Context) ([]PluginResult, []*ContextDiff, *types.Context) {
+	results := make([]PluginResult, len(stage))
+	outputs := make([]*types.Context, len(stage))
+	diffs := make([]*ContextDiff, len(stage))
+
+	var wg sync.WaitGroup
+	for i, loadedPlugin := range stage {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			policy := p.config.ErrorPolicyFor(loadedPlugin.Name)
+			results[i], outputs[i] = p.runPlugin(ctx, loadedPlugin, base.Clone(), policy)
+		}()
+	}
+	wg.Wait()
 
-		// Execute plugin
-		newContext, err := loadedPlugin.Plugin.Process(ctx, context)
-		if err != nil {
-			p.logger.Error("plugin execution failed", "name", pluginName, "error", err)
-			// Continue with other plugins even if one fails
+	merged := base.Clone()
+	writers := make(map[string]string)
+	for i := range stage {
+		if outputs[i] == nil {
 			continue
 		}
 
-		// Update context for next plugin
-		context = newContext
-		p.logger.Info("plugin executed successfully", "name", pluginName)
+		diff := diffContext(base, outputs[i])
+		if key, other, conflict := findConflict(diff, writers); conflict {
+			results[i].Outcome = OutcomeFailed
+			results[i].Error = fmt.Errorf("%w: %q was also written by %s", ErrPropertyConflict, key, other)
+			results[i].Reason = results[i].Error.Error()
+			p.logger.Error("plugin output conflicts", "name", results[i].Plugin, "key", key, "other", other)
+			continue
+		}
+
+		for _, key := range diff.Keys() {
+			writers[key] = results[i].Plugin
+		}
+		diff.apply(merged)
+		diffs[i] = &diff
+	}
+
+	return results, diffs, merged
+}
+
+func findConflict(diff ContextDiff, writers map[string]string) (key, other string, conflict bool) {
+	for _, key := range diff.Keys() {
+		if other, ok := writers[key]; ok {
+			return key, other, true
+		}
+	}
+	return "", "", false
+}
+
+// timeoutsFor resolves a plugin's deadlines: project config wins over what
+// the plugin declares, which wins over the pipeline defaults
+func (p *Pipeline) timeoutsFor(loadedPlugin LoadedPlugin) types.Timeouts {
+	timeouts := types.Timeouts{
+		ShouldExecute: DefaultShouldExecuteTimeout,
+		Process:       DefaultProcessTimeout,
+	}
+
+	if provider, ok := loadedPlugin.Plugin.(types.TimeoutProvider); ok {
+		declared := provider.Timeouts()
+		if declared.ShouldExecute > 0 {
+			timeouts.ShouldExecute = declared.ShouldExecute
+		}
+		if declared.Process > 0 {
+			timeouts.Process = declared.Process
+		}
+	}
+
+	settings := p.config.PluginSettings(loadedPlugin.Name)
+	if settings.ShouldExecuteTimeout > 0 {
+		timeouts.ShouldExecute = time.Duration(settings.ShouldExecuteTimeout)
+	}
+	if settings.ProcessTimeout > 0 {
+		timeouts.Process = time.Duration(settings.ProcessTimeout)
+	}
+
+	return timeouts
+}
+
+// timeoutReason tells apart a plugin hitting its own deadline from the whole
+// event running out of time while the plugin was running
+func (p *Pipeline) timeoutReason(eventCtx context.Context, rpc string, limit time.Duration) string {
+	if eventCtx.Err() != nil {
+		return fmt.Sprintf("%s interrupted: event deadline exceeded", rpc)
 	}
+	return fmt.Sprintf("%s exceeded plugin deadline of %s", rpc, limit)
+}
 
-	return context, nil
+func (p *Pipeline) eventError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("event deadline of %s exceeded: %w", time.Duration(p.config.EventTimeout), ctx.Err())
+	}
+	return ctx.Err()
 }
 
 // ProcessMessage is a convenience method for processing text messages
@@ -108,34 +587,3 @@ func (p *Pipeline) ProcessCommand(ctx context.Context, source, command, userID,
 	}
 	return p.ProcessEvent(ctx, event)
 }
-
-func (p *Pipeline) loadAllPlugins() ([]LoadedPlugin, error) {
-	discovered, err := discovery.DiscoverPlugins(discovery.GetPluginPaths())
-	if err != nil {
-		return nil, err
-	}
-
-	var plugins []LoadedPlugin
-	for _, disc := range discovered {
-		p.logger.Debug("loading plugin", "name", disc.Name, "path", disc.Path)
-
-		client, plugin, err := p.manager.LoadPluginFromPath(disc.Path)
-		if err != nil {
-			p.logger.Error("failed to load plugin", "name", disc.Name, "error", err)
-			continue
-		}
-
-		plugins = append(plugins, LoadedPlugin{
-			Client: client,
-			Plugin: plugin,
-		})
-	}
-
-	return plugins, nil
-}
-
-func (p *Pipeline) cleanupPlugins(plugins []LoadedPlugin) {
-	for _, plugin := range plugins {
-		plugin.Client.Kill()
-	}
-}
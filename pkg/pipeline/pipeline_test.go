@@ -0,0 +1,534 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/blobstore"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/kvstore"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+type fakePlugin struct {
+	name     string
+	priority int
+	timeouts types.Timeouts
+	deps     types.Dependencies
+
+	shouldExecute func(ctx context.Context, c *types.Context) types.ExecutionDecision
+	process       func(ctx context.Context, c *types.Context) (*types.Context, error)
+
+	// decideErr makes Decide fail the way a GRPCClient does on a broken connection
+	decideErr error
+}
+
+func (f *fakePlugin) ShouldExecute(ctx context.Context, c *types.Context) types.ExecutionDecision {
+	if f.shouldExecute != nil {
+		return f.shouldExecute(ctx, c)
+	}
+	return types.ExecutionDecision{ShouldExecute: true, Reason: "always"}
+}
+
+func (f *fakePlugin) Decide(ctx context.Context, c *types.Context) (types.ExecutionDecision, error) {
+	if f.decideErr != nil {
+		return types.ExecutionDecision{}, f.decideErr
+	}
+	return f.ShouldExecute(ctx, c), nil
+}
+
+func (f *fakePlugin) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	if f.process != nil {
+		return f.process(ctx, c)
+	}
+	c.Properties[f.name] = true
+	return c, nil
+}
+
+func (f *fakePlugin) Name() string             { return f.name }
+func (f *fakePlugin) Description() string      { return "fake plugin" }
+func (f *fakePlugin) Priority() int            { return f.priority }
+func (f *fakePlugin) Version() string          { return "1.0.0" }
+func (f *fakePlugin) BuildTime() string        { return "unknown" }
+func (f *fakePlugin) MinCLIVersion() string    { return "1.0.0" }
+func (f *fakePlugin) MaxCLIVersion() string    { return "2.0.0" }
+func (f *fakePlugin) Timeouts() types.Timeouts { return f.timeouts }
+
+func (f *fakePlugin) Dependencies() types.Dependencies { return f.deps }
+
+// hang blocks until the RPC deadline fires, like a stuck plugin would
+func hang(ctx context.Context, c *types.Context) (*types.Context, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newTestPipeline(t *testing.T, cfg config.PipelineConfig, plugins ...*fakePlugin) *Pipeline {
+	t.Helper()
+
+	byPath := make(map[string]*fakePlugin, len(plugins))
+	for _, p := range plugins {
+		byPath["/plugins/plugin-"+p.name] = p
+	}
+
+	p := &Pipeline{
+		pool:   newTestPool(t, byPath, map[string]int{}),
+		config: cfg,
+		logger: hclog.NewNullLogger(),
+		store:  kvstore.New(t.TempDir()),
+		blobs:  blobstore.New(),
+	}
+	p.pool.breaker = cfg.CircuitBreaker.WithDefaults()
+	t.Cleanup(func() { _ = p.Close() })
+	return p
+}
+
+func testEvent() types.Event {
+	return types.Event{
+		Type:     types.EventMessage,
+		Source:   "test",
+		Content:  "hello",
+		Metadata: map[string]interface{}{},
+	}
+}
+
+func TestPipeline_ExecuteRecordsOutcomes(t *testing.T) {
+	p := newTestPipeline(t, config.PipelineConfig{},
+		&fakePlugin{name: "first", priority: 10},
+		&fakePlugin{name: "skipper", priority: 20, shouldExecute: func(ctx context.Context, c *types.Context) types.ExecutionDecision {
+			return types.ExecutionDecision{ShouldExecute: false, Reason: "not interested"}
+		}},
+		&fakePlugin{name: "last", priority: 30},
+	)
+
+	result, err := p.Execute(context.Background(), testEvent())
+	require.NoError(t, err)
+	require.Len(t, result.Plugins, 3)
+
+	assert.Equal(t, OutcomeExecuted, result.Plugins[0].Outcome)
+	assert.Equal(t, OutcomeSkipped, result.Plugins[1].Outcome)
+	assert.Equal(t, "not interested", result.Plugins[1].Reason)
+	assert.Equal(t, OutcomeExecuted, result.Plugins[2].Outcome)
+	assert.Equal(t, true, result.Context.Properties["first"])
+	assert.Equal(t, true, result.Context.Properties["last"])
+}
+
+func TestPipeline_PluginTimeouts(t *testing.T) {
+	tests := []struct {
+		name   string
+		plugin *fakePlugin
+		config config.PipelineConfig
+		reason string
+	}{
+		{
+			name: "process deadline declared by plugin",
+			plugin: &fakePlugin{
+				name:     "slow",
+				timeouts: types.Timeouts{Process: 20 * time.Millisecond},
+				process:  hang,
+			},
+			reason: "Process exceeded plugin deadline of 20ms",
+		},
+		{
+			name: "should execute deadline from project config",
+			plugin: &fakePlugin{
+				name: "slow",
+				shouldExecute: func(ctx context.Context, c *types.Context) types.ExecutionDecision {
+					<-ctx.Done()
+					return types.ExecutionDecision{ShouldExecute: false, Reason: ctx.Err().Error()}
+				},
+			},
+			config: config.PipelineConfig{
+				Plugins: map[string]config.PluginSettings{
+					"slow": {ShouldExecuteTimeout: config.Duration(20 * time.Millisecond)},
+				},
+			},
+			reason: "ShouldExecute exceeded plugin deadline of 20ms",
+		},
+		{
+			name: "project config overrides plugin metadata",
+			plugin: &fakePlugin{
+				name:     "slow",
+				timeouts: types.Timeouts{Process: time.Hour},
+				process:  hang,
+			},
+			config: config.PipelineConfig{
+				Plugins: map[string]config.PluginSettings{
+					"slow": {ProcessTimeout: config.Duration(20 * time.Millisecond)},
+				},
+			},
+			reason: "Process exceeded plugin deadline of 20ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPipeline(t, tt.config, tt.plugin, &fakePlugin{name: "after", priority: 100})
+
+			result, err := p.Execute(context.Background(), testEvent())
+			var execErr *ExecutionError
+			require.ErrorAs(t, err, &execErr)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			require.Len(t, result.Plugins, 2)
+
+			assert.Equal(t, OutcomeTimedOut, result.Plugins[0].Outcome)
+			assert.Equal(t, tt.reason, result.Plugins[0].Reason)
+
+			// A hung plugin must not stall the rest of the pipeline
+			assert.Equal(t, OutcomeExecuted, result.Plugins[1].Outcome)
+		})
+	}
+}
+
+func TestPipeline_EventDeadline(t *testing.T) {
+	cfg := config.PipelineConfig{EventTimeout: config.Duration(20 * time.Millisecond)}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "slow", priority: 10, process: hang},
+		&fakePlugin{name: "never", priority: 20},
+	)
+
+	result, err := p.Execute(context.Background(), testEvent())
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.Len(t, result.Plugins, 1)
+	assert.Equal(t, OutcomeTimedOut, result.Plugins[0].Outcome)
+	assert.Equal(t, "Process interrupted: event deadline exceeded", result.Plugins[0].Reason)
+	assert.NotContains(t, result.Context.Properties, "never")
+}
+
+func TestPipeline_ErrorPolicy(t *testing.T) {
+	errBoom := types.Unavailable("boom", nil)
+	errBug := errors.New("nil pointer")
+	errNope := types.Rejected("not today")
+
+	// flaky fails the first n calls with err, then succeeds
+	flaky := func(n int, err error, calls *int) func(ctx context.Context, c *types.Context) (*types.Context, error) {
+		return func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			*calls++
+			if *calls <= n {
+				return nil, err
+			}
+			c.Properties["flaky"] = true
+			return c, nil
+		}
+	}
+
+	tests := []struct {
+		name         string
+		policy       config.ErrorPolicy
+		failures     int
+		err          error
+		wantErr      bool
+		wantAborted  bool
+		wantOutcome  Outcome
+		wantAttempts int
+		wantLastRun  bool
+	}{
+		{
+			name:         "continue records failure and runs later plugins",
+			policy:       config.ErrorPolicy{Mode: config.ErrorModeContinue},
+			failures:     1,
+			wantErr:      true,
+			wantOutcome:  OutcomeFailed,
+			wantAttempts: 1,
+			wantLastRun:  true,
+		},
+		{
+			name:         "fail fast aborts the event",
+			policy:       config.ErrorPolicy{Mode: config.ErrorModeFailFast},
+			failures:     1,
+			wantErr:      true,
+			wantAborted:  true,
+			wantOutcome:  OutcomeFailed,
+			wantAttempts: 1,
+			wantLastRun:  false,
+		},
+		{
+			name:         "retry recovers from transient failures",
+			policy:       config.ErrorPolicy{Mode: config.ErrorModeRetry, MaxRetries: 2, Backoff: config.Duration(time.Millisecond)},
+			failures:     2,
+			wantErr:      false,
+			wantOutcome:  OutcomeExecuted,
+			wantAttempts: 3,
+			wantLastRun:  true,
+		},
+		{
+			name:         "retry gives up after max retries",
+			policy:       config.ErrorPolicy{Mode: config.ErrorModeRetry, MaxRetries: 1, Backoff: config.Duration(time.Millisecond)},
+			failures:     5,
+			wantErr:      true,
+			wantOutcome:  OutcomeFailed,
+			wantAttempts: 2,
+			wantLastRun:  true,
+		},
+		{
+			name:         "retry skips errors that are not retryable",
+			policy:       config.ErrorPolicy{Mode: config.ErrorModeRetry, MaxRetries: 2, Backoff: config.Duration(time.Millisecond)},
+			failures:     1,
+			err:          errBug,
+			wantErr:      true,
+			wantOutcome:  OutcomeFailed,
+			wantAttempts: 1,
+			wantLastRun:  true,
+		},
+		{
+			name:         "rejection is not a failure",
+			policy:       config.ErrorPolicy{Mode: config.ErrorModeFailFast},
+			failures:     1,
+			err:          errNope,
+			wantErr:      false,
+			wantOutcome:  OutcomeRejected,
+			wantAttempts: 1,
+			wantLastRun:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			if tt.err == nil {
+				tt.err = errBoom
+			}
+			cfg := config.PipelineConfig{
+				Plugins: map[string]config.PluginSettings{
+					"flaky": {ErrorPolicy: tt.policy},
+				},
+			}
+			p := newTestPipeline(t, cfg,
+				&fakePlugin{name: "flaky", priority: 10, process: flaky(tt.failures, tt.err, &calls)},
+				&fakePlugin{name: "last", priority: 20},
+			)
+
+			result, err := p.Execute(context.Background(), testEvent())
+			if tt.wantErr {
+				var execErr *ExecutionError
+				require.ErrorAs(t, err, &execErr)
+				assert.ErrorIs(t, err, tt.err)
+				assert.Equal(t, tt.wantAborted, execErr.Aborted)
+				require.Len(t, execErr.Failures, 1)
+				assert.Equal(t, "flaky", execErr.Failures[0].Plugin)
+			} else {
+				require.NoError(t, err)
+				assert.Empty(t, result.Failures())
+			}
+
+			assert.Equal(t, tt.wantOutcome, result.Plugins[0].Outcome)
+			assert.Equal(t, tt.wantAttempts, result.Plugins[0].Attempts)
+			assert.Equal(t, tt.wantAttempts, calls)
+
+			_, lastRan := result.Context.Properties["last"]
+			assert.Equal(t, tt.wantLastRun, lastRan)
+		})
+	}
+}
+
+func TestPipeline_ParallelStages(t *testing.T) {
+	// Both plugins wait for each other, so this only finishes if they run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	together := func(key string) func(ctx context.Context, c *types.Context) (*types.Context, error) {
+		return func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			started.Done()
+			started.Wait()
+			c.Properties[key] = true
+			c.Responses = append(c.Responses, types.Response{PluginName: key})
+			return c, nil
+		}
+	}
+
+	cfg := config.PipelineConfig{Execution: config.ExecutionParallel}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "b", priority: 10, process: together("b")},
+		&fakePlugin{name: "a", priority: 10, process: together("a")},
+		&fakePlugin{name: "after", priority: 20},
+	)
+
+	result, err := p.Execute(context.Background(), testEvent())
+	require.NoError(t, err)
+
+	assert.Equal(t, true, result.Context.Properties["a"])
+	assert.Equal(t, true, result.Context.Properties["b"])
+	assert.Equal(t, true, result.Context.Properties["after"])
+
+	// Merged in stage order, not completion order
+	require.Len(t, result.Context.Responses, 2)
+	assert.Equal(t, "a", result.Context.Responses[0].PluginName)
+	assert.Equal(t, "b", result.Context.Responses[1].PluginName)
+}
+
+func TestPipeline_ParallelConflict(t *testing.T) {
+	write := func(value string) func(ctx context.Context, c *types.Context) (*types.Context, error) {
+		return func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			c.Properties["shared"] = value
+			return c, nil
+		}
+	}
+
+	cfg := config.PipelineConfig{Execution: config.ExecutionParallel}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "first", priority: 10, process: write("first")},
+		&fakePlugin{name: "second", priority: 10, process: write("second")},
+	)
+
+	result, err := p.Execute(context.Background(), testEvent())
+	var execErr *ExecutionError
+	require.ErrorAs(t, err, &execErr)
+	assert.ErrorIs(t, err, ErrPropertyConflict)
+
+	require.Len(t, execErr.Failures, 1)
+	assert.Equal(t, "second", execErr.Failures[0].Plugin)
+	assert.Contains(t, execErr.Failures[0].Reason, `"shared" was also written by first`)
+	assert.Equal(t, "first", result.Context.Properties["shared"])
+}
+
+func TestPipeline_CircuitOpensAfterRepeatedFailures(t *testing.T) {
+	calls := 0
+	cfg := config.PipelineConfig{CircuitBreaker: config.CircuitBreaker{FailureThreshold: 2}}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "broken", priority: 10, process: func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			calls++
+			return nil, errors.New("boom")
+		}},
+		&fakePlugin{name: "healthy", priority: 20},
+	)
+
+	for i := 0; i < 2; i++ {
+		_, err := p.Execute(context.Background(), testEvent())
+		require.Error(t, err)
+	}
+
+	trace := &Trace{}
+	result, err := p.Execute(WithTrace(context.Background(), trace), testEvent())
+	require.NoError(t, err, "a plugin behind an open circuit is skipped, not failed")
+	assert.Equal(t, 2, calls)
+
+	require.Len(t, trace.Plugins, 2)
+	assert.Equal(t, "broken", trace.Plugins[0].Plugin)
+	assert.Equal(t, OutcomeSkipped, trace.Plugins[0].Outcome)
+	assert.Contains(t, trace.Plugins[0].Reason, "circuit open after 2 consecutive failures")
+	assert.Equal(t, true, result.Context.Properties["healthy"])
+}
+
+func TestPipeline_DecisionFailure(t *testing.T) {
+	p := newTestPipeline(t, config.PipelineConfig{},
+		&fakePlugin{name: "unreachable", priority: 10, decideErr: types.NewError(types.ErrorCodeTransport, "plugin call failed", errors.New("connection refused"))},
+		&fakePlugin{name: "healthy", priority: 20},
+	)
+
+	result, err := p.Execute(context.Background(), testEvent())
+	var execErr *ExecutionError
+	require.ErrorAs(t, err, &execErr)
+	require.Len(t, execErr.Failures, 1)
+
+	failure := execErr.Failures[0]
+	assert.Equal(t, "unreachable", failure.Plugin)
+	assert.Equal(t, OutcomeFailed, failure.Outcome, "a broken connection is a failure, not a skip")
+	assert.Equal(t, types.ErrorCodeTransport, failure.Code)
+	assert.Equal(t, "plugin call failed", failure.Reason)
+	assert.Equal(t, true, result.Context.Properties["healthy"])
+}
+
+func TestPipeline_NoRetryAfterProcessExit(t *testing.T) {
+	calls := 0
+	cfg := config.PipelineConfig{ErrorPolicy: config.ErrorPolicy{Mode: config.ErrorModeRetry, Backoff: config.Duration(time.Millisecond)}}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "crashy", process: func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			calls++
+			return nil, types.NewError(types.ErrorCodeTransport, "connection is unavailable", nil)
+		}},
+	)
+	// The process dies during the first Process call
+	p.pool.exited = func(*plugin.Client) bool { return calls > 0 }
+
+	result, err := p.Execute(context.Background(), testEvent())
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+	require.Len(t, result.Plugins, 1)
+	assert.Equal(t, OutcomeFailed, result.Plugins[0].Outcome)
+	assert.Equal(t, "plugin process exited: connection is unavailable", result.Plugins[0].Reason)
+}
+
+func TestPipeline_ControlSignals(t *testing.T) {
+	halt := func(signal types.Signal) func(ctx context.Context, c *types.Context) (*types.Context, error) {
+		return func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			c.Properties["gate"] = true
+			c.Control = &types.Control{Signal: signal, Reason: "because"}
+			return c, nil
+		}
+	}
+
+	tests := []struct {
+		name     string
+		signal   types.Signal
+		wantErr  bool
+		wantLast Outcome
+		reason   string
+	}{
+		{name: "continue runs everything", signal: types.SignalContinue, wantLast: OutcomeExecuted, reason: "always"},
+		{name: "stop skips the rest", signal: types.SignalStop, wantLast: OutcomeSkipped, reason: "event stopped by gate: because"},
+		{name: "reject skips the rest and errors", signal: types.SignalReject, wantErr: true, wantLast: OutcomeSkipped, reason: "event rejected by gate: because"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asked := false
+			p := newTestPipeline(t, config.PipelineConfig{},
+				&fakePlugin{name: "gate", priority: 10, process: halt(tt.signal)},
+				&fakePlugin{name: "last", priority: 20, shouldExecute: func(ctx context.Context, c *types.Context) types.ExecutionDecision {
+					asked = true
+					return types.ExecutionDecision{ShouldExecute: true, Reason: "always"}
+				}},
+			)
+
+			result, err := p.Execute(context.Background(), testEvent())
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrEventRejected)
+				assert.EqualError(t, err, "event rejected by gate: because")
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.NotNil(t, result.Context.Control)
+			assert.Equal(t, "gate", result.Context.Control.Plugin)
+			assert.Equal(t, true, result.Context.Properties["gate"], "the halting plugin's own changes are kept")
+
+			require.Len(t, result.Plugins, 2)
+			assert.Equal(t, tt.wantLast, result.Plugins[1].Outcome)
+			assert.Equal(t, tt.reason, result.Plugins[1].Reason)
+			assert.Equal(t, tt.signal == types.SignalContinue, asked)
+		})
+	}
+}
+
+func TestPipeline_ParallelStopFirstSignalWins(t *testing.T) {
+	signal := func(signal types.Signal, reason string) func(ctx context.Context, c *types.Context) (*types.Context, error) {
+		return func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			c.Control = &types.Control{Signal: signal, Reason: reason}
+			return c, nil
+		}
+	}
+
+	cfg := config.PipelineConfig{Execution: config.ExecutionParallel}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "a", priority: 10, process: signal(types.SignalStop, "handled")},
+		&fakePlugin{name: "b", priority: 10, process: signal(types.SignalReject, "spam")},
+		&fakePlugin{name: "later", priority: 20},
+	)
+
+	trace := &Trace{}
+	result, err := p.Execute(WithTrace(context.Background(), trace), testEvent())
+	require.NoError(t, err)
+
+	assert.Equal(t, types.Control{Signal: types.SignalStop, Reason: "handled", Plugin: "a"}, *result.Context.Control)
+	require.Len(t, trace.Plugins, 3)
+	assert.Equal(t, 1, trace.Plugins[2].Stage)
+	assert.Equal(t, OutcomeSkipped, trace.Plugins[2].Outcome)
+	assert.Equal(t, "event stopped by a: handled", trace.Plugins[2].Reason)
+}
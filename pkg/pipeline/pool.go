@@ -0,0 +1,325 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// loaderFunc starts a discovered plugin and returns its client and dispensed
+// plugin. Hooks and remote plugins have no process of their own and come
+// back with a nil client.
+type loaderFunc func(d discovery.DiscoveredPlugin) (*plugin.Client, types.VersionedPlugin, error)
+
+// stopFunc shuts a plugin down and kills its process
+type stopFunc func(client *plugin.Client, p types.VersionedPlugin)
+
+// discoverFunc returns the plugins that should be part of the pool
+type discoverFunc func() ([]discovery.DiscoveredPlugin, error)
+
+// pooledPlugin is a plugin process kept alive across events
+type pooledPlugin struct {
+	name   string
+	source discovery.DiscoveredPlugin
+	client *plugin.Client
+	plugin types.VersionedPlugin
+
+	// processless plugins have no process of the CLI's to keep running or
+	// restart: hooks start one per call and remote plugins run elsewhere
+	processless bool
+
+	// identity is captured at load time so the plugin can still be named and
+	// ordered while its process is down
+	identity unavailablePlugin
+
+	failures    int       // consecutive failed events
+	openUntil   time.Time // circuit is open, skipping the plugin, until then
+	restarts    int       // restarts since the last successful event
+	nextRestart time.Time // earliest time the process may be restarted again
+}
+
+// pool keeps one running process per discovered plugin and reuses it
+// across events, restarting processes that have exited (with backoff) and
+// opening a circuit for plugins that keep failing.
+type pool struct {
+	load     loaderFunc
+	stop     stopFunc
+	discover discoverFunc
+	breaker  config.CircuitBreaker
+	logger   hclog.Logger
+
+	// Swappable for tests
+	now    func() time.Time
+	exited func(*plugin.Client) bool
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	plugins []*pooledPlugin
+}
+
+func newPool(load loaderFunc, stop stopFunc, discover discoverFunc, breaker config.CircuitBreaker, logger hclog.Logger) *pool {
+	return &pool{
+		load:     load,
+		stop:     stop,
+		discover: discover,
+		breaker:  breaker.WithDefaults(),
+		logger:   logger,
+		now:      time.Now,
+		exited:   (*plugin.Client).Exited,
+	}
+}
+
+// Acquire returns the plugins in priority order, starting them on first use
+// and restarting any whose process has exited since the last event. Plugins
+// that cannot run right now (open circuit, restart backoff) are returned as
+// stand-ins that decline the event and say why.
+func (p *pool) Acquire() ([]LoadedPlugin, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, fmt.Errorf("plugin pool is closed")
+	}
+
+	if !p.started {
+		if err := p.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := p.now()
+	loaded := make([]LoadedPlugin, 0, len(p.plugins))
+	for _, pp := range p.plugins {
+		if reason := p.unavailable(pp, now); reason != "" {
+			standIn := pp.identity
+			standIn.reason = reason
+			loaded = append(loaded, LoadedPlugin{Name: pp.name, Plugin: &standIn})
+			continue
+		}
+
+		loaded = append(loaded, LoadedPlugin{
+			Name:   pp.name,
+			Client: pp.client,
+			Plugin: pp.plugin,
+		})
+	}
+
+	return loaded, nil
+}
+
+// unavailable makes sure the plugin's process is running, restarting it if
+// its backoff allows, and returns why the plugin cannot run if it cannot
+func (p *pool) unavailable(pp *pooledPlugin, now time.Time) string {
+	if now.Before(pp.openUntil) {
+		return fmt.Sprintf("circuit open after %d consecutive failures, retrying in %s",
+			pp.failures, pp.openUntil.Sub(now).Round(time.Millisecond))
+	}
+
+	if pp.processless || (pp.client != nil && !p.exited(pp.client)) {
+		return ""
+	}
+
+	if now.Before(pp.nextRestart) {
+		return fmt.Sprintf("plugin process exited, restarting in %s", pp.nextRestart.Sub(now).Round(time.Millisecond))
+	}
+
+	if err := p.restart(pp, now); err != nil {
+		p.logger.Error("failed to restart plugin", "name", pp.name, "error", err)
+		return fmt.Sprintf("plugin process exited and restart failed: %v", err)
+	}
+	return ""
+}
+
+// processExited reports whether the process behind a loaded plugin is gone
+func (p *pool) processExited(lp LoadedPlugin) bool {
+	return lp.Client != nil && p.exited(lp.Client)
+}
+
+// Report feeds a plugin's outcome for one event into its circuit breaker.
+// Failures open the circuit once they reach the threshold; the first
+// successful event after the cooldown closes it again.
+func (p *pool) Report(name string, result PluginResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var pp *pooledPlugin
+	for _, candidate := range p.plugins {
+		if candidate.name == name {
+			pp = candidate
+			break
+		}
+	}
+	if pp == nil {
+		return
+	}
+
+	switch {
+	// A rejection is a deliberate answer, so the plugin is healthy
+	case result.Outcome == OutcomeExecuted || result.Outcome == OutcomeRejected:
+		if pp.failures >= p.breaker.FailureThreshold {
+			p.logger.Info("circuit closed", "name", name)
+		}
+		pp.failures = 0
+		pp.restarts = 0
+		pp.openUntil = time.Time{}
+
+	// A conflict is about how plugins were combined, not the plugin's health
+	case result.Failed() && !errors.Is(result.Error, ErrPropertyConflict):
+		pp.failures++
+		if pp.failures >= p.breaker.FailureThreshold {
+			pp.openUntil = p.now().Add(time.Duration(p.breaker.Cooldown))
+			p.logger.Warn("circuit opened", "name", name, "failures", pp.failures, "cooldown", time.Duration(p.breaker.Cooldown))
+		}
+	}
+}
+
+// Close stops every plugin process owned by the pool. Plugins are stopped
+// concurrently so each gets its full grace period without the others waiting.
+func (p *pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, pp := range p.plugins {
+		// Remote plugins have no process but do have a connection to close
+		if pp.client != nil || pp.processless {
+			wg.Add(1)
+			go func(client *plugin.Client, plugin types.VersionedPlugin) {
+				defer wg.Done()
+				p.stop(client, plugin)
+			}(pp.client, pp.plugin)
+			pp.client = nil
+		}
+	}
+	wg.Wait()
+
+	p.plugins = nil
+	p.closed = true
+}
+
+func (p *pool) start() error {
+	discovered, err := p.discover()
+	if err != nil {
+		return fmt.Errorf("failed to discover plugins: %w", err)
+	}
+
+	for _, disc := range discovered {
+		p.logger.Debug("loading plugin", "name", disc.Name, "path", disc.Path)
+
+		client, plugin, err := p.load(disc)
+		if err != nil {
+			p.logger.Error("failed to load plugin", "name", disc.Name, "error", err)
+			continue
+		}
+
+		p.plugins = append(p.plugins, &pooledPlugin{
+			name:        disc.Name,
+			source:      disc,
+			client:      client,
+			plugin:      plugin,
+			processless: client == nil,
+			identity:    identityOf(plugin),
+		})
+	}
+
+	// Dependencies and priority are fixed for the lifetime of a plugin
+	// binary, so order once at load time
+	if err := p.order(); err != nil {
+		for _, pp := range p.plugins {
+			p.stop(pp.client, pp.plugin)
+		}
+		p.plugins = nil
+		return err
+	}
+
+	p.started = true
+	return nil
+}
+
+// order arranges the pooled plugins by their dependency graph and priority
+func (p *pool) order() error {
+	byPlugin := make(map[types.VersionedPlugin]*pooledPlugin, len(p.plugins))
+	loaded := make([]LoadedPlugin, len(p.plugins))
+	for i, pp := range p.plugins {
+		byPlugin[pp.plugin] = pp
+		loaded[i] = LoadedPlugin{Name: pp.name, Client: pp.client, Plugin: pp.plugin}
+	}
+
+	ordered, err := orderPlugins(loaded)
+	if err != nil {
+		return err
+	}
+
+	for i, lp := range ordered {
+		p.plugins[i] = byPlugin[lp.Plugin]
+	}
+	return nil
+}
+
+// restart replaces the plugin's process. Every restart without a successful
+// event in between pushes the next allowed restart further out.
+func (p *pool) restart(pp *pooledPlugin, now time.Time) error {
+	pp.restarts++
+	pp.nextRestart = now.Add(p.breaker.RestartDelay(pp.restarts))
+	p.logger.Info("restarting plugin", "name", pp.name, "path", pp.source.Path, "restarts", pp.restarts)
+
+	if pp.client != nil {
+		p.stop(pp.client, pp.plugin)
+		pp.client = nil
+	}
+
+	client, plugin, err := p.load(pp.source)
+	if err != nil {
+		return err
+	}
+
+	pp.client = client
+	pp.plugin = plugin
+	return nil
+}
+
+// unavailablePlugin stands in for a plugin that cannot run this event. It
+// answers metadata from what was captured at load time and declines every
+// event with the reason it is unavailable.
+type unavailablePlugin struct {
+	name        string
+	description string
+	priority    int
+	deps        types.Dependencies
+	reason      string
+}
+
+func identityOf(p types.VersionedPlugin) unavailablePlugin {
+	return unavailablePlugin{
+		name:        p.Name(),
+		description: p.Description(),
+		priority:    p.Priority(),
+		deps:        dependenciesOf(p),
+	}
+}
+
+func (u *unavailablePlugin) ShouldExecute(ctx context.Context, c *types.Context) types.ExecutionDecision {
+	return types.ExecutionDecision{ShouldExecute: false, Reason: u.reason}
+}
+
+func (u *unavailablePlugin) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	return nil, fmt.Errorf("plugin %s is unavailable: %s", u.name, u.reason)
+}
+
+func (u *unavailablePlugin) Name() string                     { return u.name }
+func (u *unavailablePlugin) Description() string              { return u.description }
+func (u *unavailablePlugin) Priority() int                    { return u.priority }
+func (u *unavailablePlugin) Version() string                  { return "" }
+func (u *unavailablePlugin) BuildTime() string                { return "" }
+func (u *unavailablePlugin) MinCLIVersion() string            { return "" }
+func (u *unavailablePlugin) MaxCLIVersion() string            { return "" }
+func (u *unavailablePlugin) Dependencies() types.Dependencies { return u.deps }
@@ -0,0 +1,267 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func newTestPool(t *testing.T, plugins map[string]*fakePlugin, loads map[string]int) *pool {
+	t.Helper()
+
+	discover := func() ([]discovery.DiscoveredPlugin, error) {
+		var discovered []discovery.DiscoveredPlugin
+		for path, p := range plugins {
+			discovered = append(discovered, discovery.DiscoveredPlugin{Name: p.name, Path: path, Kind: discovery.KindFromPath(path)})
+		}
+		return discovered, nil
+	}
+
+	load := func(d discovery.DiscoveredPlugin) (*plugin.Client, types.VersionedPlugin, error) {
+		p, ok := plugins[d.Path]
+		if !ok {
+			return nil, nil, errors.New("unknown plugin")
+		}
+		loads[d.Path]++
+		if d.Kind == discovery.KindHook {
+			return nil, p, nil
+		}
+		return &plugin.Client{}, p, nil
+	}
+
+	stop := func(client *plugin.Client, p types.VersionedPlugin) {
+		if client != nil {
+			client.Kill()
+		}
+	}
+
+	return newPool(load, stop, discover, config.CircuitBreaker{}, hclog.NewNullLogger())
+}
+
+func TestPool_AcquireStartsOnceAndSorts(t *testing.T) {
+	plugins := map[string]*fakePlugin{
+		"/plugins/plugin-uploader": {name: "uploader", priority: 50},
+		"/plugins/plugin-filter":   {name: "filter", priority: 10},
+		"/plugins/plugin-convert":  {name: "converter", priority: 30},
+	}
+	loads := map[string]int{}
+	p := newTestPool(t, plugins, loads)
+	defer p.Close()
+
+	for i := 0; i < 3; i++ {
+		loaded, err := p.Acquire()
+		require.NoError(t, err)
+		require.Len(t, loaded, 3)
+
+		assert.Equal(t, "filter", loaded[0].Plugin.Name())
+		assert.Equal(t, "converter", loaded[1].Plugin.Name())
+		assert.Equal(t, "uploader", loaded[2].Plugin.Name())
+	}
+
+	for path, count := range loads {
+		assert.Equal(t, 1, count, "plugin %s should be started once", path)
+	}
+}
+
+func TestPool_HooksShareOrdering(t *testing.T) {
+	plugins := map[string]*fakePlugin{
+		"/plugins/plugin-uploader": {name: "uploader", priority: 50},
+		"/plugins/hook-audit":      {name: "audit", priority: 20},
+		"/plugins/plugin-filter":   {name: "filter", priority: 10},
+	}
+	loads := map[string]int{}
+	p := newTestPool(t, plugins, loads)
+	defer p.Close()
+
+	for i := 0; i < 2; i++ {
+		loaded, err := p.Acquire()
+		require.NoError(t, err)
+		require.Len(t, loaded, 3)
+
+		assert.Equal(t, "filter", loaded[0].Plugin.Name())
+		assert.Equal(t, "audit", loaded[1].Plugin.Name())
+		assert.Equal(t, "uploader", loaded[2].Plugin.Name())
+
+		// A hook has no process to keep alive, so it is never restarted
+		assert.Nil(t, loaded[1].Client)
+		assert.Same(t, plugins["/plugins/hook-audit"], loaded[1].Plugin)
+	}
+	assert.Equal(t, 1, loads["/plugins/hook-audit"])
+}
+
+func TestPool_RestartsMissingClient(t *testing.T) {
+	plugins := map[string]*fakePlugin{
+		"/plugins/plugin-filter": {name: "filter", priority: 10},
+	}
+	loads := map[string]int{}
+	p := newTestPool(t, plugins, loads)
+	defer p.Close()
+
+	_, err := p.Acquire()
+	require.NoError(t, err)
+
+	// Simulate a process that went away between events
+	p.plugins[0].client = nil
+
+	loaded, err := p.Acquire()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.NotNil(t, loaded[0].Client)
+	assert.Equal(t, 2, loads["/plugins/plugin-filter"])
+}
+
+func TestPool_AcquireAfterClose(t *testing.T) {
+	p := newTestPool(t, map[string]*fakePlugin{}, map[string]int{})
+
+	p.Close()
+
+	_, err := p.Acquire()
+	assert.Error(t, err)
+}
+
+func TestPool_CloseStopsEveryPlugin(t *testing.T) {
+	plugins := map[string]*fakePlugin{
+		"/plugins/plugin-filter":   {name: "filter", priority: 10},
+		"/plugins/plugin-uploader": {name: "uploader", priority: 50},
+		"/plugins/hook-audit":      {name: "audit", priority: 20},
+	}
+	p := newTestPool(t, plugins, map[string]int{})
+	_, err := p.Acquire()
+	require.NoError(t, err)
+
+	// The plugins block in shutdown until the others have started, so Close
+	// only returns if they are stopped concurrently
+	var mu sync.Mutex
+	var stopped []string
+	var started sync.WaitGroup
+	started.Add(3)
+	p.stop = func(client *plugin.Client, vp types.VersionedPlugin) {
+		started.Done()
+		started.Wait()
+		mu.Lock()
+		stopped = append(stopped, vp.Name())
+		mu.Unlock()
+	}
+
+	p.Close()
+	assert.ElementsMatch(t, []string{"filter", "uploader", "audit"}, stopped)
+}
+
+// fakeClock is a manually advanced clock for backoff and cooldown tests
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestPool_RestartBackoff(t *testing.T) {
+	plugins := map[string]*fakePlugin{
+		"/plugins/plugin-filter": {name: "filter", priority: 10},
+	}
+	loads := map[string]int{}
+	p := newTestPool(t, plugins, loads)
+	defer p.Close()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	p.now = clock.Now
+	p.breaker = config.CircuitBreaker{RestartBackoff: config.Duration(time.Second)}.WithDefaults()
+
+	// Every process dies right after it starts
+	p.exited = func(*plugin.Client) bool { return true }
+
+	_, err := p.Acquire()
+	require.NoError(t, err)
+
+	// The first restart is immediate
+	loaded, err := p.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, "filter", loaded[0].Plugin.Name())
+	assert.Equal(t, 2, loads["/plugins/plugin-filter"])
+
+	// The next one waits out the backoff, skipping the plugin meanwhile
+	loaded, err = p.Acquire()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "filter", loaded[0].Plugin.Name())
+	assert.Nil(t, loaded[0].Client)
+	decision := loaded[0].Plugin.ShouldExecute(context.Background(), &types.Context{})
+	assert.False(t, decision.ShouldExecute)
+	assert.Equal(t, "plugin process exited, restarting in 1s", decision.Reason)
+	assert.Equal(t, 2, loads["/plugins/plugin-filter"])
+
+	clock.Advance(time.Second)
+	_, err = p.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, 3, loads["/plugins/plugin-filter"])
+
+	// Backoff doubles while the plugin keeps crashing
+	clock.Advance(time.Second)
+	loaded, err = p.Acquire()
+	require.NoError(t, err)
+	assert.Nil(t, loaded[0].Client)
+	assert.Equal(t, 3, loads["/plugins/plugin-filter"])
+
+	// A successful event resets it
+	p.exited = func(*plugin.Client) bool { return false }
+	clock.Advance(time.Second)
+	_, err = p.Acquire()
+	require.NoError(t, err)
+	p.Report("filter", PluginResult{Outcome: OutcomeExecuted})
+	assert.Zero(t, p.plugins[0].restarts)
+}
+
+func TestPool_CircuitBreaker(t *testing.T) {
+	plugins := map[string]*fakePlugin{
+		"/plugins/plugin-filter": {name: "filter", priority: 10},
+	}
+	p := newTestPool(t, plugins, map[string]int{})
+	defer p.Close()
+
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	p.now = clock.Now
+	p.breaker = config.CircuitBreaker{FailureThreshold: 2, Cooldown: config.Duration(10 * time.Second)}.WithDefaults()
+
+	acquire := func() LoadedPlugin {
+		loaded, err := p.Acquire()
+		require.NoError(t, err)
+		require.Len(t, loaded, 1)
+		return loaded[0]
+	}
+	failure := PluginResult{Outcome: OutcomeFailed, Error: errors.New("boom")}
+
+	acquire()
+	p.Report("filter", failure)
+	assert.NotNil(t, acquire().Client, "one failure is below the threshold")
+
+	p.Report("filter", failure)
+	open := acquire()
+	assert.Nil(t, open.Client)
+	assert.Equal(t, "circuit open after 2 consecutive failures, retrying in 10s",
+		open.Plugin.ShouldExecute(context.Background(), &types.Context{}).Reason)
+
+	// Skips and conflicts do not count either way
+	p.Report("filter", PluginResult{Outcome: OutcomeSkipped})
+	p.Report("filter", PluginResult{Outcome: OutcomeFailed, Error: ErrPropertyConflict})
+
+	// After the cooldown the plugin gets a trial; failing it reopens the circuit
+	clock.Advance(10 * time.Second)
+	assert.NotNil(t, acquire().Client)
+	p.Report("filter", failure)
+	assert.Nil(t, acquire().Client)
+
+	// A successful trial closes it
+	clock.Advance(10 * time.Second)
+	assert.NotNil(t, acquire().Client)
+	p.Report("filter", PluginResult{Outcome: OutcomeExecuted})
+	p.Report("filter", failure)
+	assert.NotNil(t, acquire().Client)
+}
@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Outcome describes how a plugin's turn in the pipeline ended
+type Outcome string
+
+const (
+	OutcomeExecuted Outcome = "executed"
+	OutcomeSkipped  Outcome = "skipped"
+	OutcomeFailed   Outcome = "failed"
+	OutcomeTimedOut Outcome = "timed_out"
+	// OutcomeRejected means Process refused the event with a rejected error;
+	// the plugin's changes are dropped but it does not count as a failure
+	OutcomeRejected Outcome = "rejected"
+)
+
+// PluginResult records what happened to a single plugin for one event
+type PluginResult struct {
+	Plugin  string  `json:"plugin"`
+	Outcome Outcome `json:"outcome"`
+	Reason  string  `json:"reason,omitempty"`
+	Error   error   `json:"-"`
+
+	// Code classifies Error; see types.PluginError
+	Code types.ErrorCode `json:"code,omitempty"`
+
+	// Attempts counts Process calls, including retries
+	Attempts int `json:"attempts,omitempty"`
+
+	// Decision is what ShouldExecute returned
+	Decision types.ExecutionDecision `json:"decision"`
+
+	// ShouldExecuteTime and ProcessTime are wall-clock RPC durations;
+	// ProcessTime covers all attempts including backoff
+	ShouldExecuteTime time.Duration `json:"should_execute_ns"`
+	ProcessTime       time.Duration `json:"process_ns,omitempty"`
+}
+
+// Failed reports whether the plugin ended in failure or timeout
+func (r PluginResult) Failed() bool {
+	return r.Outcome == OutcomeFailed || r.Outcome == OutcomeTimedOut
+}
+
+// Result is the outcome of running an event through the pipeline
+type Result struct {
+	Context *types.Context `json:"context"`
+	Plugins []PluginResult `json:"plugins"`
+
+	// Pipeline names the pipeline the event was routed to; empty when it ran
+	// through every plugin
+	Pipeline string `json:"pipeline,omitempty"`
+
+	// Trace is set when tracing was requested with WithTrace
+	Trace *Trace `json:"trace,omitempty"`
+
+	// FollowUps holds the events plugins emitted while handling this one
+	FollowUps []FollowUp `json:"follow_ups,omitempty"`
+
+	// release deletes the event's blobs; see Release
+	release func() error
+}
+
+// Release deletes the event's attachments and the blobs plugins stored while
+// handling it and its follow-ups. Read the blobs the result refers to, e.g.
+// to save files, before releasing it. Releasing twice does nothing.
+func (r *Result) Release() error {
+	if r.release == nil {
+		return nil
+	}
+	release := r.release
+	r.release = nil
+	return release()
+}
+
+// Failures returns the results of plugins that failed or timed out
+func (r *Result) Failures() []PluginResult {
+	var failures []PluginResult
+	for _, pr := range r.Plugins {
+		if pr.Failed() {
+			failures = append(failures, pr)
+		}
+	}
+	return failures
+}
+
+// ExecutionError is returned alongside the final context when one or more
+// plugins failed, so callers can tell a partial run from a clean one
+type ExecutionError struct {
+	Failures []PluginResult
+
+	// Aborted is set when a fail-fast policy stopped the event early
+	Aborted bool
+}
+
+func (e *ExecutionError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = fmt.Sprintf("%s (%s: %s)", f.Plugin, f.Outcome, f.Reason)
+	}
+
+	msg := fmt.Sprintf("%d plugin(s) failed: %s", len(e.Failures), strings.Join(parts, ", "))
+	if e.Aborted {
+		msg = "event aborted, " + msg
+	}
+	return msg
+}
+
+// Unwrap exposes the individual plugin errors to errors.Is and errors.As
+func (e *ExecutionError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		if f.Error != nil {
+			errs = append(errs, f.Error)
+		}
+	}
+	return errs
+}
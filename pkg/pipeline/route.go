@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+type pipelineKey struct{}
+
+// WithPipeline returns a context that makes the pipeline run the named
+// pipeline from the project config instead of routing the event by its
+// match rules
+func WithPipeline(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, pipelineKey{}, name)
+}
+
+func pipelineFrom(ctx context.Context) string {
+	name, _ := ctx.Value(pipelineKey{}).(string)
+	return name
+}
+
+// route picks the plugins for an event: the pipeline forced with
+// WithPipeline, else the first named pipeline matching the event, else every
+// plugin in dependency order. The returned name is empty in the last case.
+func (p *Pipeline) route(ctx context.Context, event types.Event, plugins []LoadedPlugin) (string, []LoadedPlugin, error) {
+	named, ok := p.config.PipelineFor(event)
+	if forced := pipelineFrom(ctx); forced != "" {
+		if named, ok = p.config.NamedPipeline(forced); !ok {
+			return "", nil, fmt.Errorf("unknown pipeline: %s", forced)
+		}
+	}
+	if !ok {
+		return "", plugins, nil
+	}
+
+	byName := make(map[string]LoadedPlugin, len(plugins))
+	for _, lp := range plugins {
+		byName[lp.Name] = lp
+	}
+
+	selected := make([]LoadedPlugin, 0, len(named.Plugins))
+	for _, name := range named.Plugins {
+		lp, ok := byName[name]
+		if !ok {
+			return named.Name, nil, fmt.Errorf("pipeline %s: plugin %s is not installed", named.Name, name)
+		}
+		selected = append(selected, lp)
+	}
+
+	p.logger.Debug("routed event", "pipeline", named.Name, "plugins", named.Plugins)
+	return named.Name, selected, nil
+}
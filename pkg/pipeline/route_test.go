@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func executedPlugins(result *Result) []string {
+	var names []string
+	for _, pr := range result.Plugins {
+		names = append(names, pr.Plugin)
+	}
+	return names
+}
+
+func TestPipeline_Routing(t *testing.T) {
+	cfg := config.PipelineConfig{
+		Pipelines: []config.NamedPipeline{
+			// Explicit order wins over priority
+			{Name: "discord", Plugins: []string{"uploader", "filter"}, Match: config.EventMatch{Sources: []string{"discord"}}},
+			{Name: "hooks", Plugins: []string{"uploader"}, Match: config.EventMatch{Types: []types.EventType{types.EventWebhook}}},
+		},
+	}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "filter", priority: 10},
+		&fakePlugin{name: "converter", priority: 20},
+		&fakePlugin{name: "uploader", priority: 30},
+	)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		event    types.Event
+		pipeline string
+		plugins  []string
+	}{
+		{
+			name:     "matched by source",
+			ctx:      context.Background(),
+			event:    types.Event{Type: types.EventMessage, Source: "discord"},
+			pipeline: "discord",
+			plugins:  []string{"uploader", "filter"},
+		},
+		{
+			name:     "matched by type",
+			ctx:      context.Background(),
+			event:    types.Event{Type: types.EventWebhook, Source: "github"},
+			pipeline: "hooks",
+			plugins:  []string{"uploader"},
+		},
+		{
+			name:    "unmatched runs everything",
+			ctx:     context.Background(),
+			event:   types.Event{Type: types.EventMessage, Source: "cli"},
+			plugins: []string{"filter", "converter", "uploader"},
+		},
+		{
+			name:     "forced pipeline ignores match rules",
+			ctx:      WithPipeline(context.Background(), "hooks"),
+			event:    types.Event{Type: types.EventMessage, Source: "discord"},
+			pipeline: "hooks",
+			plugins:  []string{"uploader"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Execute(tt.ctx, tt.event)
+			require.NoError(t, err)
+			assert.Equal(t, tt.pipeline, result.Pipeline)
+			assert.Equal(t, tt.plugins, executedPlugins(result))
+		})
+	}
+}
+
+func TestPipeline_RoutingErrors(t *testing.T) {
+	cfg := config.PipelineConfig{
+		Pipelines: []config.NamedPipeline{
+			{Name: "broken", Plugins: []string{"filter", "missing"}, Match: config.EventMatch{Sources: []string{"discord"}}},
+		},
+	}
+	p := newTestPipeline(t, cfg, &fakePlugin{name: "filter"})
+
+	_, err := p.Execute(WithPipeline(context.Background(), "nope"), testEvent())
+	assert.EqualError(t, err, "unknown pipeline: nope")
+
+	_, err = p.Execute(context.Background(), types.Event{Source: "discord"})
+	assert.EqualError(t, err, "pipeline broken: plugin missing is not installed")
+
+	_, err = p.Explain(WithPipeline(context.Background(), "nope"), testEvent(), nil)
+	assert.EqualError(t, err, "unknown pipeline: nope")
+}
+
+func TestPipeline_TraceRecordsPipeline(t *testing.T) {
+	cfg := config.PipelineConfig{
+		Pipelines: []config.NamedPipeline{{Name: "only-a", Plugins: []string{"a"}}},
+	}
+	p := newTestPipeline(t, cfg, &fakePlugin{name: "a"}, &fakePlugin{name: "b"})
+
+	trace := &Trace{}
+	_, err := p.Execute(WithTrace(context.Background(), trace), testEvent())
+	require.NoError(t, err)
+	assert.Equal(t, "only-a", trace.Pipeline)
+	require.Len(t, trace.Plugins, 1)
+
+	explanation, err := p.Explain(context.Background(), testEvent(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "only-a", explanation.Pipeline)
+	assert.Len(t, explanation.Plugins, 1)
+}
@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Trace is a structured record of how an event moved through the pipeline
+type Trace struct {
+	Event     types.Event   `json:"event"`
+	Pipeline  string        `json:"pipeline,omitempty"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Plugins   []PluginTrace `json:"plugins"`
+	Error     string        `json:"error,omitempty"`
+
+	mu sync.Mutex
+}
+
+// PluginTrace is one plugin's entry in a Trace
+type PluginTrace struct {
+	PluginResult
+
+	// Stage is the index of the stage the plugin ran in
+	Stage int `json:"stage"`
+
+	// Error is the plugin error message, if any
+	Error string `json:"error,omitempty"`
+
+	// Diff holds the Properties and Responses the plugin introduced
+	Diff *ContextDiff `json:"diff,omitempty"`
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context that makes the pipeline record its execution
+// into trace. Works with Execute, ProcessEvent and the convenience wrappers.
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceFrom returns the trace attached to ctx, or nil if tracing is off
+func TraceFrom(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// WriteFile saves the trace as indented JSON
+func (t *Trace) WriteFile(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trace: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write trace: %w", err)
+	}
+
+	return nil
+}
+
+func (t *Trace) start(event types.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Event = event
+	t.StartedAt = time.Now()
+	t.Plugins = []PluginTrace{}
+}
+
+func (t *Trace) record(stage int, result PluginResult, diff *ContextDiff) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := PluginTrace{
+		PluginResult: result,
+		Stage:        stage,
+		Diff:         diff,
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+	}
+	t.Plugins = append(t.Plugins, entry)
+}
+
+func (t *Trace) finish(pipeline string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Pipeline = pipeline
+	t.Duration = time.Since(t.StartedAt)
+	if err != nil {
+		t.Error = err.Error()
+	}
+}
@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestPipeline_TraceRecordsDecisionsAndDiffs(t *testing.T) {
+	p := newTestPipeline(t, config.PipelineConfig{},
+		&fakePlugin{name: "writer", priority: 10, process: func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			time.Sleep(time.Millisecond)
+			c.Properties["written"] = "yes"
+			c.Responses = append(c.Responses, types.Response{PluginName: "writer", Type: "text", Content: "done"})
+			return c, nil
+		}},
+		&fakePlugin{name: "skipper", priority: 20, shouldExecute: func(ctx context.Context, c *types.Context) types.ExecutionDecision {
+			return types.ExecutionDecision{ShouldExecute: false, Reason: "nothing to do"}
+		}},
+		&fakePlugin{name: "broken", priority: 30, process: func(ctx context.Context, c *types.Context) (*types.Context, error) {
+			return nil, errors.New("boom")
+		}},
+	)
+
+	trace := &Trace{}
+	_, err := p.ProcessEvent(WithTrace(context.Background(), trace), testEvent())
+	require.Error(t, err)
+
+	assert.Equal(t, "hello", trace.Event.Content)
+	assert.False(t, trace.StartedAt.IsZero())
+	assert.Positive(t, trace.Duration)
+	assert.Contains(t, trace.Error, "broken")
+	require.Len(t, trace.Plugins, 3)
+
+	writer := trace.Plugins[0]
+	assert.Equal(t, OutcomeExecuted, writer.Outcome)
+	assert.True(t, writer.Decision.ShouldExecute)
+	assert.GreaterOrEqual(t, writer.ProcessTime, time.Millisecond)
+	require.NotNil(t, writer.Diff)
+	assert.Equal(t, map[string]interface{}{"written": "yes"}, writer.Diff.Set)
+	require.Len(t, writer.Diff.Responses, 1)
+	assert.Equal(t, "done", writer.Diff.Responses[0].Content)
+
+	skipper := trace.Plugins[1]
+	assert.Equal(t, OutcomeSkipped, skipper.Outcome)
+	assert.Equal(t, "nothing to do", skipper.Decision.Reason)
+	assert.Zero(t, skipper.ProcessTime)
+	assert.Nil(t, skipper.Diff)
+
+	broken := trace.Plugins[2]
+	assert.Equal(t, OutcomeFailed, broken.Outcome)
+	assert.Equal(t, "boom", broken.Error)
+	assert.Nil(t, broken.Diff)
+	assert.Equal(t, []int{0, 1, 2}, []int{writer.Stage, skipper.Stage, broken.Stage})
+}
+
+func TestPipeline_TraceParallelStages(t *testing.T) {
+	cfg := config.PipelineConfig{Execution: config.ExecutionParallel}
+	p := newTestPipeline(t, cfg,
+		&fakePlugin{name: "a", priority: 10},
+		&fakePlugin{name: "b", priority: 10},
+		&fakePlugin{name: "c", priority: 20},
+	)
+
+	trace := &Trace{}
+	result, err := p.Execute(WithTrace(context.Background(), trace), testEvent())
+	require.NoError(t, err)
+	assert.Same(t, trace, result.Trace)
+
+	require.Len(t, trace.Plugins, 3)
+	assert.Equal(t, 0, trace.Plugins[0].Stage)
+	assert.Equal(t, 0, trace.Plugins[1].Stage)
+	assert.Equal(t, 1, trace.Plugins[2].Stage)
+	for _, pt := range trace.Plugins {
+		require.NotNil(t, pt.Diff, pt.Plugin)
+		assert.Equal(t, map[string]interface{}{pt.Plugin: true}, pt.Diff.Set)
+	}
+}
+
+func TestPipeline_NoTraceByDefault(t *testing.T) {
+	p := newTestPipeline(t, config.PipelineConfig{}, &fakePlugin{name: "a"})
+
+	result, err := p.Execute(context.Background(), testEvent())
+	require.NoError(t, err)
+	assert.Nil(t, result.Trace)
+	assert.Nil(t, TraceFrom(context.Background()))
+}
+
+func TestTrace_WriteFile(t *testing.T) {
+	trace := &Trace{}
+	trace.start(testEvent())
+	trace.record(0, PluginResult{Plugin: "a", Outcome: OutcomeFailed, Error: errors.New("boom")}, nil)
+	trace.finish("", nil)
+
+	path := filepath.Join(t.TempDir(), "trace.json")
+	require.NoError(t, trace.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	plugins := decoded["plugins"].([]interface{})
+	require.Len(t, plugins, 1)
+	entry := plugins[0].(map[string]interface{})
+	assert.Equal(t, "a", entry["plugin"])
+	assert.Equal(t, "failed", entry["outcome"])
+	assert.Equal(t, "boom", entry["error"])
+}
@@ -1,23 +1,74 @@
 package plugin
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
-	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
 	"github.com/williamokano/hashicorp-plugin-example/internal/version"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/hook"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
 
+const (
+	// ConfigureTimeout bounds the Configure call made when a plugin is loaded
+	ConfigureTimeout = 10 * time.Second
+	// InitTimeout bounds the Init call that follows it
+	InitTimeout = 30 * time.Second
+	// HealthTimeout bounds a health check
+	HealthTimeout = 2 * time.Second
+	// ConnectTimeout bounds connecting to a remote plugin
+	ConnectTimeout = 10 * time.Second
+	// CommandsTimeout bounds fetching the commands a plugin contributes
+	CommandsTimeout = 10 * time.Second
+	// ListPluginsTimeout bounds asking a plugin process which plugins it
+	// serves, and reaching one of them
+	ListPluginsTimeout = 5 * time.Second
+)
+
 type Manager struct {
 	logger hclog.Logger
+
+	// config supplies the values each plugin is configured with
+	config config.PipelineConfig
+
+	mu sync.Mutex
+	// idle are the processes ListPlugins attached to to ask which plugins
+	// they serve, kept for loading them, by processKey
+	idle map[string]*process
+	// bundles are the processes serving several plugins that some of their
+	// plugins are loaded from
+	bundles map[*plugin.Client]*process
 }
 
+// NewManager creates a manager configuring plugins from the project config
+// for commands that load plugins without a pipeline; a pipeline hands its
+// manager the config it was created with instead (see
+// pipeline.NewPipelineWithConfig)
 func NewManager() *Manager {
+	cfg, err := config.LoadPipelineConfig()
+	m := NewManagerWithConfig(cfg)
+	if err != nil {
+		m.logger.Warn("failed to load project config, plugins get no configuration", "error", err)
+	}
+	return m
+}
+
+// NewManagerWithConfig creates a manager configuring plugins from cfg
+func NewManagerWithConfig(cfg config.PipelineConfig) *Manager {
 	// Get log level from environment, default to Error
 	level := hclog.Error
 	if envLevel := os.Getenv("PLUGIN_LOG_LEVEL"); envLevel != "" {
@@ -43,70 +94,293 @@ func NewManager() *Manager {
 			Output: os.Stderr,
 			Level:  level,
 		}),
+		config:  cfg,
+		idle:    make(map[string]*process),
+		bundles: make(map[*plugin.Client]*process),
 	}
 }
 
+// LoadPlugin loads a plugin by name, resolving it the way ListPlugins does
 func (m *Manager) LoadPlugin(name string) (*plugin.Client, types.VersionedPlugin, error) {
-	discoveredPlugin, err := discovery.FindPlugin(name)
+	d, err := m.FindPlugin(name)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to discover plugin: %w", err)
+		return nil, nil, err
 	}
+	return m.Load(d)
+}
 
-	return m.LoadPluginFromPath(discoveredPlugin.Path)
+// Load loads a plugin returned by ListPlugins. Plugins served by the same
+// process share it: loading a second one reuses the process the first was
+// loaded from.
+func (m *Manager) Load(d discovery.DiscoveredPlugin) (*plugin.Client, types.VersionedPlugin, error) {
+	switch d.Kind {
+	case discovery.KindRemote:
+		return m.loadRemote(d.Name)
+	case discovery.KindHook:
+		return m.loadHook(d.Path)
+	default:
+		return m.loadProcess(d)
+	}
 }
 
+// LoadPluginFromPath starts the plugin at path and prepares it for events.
+// Hooks run a fresh process per call, so they come back with a nil client,
+// as do remote plugins.
 func (m *Manager) LoadPluginFromPath(path string) (*plugin.Client, types.VersionedPlugin, error) {
-	client := plugin.NewClient(&plugin.ClientConfig{
-		HandshakeConfig: protocol.Handshake,
-		Plugins:         protocol.PluginMap,
-		Cmd:             exec.Command(path),
-		Logger:          m.logger,
-		AllowedProtocols: []plugin.Protocol{
-			plugin.ProtocolGRPC,
-		},
+	return m.Load(discovery.DiscoveredPlugin{
+		Name: discovery.NameFromPath(path),
+		Path: path,
+		Kind: discovery.KindFromPath(path),
 	})
+}
 
-	rpcClient, err := client.Client()
+func (m *Manager) loadHook(path string) (*plugin.Client, types.VersionedPlugin, error) {
+	p, err := hook.Load(context.Background(), path)
 	if err != nil {
-		client.Kill()
-		return nil, nil, fmt.Errorf("failed to create RPC client: %w", err)
+		return nil, nil, err
+	}
+	if err := m.prepare(discovery.NameFromPath(path), p); err != nil {
+		return nil, nil, err
 	}
+	return nil, p, nil
+}
+
+// loadRemote connects to a plugin served at the address the project config
+// gives it. The connection is closed by Stop; the plugin keeps running.
+func (m *Manager) loadRemote(name string) (*plugin.Client, types.VersionedPlugin, error) {
+	settings := m.config.PluginSettings(name)
+
+	var tlsConfig *tls.Config
+	if settings.TLS != nil {
+		var err error
+		if tlsConfig, err = settings.TLS.ClientConfig(); err != nil {
+			return nil, nil, fmt.Errorf("plugin %s: %w", name, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ConnectTimeout)
+	defer cancel()
 
-	raw, err := rpcClient.Dispense("plugin")
+	p, err := protocol.Dial(ctx, settings.Address, tlsConfig)
 	if err != nil {
-		client.Kill()
-		return nil, nil, fmt.Errorf("failed to dispense plugin: %w", err)
+		return nil, nil, err
 	}
+	m.logger.Debug("connected to remote plugin", "name", name, "address", settings.Address)
 
-	p, ok := raw.(types.VersionedPlugin)
-	if !ok {
-		client.Kill()
-		return nil, nil, fmt.Errorf("plugin does not implement VersionedPlugin interface")
+	if err := m.prepare(name, p); err != nil {
+		_ = p.Close()
+		return nil, nil, err
 	}
+	return nil, p, nil
+}
 
+// prepare checks that a freshly loaded plugin works with this CLI, then
+// configures and initializes it
+func (m *Manager) prepare(name string, p types.VersionedPlugin) error {
 	minVersion := p.MinCLIVersion()
 	maxVersion := p.MaxCLIVersion()
 	compatible, err := version.IsCompatible(version.CLIVersion, minVersion, maxVersion)
 	if err != nil {
-		client.Kill()
-		return nil, nil, fmt.Errorf("failed to check version compatibility: %w", err)
+		return fmt.Errorf("failed to check version compatibility: %w", err)
 	}
 
 	if !compatible {
-		client.Kill()
-		return nil, nil, fmt.Errorf("plugin version incompatible: CLI version %s, plugin requires %s-%s",
+		return fmt.Errorf("plugin version incompatible: CLI version %s, plugin requires %s-%s",
 			version.CLIVersion, minVersion, maxVersion)
 	}
 
-	return client, p, nil
+	if err := m.configure(name, p); err != nil {
+		return err
+	}
+
+	if initializer, ok := p.(types.Initializer); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), InitTimeout)
+		defer cancel()
+		if err := initializer.Init(ctx); err != nil {
+			return fmt.Errorf("plugin %s failed to initialize: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Stop asks a loaded plugin to shut down, waits up to the configured grace
+// period for it to clean up, then kills its process. A process serving
+// several plugins is killed once the last plugin loaded from it is stopped.
+// Hooks have no process to stop, remote plugins only have their connection
+// closed, and plugins serving in debug mode keep running for the next CLI.
+func (m *Manager) Stop(client *plugin.Client, p types.VersionedPlugin) {
+	if closer, ok := p.(io.Closer); ok {
+		defer func() { _ = closer.Close() }()
+	}
+	if client == nil {
+		return
+	}
+	if shutdowner, ok := p.(types.Shutdowner); ok && !client.Exited() {
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.GracePeriod())
+		if err := shutdowner.Shutdown(ctx); err != nil {
+			m.logger.Warn("plugin did not shut down cleanly", "name", p.Name(), "error", err)
+		}
+		cancel()
+	}
+	if !m.stopMember(client, p) {
+		client.Kill()
+	}
+}
+
+// Close lets go of the processes ListPlugins attached to that no plugin was
+// loaded from
+func (m *Manager) Close() {
+	m.mu.Lock()
+	idle := m.idle
+	m.idle = make(map[string]*process)
+	m.mu.Unlock()
+
+	for _, proc := range idle {
+		m.kill(proc)
+	}
+}
+
+// Health checks a loaded plugin's health
+func (m *Manager) Health(p types.VersionedPlugin) types.Health {
+	checker, ok := p.(types.HealthChecker)
+	if !ok {
+		return types.Health{Status: types.HealthUnknown}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), HealthTimeout)
+	defer cancel()
+	return checker.Health(ctx)
+}
+
+// Commands fetches the CLI commands a loaded plugin contributes; plugins
+// that are not types.CommandProvider have none
+func (m *Manager) Commands(p types.VersionedPlugin) ([]types.Command, error) {
+	provider, ok := p.(types.CommandProvider)
+	if !ok {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CommandsTimeout)
+	defer cancel()
+	return provider.Commands(ctx)
 }
 
+// configure sends a freshly loaded plugin its config block. Plugins that take
+// no configuration are only an error when they were given some.
+func (m *Manager) configure(name string, p types.VersionedPlugin) error {
+	values, err := m.config.PluginConfig(name)
+	if err != nil {
+		return err
+	}
+
+	configurable, ok := p.(types.Configurable)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ConfigureTimeout)
+	defer cancel()
+
+	err = configurable.Configure(ctx, values)
+	if errors.Is(err, protocol.ErrNotConfigurable) {
+		if len(values) > 0 {
+			return fmt.Errorf("plugin %s does not accept configuration", name)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("plugin %s rejected its configuration: %w", name, err)
+	}
+	return nil
+}
+
+// ListPlugins returns the plugins found on disk, the remote plugins in the
+// project config and the plugins in protocol.EnvReattach. A plugin in
+// EnvReattach replaces any other of the same name, and a remote plugin
+// replaces a local one. A binary serving several plugins is listed as one
+// plugin per plugin it serves, named <binary>.<plugin>, as its manifest
+// lists them (see discovery.BundleSuffix); no binary is started. A plugin in
+// EnvReattach is already running and is asked instead, its process kept for
+// loading its plugins until Close.
 func (m *Manager) ListPlugins() ([]discovery.DiscoveredPlugin, error) {
-	return discovery.DiscoverPlugins(discovery.GetPluginPaths())
+	discovered, err := discovery.DiscoverPlugins(discovery.GetPluginPaths())
+	if err != nil {
+		return nil, err
+	}
+	reattach, err := protocol.ReattachFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	remote := m.config.RemotePlugins()
+	plugins := make([]discovery.DiscoveredPlugin, 0, len(discovered)+len(remote)+len(reattach))
+	for _, d := range discovered {
+		name := processName(d)
+		if _, debugging := reattach[name]; !debugging && m.config.PluginSettings(name).Address == "" {
+			plugins = append(plugins, d)
+		}
+	}
+	for _, name := range remote {
+		if _, debugging := reattach[name]; !debugging {
+			plugins = append(plugins, remotePlugin(name, m.config.PluginSettings(name).Address))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(reattach)) {
+		plugins = append(plugins, m.expand(discovery.DiscoveredPlugin{
+			Name: name,
+			Path: reattach[name].Address,
+			Kind: discovery.KindReattach,
+		})...)
+	}
+	return plugins, nil
+}
+
+// FindPlugin resolves a plugin name with the same precedence as ListPlugins.
+// A name that is not a plugin of its own but <binary>.<plugin> resolves to
+// that plugin of a binary serving several, without asking the binary.
+func (m *Manager) FindPlugin(name string) (discovery.DiscoveredPlugin, error) {
+	reattach, err := protocol.ReattachFromEnv()
+	if err != nil {
+		return discovery.DiscoveredPlugin{}, err
+	}
+	if debug, ok := reattach[name]; ok {
+		return discovery.DiscoveredPlugin{Name: name, Path: debug.Address, Kind: discovery.KindReattach}, nil
+	}
+
+	if address := m.config.PluginSettings(name).Address; address != "" {
+		return remotePlugin(name, address), nil
+	}
+
+	d, err := discovery.FindPlugin(name)
+	if err == nil {
+		return *d, nil
+	}
+	if binary, member, ok := cutLast(name, "."); ok && errors.Is(err, discovery.ErrNotFound) {
+		if process, findErr := m.FindPlugin(binary); findErr == nil && process.Member == "" &&
+			(process.Kind == discovery.KindPlugin || process.Kind == discovery.KindReattach) {
+			process.Name = name
+			process.Member = member
+			return process, nil
+		}
+	}
+	return discovery.DiscoveredPlugin{}, fmt.Errorf("failed to discover plugin: %w", err)
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+func remotePlugin(name, address string) discovery.DiscoveredPlugin {
+	return discovery.DiscoveredPlugin{Name: name, Path: address, Kind: discovery.KindRemote}
 }
 
 func (m *Manager) GetPluginMetadata(p types.VersionedPlugin) types.PluginMetadata {
-	return types.PluginMetadata{
+	metadata := types.PluginMetadata{
 		Name:          p.Name(),
 		Version:       p.Version(),
 		BuildTime:     p.BuildTime(),
@@ -115,4 +389,18 @@ func (m *Manager) GetPluginMetadata(p types.VersionedPlugin) types.PluginMetadat
 		Description:   p.Description(),
 		Priority:      p.Priority(),
 	}
+
+	if tp, ok := p.(types.TimeoutProvider); ok {
+		metadata.Timeouts = tp.Timeouts()
+	}
+
+	if dp, ok := p.(types.DependencyProvider); ok {
+		metadata.Dependencies = dp.Dependencies()
+	}
+
+	if pv, ok := p.(protocol.ProtocolVersioned); ok {
+		metadata.ProtocolVersion = pv.ProtocolVersion()
+	}
+
+	return metadata
 }
@@ -0,0 +1,335 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// process is a plugin process the manager started or attached to. A process
+// serving several plugins (a bundle) is shared by the plugins loaded from
+// it and runs until the last of them is stopped.
+type process struct {
+	key    string // processKey of what it was started from
+	client *plugin.Client
+
+	// root is what was dispensed: the plugin itself, or for a bundle the
+	// client its plugins are reached through
+	root *protocol.GRPCClient
+
+	// names are the plugins a bundle serves, nil for a single plugin
+	names []string
+
+	// loaded are the plugins of a bundle loaded and not yet stopped
+	loaded map[string]types.VersionedPlugin
+}
+
+func processKey(d discovery.DiscoveredPlugin) string {
+	return string(d.Kind) + ":" + d.Path
+}
+
+// processName is the name of the binary or debug process serving d
+func processName(d discovery.DiscoveredPlugin) string {
+	if d.Member == "" {
+		return d.Name
+	}
+	return strings.TrimSuffix(d.Name, "."+d.Member)
+}
+
+// members lists the plugins of the bundle served for d, named as in
+// ListPlugins
+func (p *process) members(d discovery.DiscoveredPlugin) []discovery.DiscoveredPlugin {
+	members := make([]discovery.DiscoveredPlugin, 0, len(p.names))
+	for _, name := range p.names {
+		members = append(members, discovery.DiscoveredPlugin{
+			Name:   d.Name + "." + name,
+			Path:   d.Path,
+			Kind:   d.Kind,
+			Member: name,
+		})
+	}
+	return members
+}
+
+// loadProcess loads the plugin a plugin process serves, or the one of a
+// bundle's plugins d names, and prepares it for events
+func (m *Manager) loadProcess(d discovery.DiscoveredPlugin) (*plugin.Client, types.VersionedPlugin, error) {
+	proc, err := m.acquire(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if proc.names == nil {
+		if err := m.prepare(d.Name, proc.root); err != nil {
+			m.kill(proc)
+			return nil, nil, err
+		}
+		return proc.client, proc.root, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ListPluginsTimeout)
+	defer cancel()
+	p, err := proc.root.Plugin(ctx, d.Member)
+	if err != nil {
+		m.release(proc, d.Member)
+		return nil, nil, fmt.Errorf("failed to dispense plugin %s: %w", d.Name, err)
+	}
+	if err := m.prepare(d.Name, p); err != nil {
+		_ = p.Close()
+		m.release(proc, d.Member)
+		return nil, nil, err
+	}
+
+	m.mu.Lock()
+	proc.loaded[d.Member] = p
+	m.mu.Unlock()
+	return proc.client, p, nil
+}
+
+// acquire returns the process to load d from: a running bundle process d
+// is not loaded from yet, one ListPlugins attached to, or a new one. A bundle
+// process comes back registered with d's plugin reserved.
+func (m *Manager) acquire(d discovery.DiscoveredPlugin) (*process, error) {
+	key := processKey(d)
+
+	m.mu.Lock()
+	proc, shared := m.idle[key], false
+	delete(m.idle, key)
+	if proc == nil && d.Member != "" {
+		for _, running := range m.bundles {
+			if _, busy := running.loaded[d.Member]; running.key == key && !busy && !running.client.Exited() {
+				proc, shared = running, true
+				break
+			}
+		}
+	}
+	if shared {
+		// Reserve the plugin so a concurrent load starts a process of its own
+		proc.loaded[d.Member] = nil
+	}
+	m.mu.Unlock()
+
+	if proc != nil && !shared && proc.client.Exited() {
+		m.kill(proc)
+		proc = nil
+	}
+	if proc == nil {
+		var err error
+		if proc, err = m.spawn(d); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkMember(d, proc.names); err != nil {
+		if shared {
+			m.release(proc, d.Member)
+		} else {
+			m.kill(proc)
+		}
+		return nil, err
+	}
+
+	if proc.names != nil && !shared {
+		m.mu.Lock()
+		proc.loaded[d.Member] = nil
+		m.bundles[proc.client] = proc
+		m.mu.Unlock()
+	}
+	return proc, nil
+}
+
+// checkMember checks that d names one of the plugins of a bundle serving
+// names, or a process serving a single plugin when names is nil
+func checkMember(d discovery.DiscoveredPlugin, names []string) error {
+	name := processName(d)
+	switch {
+	case names == nil && d.Member != "":
+		return fmt.Errorf("plugin %s serves a single plugin, load it as %s", name, name)
+	case names != nil && d.Member == "":
+		qualified := make([]string, len(names))
+		for i, member := range names {
+			qualified[i] = name + "." + member
+		}
+		err := fmt.Errorf("plugin %s serves several plugins, load one of %s", name, strings.Join(qualified, ", "))
+		if d.Kind == discovery.KindPlugin {
+			err = fmt.Errorf("%w, or list them in %s to load them all", err, filepath.Base(discovery.BundlePath(d.Path)))
+		}
+		return err
+	case names != nil && !slices.Contains(names, d.Member):
+		return fmt.Errorf("plugin %s does not serve %s, it serves %s", name, d.Member, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// release frees a bundle plugin's place in its process, killing the process
+// once none of its plugins is loaded. A single plugin's process is killed
+// right away.
+func (m *Manager) release(proc *process, member string) {
+	if proc.names == nil {
+		m.kill(proc)
+		return
+	}
+
+	m.mu.Lock()
+	delete(proc.loaded, member)
+	last := len(proc.loaded) == 0
+	if last {
+		delete(m.bundles, proc.client)
+	}
+	m.mu.Unlock()
+
+	if last {
+		m.kill(proc)
+	}
+}
+
+// stopMember releases the bundle plugin p, reporting whether client is a
+// bundle process p was loaded from
+func (m *Manager) stopMember(client *plugin.Client, p types.VersionedPlugin) bool {
+	m.mu.Lock()
+	proc, ok := m.bundles[client]
+	member := ""
+	if ok {
+		for name, loaded := range proc.loaded {
+			if loaded == p {
+				member = name
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	if ok {
+		m.release(proc, member)
+	}
+	return ok
+}
+
+func (m *Manager) kill(proc *process) {
+	_ = proc.root.Close()
+	proc.client.Kill()
+}
+
+// spawn starts the plugin process for d, or attaches to it when it is
+// serving in debug mode, and asks which plugins it serves
+func (m *Manager) spawn(d discovery.DiscoveredPlugin) (*process, error) {
+	name := processName(d)
+	clientConfig := &plugin.ClientConfig{
+		HandshakeConfig:  protocol.Handshake,
+		VersionedPlugins: protocol.VersionedPlugins,
+		Logger:           m.logger,
+		AllowedProtocols: []plugin.Protocol{
+			plugin.ProtocolGRPC,
+		},
+	}
+	if d.Kind == discovery.KindReattach {
+		reattach, err := m.reattachConfig(name)
+		if err != nil {
+			return nil, err
+		}
+		// go-plugin does not negotiate with a plugin it attaches to, so the
+		// version the plugin serves picks the plugin set
+		clientConfig.Reattach = reattach
+		clientConfig.Plugins = protocol.AttachedPlugins[reattach.ProtocolVersion]
+	} else {
+		clientConfig.Cmd = exec.Command(d.Path)
+	}
+	client := plugin.NewClient(clientConfig)
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("failed to create RPC client: %w", err)
+	}
+
+	raw, err := rpcClient.Dispense("plugin")
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("failed to dispense plugin: %w", err)
+	}
+	m.logger.Debug("negotiated plugin protocol", "name", name, "version", client.NegotiatedVersion())
+
+	root, ok := raw.(*protocol.GRPCClient)
+	if !ok {
+		client.Kill()
+		return nil, fmt.Errorf("plugin does not implement VersionedPlugin interface")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ListPluginsTimeout)
+	defer cancel()
+	names, err := root.Plugins(ctx)
+	if err == nil && names != nil {
+		err = protocol.ValidateBundle(names)
+	}
+	if err != nil {
+		_ = root.Close()
+		client.Kill()
+		return nil, fmt.Errorf("failed to list the plugins of %s: %w", name, err)
+	}
+
+	return &process{
+		key:    processKey(d),
+		client: client,
+		root:   root,
+		names:  names,
+		loaded: make(map[string]types.VersionedPlugin),
+	}, nil
+}
+
+// reattachConfig returns how to attach to a plugin serving in debug mode,
+// as listed in protocol.EnvReattach
+func (m *Manager) reattachConfig(name string) (*plugin.ReattachConfig, error) {
+	reattach, err := protocol.ReattachFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	debug, ok := reattach[name]
+	if !ok {
+		return nil, fmt.Errorf("plugin %s is not in %s", name, protocol.EnvReattach)
+	}
+	m.logger.Debug("attaching to plugin", "name", name, "pid", debug.Pid, "address", debug.Address)
+	return debug.ClientConfig()
+}
+
+// expand lists the plugins of a bundle serving in debug mode in place of
+// its process, which is kept for loading them. d is returned as is when it
+// serves a single plugin, or cannot be asked so that loading it reports why.
+// Binaries are never started to be asked: their manifest lists their plugins.
+func (m *Manager) expand(d discovery.DiscoveredPlugin) []discovery.DiscoveredPlugin {
+	if d.Kind != discovery.KindReattach {
+		return []discovery.DiscoveredPlugin{d}
+	}
+	key := processKey(d)
+
+	m.mu.Lock()
+	proc := m.idle[key]
+	for _, running := range m.bundles {
+		if proc == nil && running.key == key {
+			proc = running
+		}
+	}
+	m.mu.Unlock()
+
+	if proc == nil {
+		var err error
+		if proc, err = m.spawn(d); err != nil {
+			m.logger.Debug("failed to start plugin to list its plugins", "name", d.Name, "error", err)
+			return []discovery.DiscoveredPlugin{d}
+		}
+		m.mu.Lock()
+		m.idle[key] = proc
+		m.mu.Unlock()
+	}
+
+	if proc.names == nil {
+		return []discovery.DiscoveredPlugin{d}
+	}
+	return proc.members(d)
+}
@@ -0,0 +1,39 @@
+package plugintest
+
+import "github.com/williamokano/hashicorp-plugin-example/pkg/types"
+
+// Message returns a message event like the CLI's process command creates
+func Message(content string) types.Event {
+	return Event(types.EventMessage, content)
+}
+
+// Command returns a command event
+func Command(content string) types.Event {
+	return Event(types.EventCommand, content)
+}
+
+// Event returns an event of any type from the "test" source
+func Event(eventType types.EventType, content string) types.Event {
+	return types.Event{
+		Type:      eventType,
+		Source:    "test",
+		Content:   content,
+		UserID:    "test-user",
+		ChannelID: "test-channel",
+		Metadata:  make(map[string]interface{}),
+	}
+}
+
+// NewContext returns the context the pipeline would hand the first plugin,
+// with properties standing in for what earlier plugins wrote
+func NewContext(event types.Event, properties map[string]interface{}) *types.Context {
+	props := make(map[string]interface{}, len(properties))
+	for k, v := range properties {
+		props[k] = v
+	}
+	return &types.Context{
+		Event:      event,
+		Properties: props,
+		Responses:  []types.Response{},
+	}
+}
@@ -0,0 +1,134 @@
+package plugintest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// LogEntry is one Host.Log call
+type LogEntry struct {
+	Level   string
+	Message string
+	Fields  map[string]interface{}
+}
+
+// ProgressUpdate is one Host.Progress call
+type ProgressUpdate struct {
+	Percent float64
+	Message string
+}
+
+// Host is an in-memory types.Host that records what the plugin did. Seed
+// Store before running to simulate state from earlier events, and use
+// AddBlob to give events attachments.
+type Host struct {
+	mu sync.Mutex
+
+	Store   map[string]interface{}
+	Logs    []LogEntry
+	Emitted []types.Event
+	Updates []ProgressUpdate
+
+	blobs map[string]blob
+	next  int
+}
+
+type blob struct {
+	info types.Blob
+	data []byte
+}
+
+// NewHost returns an empty Host
+func NewHost() *Host {
+	return &Host{Store: make(map[string]interface{}), blobs: make(map[string]blob)}
+}
+
+var _ types.Host = (*Host)(nil)
+
+// Log records a log line
+func (h *Host) Log(level, message string, fields map[string]interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Logs = append(h.Logs, LogEntry{Level: level, Message: message, Fields: fields})
+}
+
+// Get reads from Store
+func (h *Host) Get(key string) (interface{}, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	value, ok := h.Store[key]
+	return value, ok, nil
+}
+
+// Set writes to Store
+func (h *Host) Set(key string, value interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Store[key] = value
+	return nil
+}
+
+// Delete removes from Store
+func (h *Host) Delete(key string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.Store, key)
+	return nil
+}
+
+// Emit records an event in Emitted
+func (h *Host) Emit(event types.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Emitted = append(h.Emitted, event)
+	return nil
+}
+
+// Progress records an update in Updates
+func (h *Host) Progress(percent float64, message string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Updates = append(h.Updates, ProgressUpdate{Percent: percent, Message: message})
+}
+
+// PutBlob keeps the blob in memory
+func (h *Host) PutBlob(name, contentType string, r io.Reader) (types.Blob, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return types.Blob{}, err
+	}
+	return h.AddBlob(name, contentType, data), nil
+}
+
+// OpenBlob reads a blob added by the plugin or with AddBlob
+func (h *Host) OpenBlob(handle string) (io.ReadCloser, types.Blob, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, ok := h.blobs[handle]
+	if !ok {
+		return nil, types.Blob{}, fmt.Errorf("%w: %q", types.ErrBlobNotFound, handle)
+	}
+	return io.NopCloser(bytes.NewReader(b.data)), b.info, nil
+}
+
+// AddBlob stores data as a blob, e.g. to attach to an event
+func (h *Host) AddBlob(name, contentType string, data []byte) types.Blob {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.next++
+	info := types.Blob{Handle: fmt.Sprintf("blob-%d", h.next), Name: name, ContentType: contentType, Size: int64(len(data))}
+	h.blobs[info.Handle] = blob{info: info, data: data}
+	return info
+}
+
+// Blob returns the contents of the blob with the handle and whether it exists
+func (h *Host) Blob(handle string) ([]byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, ok := h.blobs[handle]
+	return b.data, ok
+}
@@ -0,0 +1,249 @@
+// Package plugintest runs a plugin through the same gRPC adapters the CLI
+// uses, in memory, so plugin authors can test against the real wire
+// behavior (integers arriving as int64, control signals, host services):
+//
+//	func TestConvert(t *testing.T) {
+//		h := plugintest.New(t, &ConverterPlugin{})
+//
+//		h.Run(plugintest.Message("convert this video"), map[string]interface{}{"action": "convert", "media_type": "video"}).
+//			AssertExecuted().
+//			AssertProperty("conversion_complete", true).
+//			AssertResponse("conversion", "converted successfully")
+//	}
+package plugintest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// DefaultTimeout bounds each ShouldExecute and Process call
+const DefaultTimeout = 10 * time.Second
+
+// Harness serves one plugin for the duration of a test
+type Harness struct {
+	// Plugin is the CLI's view of the plugin; every call crosses the gRPC boundary
+	Plugin types.VersionedPlugin
+
+	// Host records what the plugin did through its host services
+	Host *Host
+
+	t       testing.TB
+	timeout time.Duration
+}
+
+type options struct {
+	version int
+	timeout time.Duration
+	config  map[string]interface{}
+}
+
+// Option configures a Harness
+type Option func(*options)
+
+// WithProtocolVersion serves the plugin over a specific protocol version
+// instead of the latest one
+func WithProtocolVersion(version int) Option {
+	return func(o *options) { o.version = version }
+}
+
+// WithConfig configures the plugin with values, as if they were its config
+// block in the project config. Without it the plugin gets an empty config.
+func WithConfig(values map[string]interface{}) Option {
+	return func(o *options) { o.config = values }
+}
+
+// WithTimeout changes the deadline of each call from DefaultTimeout
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.timeout = timeout }
+}
+
+// New serves impl, connects to it, and configures and initializes it like the
+// CLI would. The test fails if the plugin rejects its config or fails to
+// initialize. The plugin is shut down and the connection closed when the test
+// ends.
+func New(t testing.TB, impl types.VersionedPlugin, opts ...Option) *Harness {
+	t.Helper()
+
+	o := options{version: protocol.LatestProtocolVersion, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	set, ok := protocol.PluginSets(impl)[o.version]
+	if !ok {
+		t.Fatalf("plugintest: unsupported protocol version %d", o.version)
+	}
+
+	client, _ := plugin.TestPluginGRPCConn(t, false, set)
+	t.Cleanup(func() { _ = client.Close() })
+
+	raw, err := client.Dispense("plugin")
+	if err != nil {
+		t.Fatalf("plugintest: failed to dispense plugin: %v", err)
+	}
+
+	p, ok := raw.(types.VersionedPlugin)
+	if !ok {
+		t.Fatalf("plugintest: dispensed %T, which is not a VersionedPlugin", raw)
+	}
+
+	h := &Harness{Plugin: p, Host: NewHost(), t: t, timeout: o.timeout}
+	if err := h.Configure(o.config); err != nil {
+		t.Fatalf("plugintest: %v", err)
+	}
+	if err := h.Init(); err != nil {
+		t.Fatalf("plugintest: failed to initialize plugin: %v", err)
+	}
+
+	// Cleanups run last-registered first, so this runs before the close
+	t.Cleanup(func() {
+		if err := h.Shutdown(); err != nil {
+			t.Errorf("plugintest: failed to shut down plugin: %v", err)
+		}
+	})
+	return h
+}
+
+// Configure sends the plugin a config as the CLI does after loading it.
+// Plugins that take no configuration accept an empty one.
+func (h *Harness) Configure(values map[string]interface{}) error {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	configurable, ok := h.Plugin.(types.Configurable)
+	if !ok {
+		return nil
+	}
+	err := configurable.Configure(ctx, values)
+	if errors.Is(err, protocol.ErrNotConfigurable) && len(values) == 0 {
+		return nil
+	}
+	return err
+}
+
+// Init asks the plugin to acquire its resources. New already calls it once.
+func (h *Harness) Init() error {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	if initializer, ok := h.Plugin.(types.Initializer); ok {
+		return initializer.Init(ctx)
+	}
+	return nil
+}
+
+// Health asks the plugin for its health
+func (h *Harness) Health() types.Health {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	if checker, ok := h.Plugin.(types.HealthChecker); ok {
+		return checker.Health(ctx)
+	}
+	return types.Health{Status: types.HealthUnknown}
+}
+
+// Shutdown asks the plugin to release its resources. It is called when the
+// test ends; calling it earlier lets a test check what the plugin cleaned up.
+func (h *Harness) Shutdown() error {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	if shutdowner, ok := h.Plugin.(types.Shutdowner); ok {
+		return shutdowner.Shutdown(ctx)
+	}
+	return nil
+}
+
+// ShouldExecute asks the plugin whether it wants the context
+func (h *Harness) ShouldExecute(c *types.Context) types.ExecutionDecision {
+	ctx, cancel := h.context()
+	defer cancel()
+	return h.Plugin.ShouldExecute(ctx, c.Clone())
+}
+
+// Process runs the plugin on the context regardless of ShouldExecute
+func (h *Harness) Process(c *types.Context) (*types.Context, error) {
+	ctx, cancel := h.context()
+	defer cancel()
+	return h.Plugin.Process(ctx, c.Clone())
+}
+
+// Run builds a context from the event and initial properties and runs it
+// the way the pipeline does: ShouldExecute first, then Process if the plugin
+// accepted it
+func (h *Harness) Run(event types.Event, properties map[string]interface{}) *Result {
+	h.t.Helper()
+	return h.RunContext(NewContext(event, properties))
+}
+
+// RunContext runs an existing context the way the pipeline does
+func (h *Harness) RunContext(c *types.Context) *Result {
+	h.t.Helper()
+
+	result := &Result{t: h.t, Input: c, Context: c}
+	result.Decision = h.ShouldExecute(c)
+	if !result.Decision.ShouldExecute {
+		return result
+	}
+
+	result.Executed = true
+	output, err := h.Process(c)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Context = output
+	return result
+}
+
+// RunCommand runs one of the plugin's commands and returns what it wrote.
+// Flags the command declares but flags leaves out get their defaults, as on
+// the command line.
+func (h *Harness) RunCommand(name string, args []string, flags map[string]interface{}) (string, error) {
+	ctx, cancel := h.context()
+	defer cancel()
+
+	provider, ok := h.Plugin.(types.CommandProvider)
+	if !ok {
+		return "", protocol.ErrNoCommands
+	}
+	commands, err := provider.Commands(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	values := make(map[string]interface{}, len(flags))
+	for _, command := range commands {
+		if command.Name != name {
+			continue
+		}
+		for _, flag := range command.Flags {
+			value, err := flag.Parse(flag.Default)
+			if err != nil {
+				return "", fmt.Errorf("command %s: %w", name, err)
+			}
+			values[flag.Name] = value
+		}
+	}
+	for key, value := range flags {
+		values[key] = value
+	}
+
+	var out strings.Builder
+	err = provider.RunCommand(ctx, types.CommandRequest{Name: name, Args: args, Flags: values}, &out)
+	return out.String(), err
+}
+
+func (h *Harness) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(types.WithHost(context.Background(), h.Host), h.timeout)
+}
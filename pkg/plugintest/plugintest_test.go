@@ -0,0 +1,234 @@
+package plugintest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/sdk"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// counterPlugin counts "count" messages in the host store and stops on "stop"
+type counterPlugin struct {
+	sdk.Base
+}
+
+func (p *counterPlugin) ShouldExecute(ctx context.Context, c *types.Context) types.ExecutionDecision {
+	switch c.Event.Content {
+	case "count", "stop", "fail":
+		return sdk.Execute("known command")
+	}
+	return sdk.Skip("unknown command")
+}
+
+func (p *counterPlugin) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	host, _ := types.HostFrom(ctx)
+
+	switch c.Event.Content {
+	case "fail":
+		return nil, errors.New("counter broke")
+	case "stop":
+		c.Stop("stopped by counter")
+		return c, nil
+	}
+
+	count := int64(0)
+	if value, ok, err := host.Get("count"); err == nil && ok {
+		count, _ = sdk.Int(&types.Context{Properties: map[string]interface{}{"n": value}}, "n")
+	}
+	count++
+	if err := host.Set("count", count); err != nil {
+		return nil, err
+	}
+	host.Log(types.LogInfo, "counted", map[string]interface{}{"count": count})
+	host.Progress(100, "done")
+
+	sdk.Set(c, "count", count)
+	p.Respond(c, "count", "counted", nil)
+	return c, nil
+}
+
+func newCounter() *counterPlugin {
+	return &counterPlugin{Base: sdk.Base{Info: sdk.Info{Name: "counter", Version: "1.2.3"}}}
+}
+
+func TestHarness_Run(t *testing.T) {
+	h := New(t, newCounter())
+
+	assert.Equal(t, "counter", h.Plugin.Name())
+	assert.Equal(t, "1.2.3", h.Plugin.Version())
+
+	h.Run(Message("count"), nil).
+		AssertExecuted().
+		AssertProperty("count", 1).
+		AssertResponse("count", "counted")
+
+	h.Run(Message("count"), map[string]interface{}{"seed": "x"}).
+		AssertExecuted().
+		AssertProperty("count", 2).
+		AssertProperty("seed", "x")
+
+	h.Run(Message("hello"), nil).
+		AssertSkipped("unknown").
+		AssertNoProperty("count").
+		AssertNoResponse()
+
+	h.Run(Message("stop"), nil).
+		AssertExecuted().
+		AssertControl(types.SignalStop, "counter")
+
+	h.Run(Message("fail"), nil).
+		AssertError("counter broke").
+		AssertErrorCode(types.ErrorCodeInternal)
+}
+
+func TestHarness_Host(t *testing.T) {
+	h := New(t, newCounter())
+	h.Host.Store["count"] = int64(41)
+
+	h.Run(Message("count"), nil).AssertProperty("count", 42)
+
+	assert.EqualValues(t, 42, h.Host.Store["count"])
+	require.Len(t, h.Host.Logs, 1)
+	assert.Equal(t, LogEntry{Level: types.LogInfo, Message: "counted", Fields: map[string]interface{}{"count": int64(42)}}, h.Host.Logs[0])
+	assert.Equal(t, []ProgressUpdate{{Percent: 100, Message: "done"}}, h.Host.Updates)
+}
+
+// greeterPlugin answers with a configured greeting
+type greeterPlugin struct {
+	counterPlugin
+	greeting string
+}
+
+func (p *greeterPlugin) Configure(ctx context.Context, values map[string]interface{}) error {
+	greeting, err := sdk.Config(values).String("greeting", "hello")
+	if err != nil {
+		return err
+	}
+	p.greeting = greeting
+	return nil
+}
+
+func (p *greeterPlugin) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	p.Respond(c, "text", p.greeting, nil)
+	return c, nil
+}
+
+func TestHarness_Config(t *testing.T) {
+	h := New(t, &greeterPlugin{counterPlugin: *newCounter()})
+	h.Run(Message("count"), nil).AssertResponse("text", "hello")
+
+	h = New(t, &greeterPlugin{counterPlugin: *newCounter()}, WithConfig(map[string]interface{}{"greeting": "hi"}))
+	h.Run(Message("count"), nil).AssertResponse("text", "hi")
+
+	assert.Error(t, h.Configure(map[string]interface{}{"greeting": 3}))
+	assert.ErrorIs(t, New(t, newCounter()).Configure(map[string]interface{}{"greeting": "hi"}), protocol.ErrNotConfigurable)
+}
+
+func (p *greeterPlugin) Commands(ctx context.Context) ([]types.Command, error) {
+	return []types.Command{{
+		Name:  "greet",
+		Flags: []types.CommandFlag{{Name: "name", Default: "world"}, {Name: "shout", Type: types.FlagBool}},
+	}}, nil
+}
+
+func (p *greeterPlugin) RunCommand(ctx context.Context, req types.CommandRequest, out io.Writer) error {
+	greeting := fmt.Sprintf("%s, %s", p.greeting, req.Flags["name"])
+	if req.Flags["shout"] == true {
+		greeting = strings.ToUpper(greeting)
+	}
+	_, err := fmt.Fprintln(out, greeting)
+	return err
+}
+
+func TestHarness_RunCommand(t *testing.T) {
+	h := New(t, &greeterPlugin{counterPlugin: *newCounter()}, WithConfig(map[string]interface{}{"greeting": "hi"}))
+
+	out, err := h.RunCommand("greet", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hi, world\n", out)
+
+	out, err = h.RunCommand("greet", nil, map[string]interface{}{"name": "gopher", "shout": true})
+	require.NoError(t, err)
+	assert.Equal(t, "HI, GOPHER\n", out)
+
+	_, err = h.RunCommand("wave", nil, nil)
+	assert.ErrorContains(t, err, `unknown command "wave"`)
+
+	_, err = New(t, newCounter()).RunCommand("greet", nil, nil)
+	assert.ErrorIs(t, err, protocol.ErrNoCommands)
+}
+
+func TestHarness_ProtocolVersions(t *testing.T) {
+	for _, version := range []int{protocol.ProtocolVersion1, protocol.ProtocolVersion2} {
+		h := New(t, newCounter(), WithProtocolVersion(version))
+
+		versioned, ok := h.Plugin.(protocol.ProtocolVersioned)
+		require.True(t, ok)
+		assert.Equal(t, version, versioned.ProtocolVersion())
+
+		h.Run(Message("count"), nil).AssertExecuted().AssertProperty("count", 1)
+	}
+}
+
+// failureRecorder swallows assertion failures so tests can check they happen
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *failureRecorder) Errorf(string, ...interface{}) { r.failed = true }
+
+func (r *failureRecorder) Helper() {}
+
+func TestResult_ReportsFailures(t *testing.T) {
+	h := New(t, newCounter())
+
+	tests := []struct {
+		name   string
+		event  string
+		assert func(*Result)
+	}{
+		{"executed but skipped", "hello", func(r *Result) { r.AssertExecuted() }},
+		{"skipped but executed", "count", func(r *Result) { r.AssertSkipped("") }},
+		{"wrong property", "count", func(r *Result) { r.AssertProperty("count", 7) }},
+		{"missing response", "count", func(r *Result) { r.AssertResponse("count", "nope") }},
+		{"missing control", "count", func(r *Result) { r.AssertControl(types.SignalStop, "") }},
+		{"no error", "count", func(r *Result) { r.AssertError("broke") }},
+		{"wrong error code", "fail", func(r *Result) { r.AssertErrorCode(types.ErrorCodeRejected) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := h.Run(Message(tt.event), nil)
+			recorder := &failureRecorder{TB: t}
+			r.t = recorder
+			tt.assert(r)
+			assert.True(t, recorder.failed)
+		})
+	}
+}
+
+func TestResult_DroppedResponses(t *testing.T) {
+	given := &types.Context{Responses: []types.Response{{Type: "text", Content: "earlier"}}}
+	returned := &types.Context{}
+
+	for name, check := range map[string]func(*Result){
+		"AssertResponse":   func(r *Result) { r.AssertResponse("text", "earlier") },
+		"AssertNoResponse": func(r *Result) { r.AssertNoResponse() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder := &failureRecorder{TB: t}
+			r := &Result{Input: given, Executed: true, Context: returned, t: recorder}
+			assert.NotPanics(t, func() { check(r) })
+			assert.True(t, recorder.failed)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package plugintest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Result is what happened when a context was run through the plugin. The
+// Assert methods report failures on the test and return the result so they
+// can be chained.
+type Result struct {
+	// Input is the context the plugin was given
+	Input *types.Context
+
+	// Decision is what ShouldExecute returned
+	Decision types.ExecutionDecision
+
+	// Executed is true when Process was called
+	Executed bool
+
+	// Context is what Process returned, or Input when it was not called or failed
+	Context *types.Context
+
+	// Err is the error Process returned
+	Err error
+
+	t testing.TB
+}
+
+// AssertExecuted checks that the plugin accepted the event and Process succeeded
+func (r *Result) AssertExecuted() *Result {
+	r.t.Helper()
+	if assert.True(r.t, r.Executed, "plugin skipped the event: %s", r.Decision.Reason) {
+		assert.NoError(r.t, r.Err, "Process failed")
+	}
+	return r
+}
+
+// AssertSkipped checks that ShouldExecute declined the event. If reason is
+// not empty the decision's reason must contain it.
+func (r *Result) AssertSkipped(reason string) *Result {
+	r.t.Helper()
+	if assert.False(r.t, r.Executed, "plugin executed, expected it to skip") && reason != "" {
+		assert.Contains(r.t, r.Decision.Reason, reason)
+	}
+	return r
+}
+
+// AssertError checks that Process failed with an error containing message
+func (r *Result) AssertError(message string) *Result {
+	r.t.Helper()
+	if assert.Error(r.t, r.Err, "Process succeeded, expected an error") {
+		assert.Contains(r.t, r.Err.Error(), message)
+	}
+	return r
+}
+
+// AssertErrorCode checks that Process failed with a *types.PluginError of
+// the given code, as the pipeline would classify it
+func (r *Result) AssertErrorCode(code types.ErrorCode) *Result {
+	r.t.Helper()
+	if assert.Error(r.t, r.Err, "Process succeeded, expected an error") {
+		assert.Equal(r.t, code, types.AsPluginError(r.Err).Code)
+	}
+	return r
+}
+
+// AssertProperty checks a Properties value as it arrived over the wire.
+// Numbers compare by value, so 3 matches the int64 3 a plugin sends back.
+func (r *Result) AssertProperty(key string, want interface{}) *Result {
+	r.t.Helper()
+	got, ok := r.Context.Properties[key]
+	if assert.True(r.t, ok, "property %q is not set", key) {
+		assert.EqualValues(r.t, want, got, "property %q", key)
+	}
+	return r
+}
+
+// AssertNoProperty checks that key is not set
+func (r *Result) AssertNoProperty(key string) *Result {
+	r.t.Helper()
+	assert.NotContains(r.t, r.Context.Properties, key)
+	return r
+}
+
+// AssertResponse checks that the plugin added a response of the given type
+// whose content contains the given text
+func (r *Result) AssertResponse(responseType, content string) *Result {
+	r.t.Helper()
+	added, ok := r.addedResponses()
+	if !ok {
+		return r
+	}
+	for _, resp := range added {
+		if resp.Type == responseType && strings.Contains(resp.Content, content) {
+			return r
+		}
+	}
+	assert.Fail(r.t, "response not found", "no %q response containing %q in %+v", responseType, content, r.Context.Responses)
+	return r
+}
+
+// AssertNoResponse checks that the plugin added no responses
+func (r *Result) AssertNoResponse() *Result {
+	r.t.Helper()
+	if added, ok := r.addedResponses(); ok {
+		assert.Empty(r.t, added)
+	}
+	return r
+}
+
+// addedResponses returns the responses the plugin appended to the ones it
+// was given, failing the test when it dropped some of them instead
+func (r *Result) addedResponses() ([]types.Response, bool) {
+	r.t.Helper()
+	given := len(r.Input.Responses)
+	if len(r.Context.Responses) < given {
+		assert.Fail(r.t, "responses dropped", "plugin was given %d responses and returned %d; plugins must keep the responses of earlier plugins",
+			given, len(r.Context.Responses))
+		return nil, false
+	}
+	return r.Context.Responses[given:], true
+}
+
+// AssertControl checks that the plugin sent a control signal. If reason is
+// not empty the signal's reason must contain it.
+func (r *Result) AssertControl(signal types.Signal, reason string) *Result {
+	r.t.Helper()
+	control := r.Context.Control
+	if assert.NotNil(r.t, control, "no control signal was sent") {
+		assert.Equal(r.t, signal, control.Signal)
+		if reason != "" {
+			assert.Contains(r.t, control.Reason, reason)
+		}
+	}
+	return r
+}
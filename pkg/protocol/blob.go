@@ -0,0 +1,171 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BlobChunkSize is the most blob data sent in one message, well under gRPC's
+// default 4MB message limit
+const BlobChunkSize = 64 * 1024
+
+func (s *hostServer) PutBlob(stream grpc.ClientStreamingServer[BlobChunk, Blob]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	host, err := s.host(first.GetCallId())
+	if err != nil {
+		return err
+	}
+
+	info := first.GetInfo()
+	blob, err := host.PutBlob(info.GetName(), info.GetContentType(), &chunkReader{pending: first.GetData(), recv: stream.Recv})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(blobToProto(blob))
+}
+
+func (s *hostServer) OpenBlob(req *OpenBlobRequest, stream grpc.ServerStreamingServer[BlobChunk]) error {
+	host, err := s.host(req.GetCallId())
+	if err != nil {
+		return err
+	}
+
+	r, blob, err := host.OpenBlob(req.GetHandle())
+	if errors.Is(err, types.ErrBlobNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	// The first chunk carries the blob's info even when it is empty
+	chunk := &BlobChunk{Info: blobToProto(blob)}
+	buf := make([]byte, BlobChunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 || chunk.Info != nil {
+			// Send may queue the message past returning, and buf is read
+			// into again, so each chunk is copied
+			chunk.Data = bytes.Clone(buf[:n])
+			if sendErr := stream.Send(chunk); sendErr != nil {
+				return sendErr
+			}
+			chunk = &BlobChunk{}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read blob %s: %w", blob.Name, err)
+		}
+	}
+}
+
+func (h *hostClient) PutBlob(name, contentType string, r io.Reader) (types.Blob, error) {
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+
+	stream, err := h.client.PutBlob(ctx)
+	if err != nil {
+		return types.Blob{}, blobError(err)
+	}
+
+	chunk := &BlobChunk{CallId: h.callID, Info: &Blob{Name: name, ContentType: contentType}}
+	buf := make([]byte, BlobChunkSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 || chunk.Info != nil {
+			// Send may queue the message past returning, and buf is read
+			// into again, so each chunk is copied
+			chunk.Data = bytes.Clone(buf[:n])
+			if err := stream.Send(chunk); err != nil {
+				// The server's reason for ending the stream comes from CloseAndRecv
+				_, err = stream.CloseAndRecv()
+				return types.Blob{}, blobError(err)
+			}
+			chunk = &BlobChunk{}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return types.Blob{}, fmt.Errorf("failed to read blob %s: %w", name, readErr)
+		}
+	}
+
+	blob, err := stream.CloseAndRecv()
+	if err != nil {
+		return types.Blob{}, blobError(err)
+	}
+	return protoToBlob(blob), nil
+}
+
+func (h *hostClient) OpenBlob(handle string) (io.ReadCloser, types.Blob, error) {
+	ctx, cancel := context.WithCancel(h.ctx)
+
+	stream, err := h.client.OpenBlob(ctx, &OpenBlobRequest{CallId: h.callID, Handle: handle})
+	if err != nil {
+		cancel()
+		return nil, types.Blob{}, blobError(err)
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		cancel()
+		return nil, types.Blob{}, blobError(err)
+	}
+	return &chunkReader{pending: first.GetData(), recv: stream.Recv, cancel: cancel}, protoToBlob(first.GetInfo()), nil
+}
+
+// blobError restores the errors a plugin can act on from a blob RPC's status
+func blobError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%w: %s", types.ErrBlobNotFound, status.Convert(err).Message())
+	case codes.Unimplemented:
+		// The host predates blobs
+		return types.ErrNoHost
+	default:
+		return err
+	}
+}
+
+// chunkReader reads the data of a stream of BlobChunks
+type chunkReader struct {
+	pending []byte
+	recv    func() (*BlobChunk, error)
+	cancel  context.CancelFunc
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		chunk, err := r.recv()
+		if err != nil {
+			return 0, err
+		}
+		r.pending = chunk.GetData()
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Close stops the stream; reading to the end is not required
+func (r *chunkReader) Close() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return nil
+}
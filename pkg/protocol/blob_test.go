@@ -0,0 +1,104 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/blobstore"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blobCopier streams every attachment back to the host as a new blob
+type blobCopier struct{ countingPlugin }
+
+func (blobCopier) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	host, _ := types.HostFrom(ctx)
+
+	var copies []interface{}
+	for _, attachment := range c.Event.Attachments {
+		r, info, err := host.OpenBlob(attachment.Handle)
+		if err != nil {
+			return nil, err
+		}
+		copied, err := host.PutBlob("copy-"+info.Name, info.ContentType, r)
+		_ = r.Close()
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, copied.Handle)
+	}
+	c.Properties["copies"] = copies
+
+	_, _, err := host.OpenBlob("blob-missing")
+	c.Properties["missing"] = errors.Is(err, types.ErrBlobNotFound)
+	return c, nil
+}
+
+func TestBlobs_OverBroker(t *testing.T) {
+	// Spans several chunks and does not end on a chunk boundary
+	large := make([]byte, 3*BlobChunkSize+7)
+	rand.New(rand.NewSource(1)).Read(large)
+
+	attachments := map[string][]byte{
+		"large.bin": large,
+		"empty.txt": {},
+	}
+
+	for version, set := range PluginSets(blobCopier{}) {
+		client, _ := plugin.TestPluginGRPCConn(t, false, set)
+		defer func() { _ = client.Close() }()
+
+		raw, err := client.Dispense("plugin")
+		require.NoError(t, err)
+		p := raw.(types.VersionedPlugin)
+
+		store := blobstore.New()
+		defer func() { _ = store.Close() }()
+		host := &recordingHost{blobs: store}
+
+		event := types.Event{Type: types.EventMessage}
+		for name, data := range attachments {
+			blob, err := store.Put(name, "", bytes.NewReader(data))
+			require.NoError(t, err)
+			event.Attachments = append(event.Attachments, blob)
+		}
+
+		result, err := p.Process(types.WithHost(context.Background(), host), &types.Context{Event: event, Properties: map[string]interface{}{}})
+		require.NoError(t, err, "version %d", version)
+		assert.Equal(t, true, result.Properties["missing"])
+
+		copies := result.Properties["copies"].([]interface{})
+		require.Len(t, copies, len(event.Attachments))
+		for i, handle := range copies {
+			original := event.Attachments[i]
+
+			r, copied, err := store.Open(handle.(string))
+			require.NoError(t, err)
+			data, err := io.ReadAll(r)
+			_ = r.Close()
+			require.NoError(t, err)
+
+			assert.Equal(t, "copy-"+original.Name, copied.Name)
+			assert.Equal(t, original.ContentType, copied.ContentType)
+			assert.Equal(t, original.Size, copied.Size)
+			assert.Equal(t, attachments[original.Name], data)
+		}
+	}
+}
+
+func TestBlobError(t *testing.T) {
+	assert.ErrorIs(t, blobError(status.Error(codes.NotFound, "blob not found: \"x\"")), types.ErrBlobNotFound)
+	assert.Equal(t, types.ErrNoHost, blobError(status.Error(codes.Unimplemented, "method PutBlob not implemented")))
+
+	other := status.Error(codes.Internal, "disk full")
+	assert.Equal(t, other, blobError(other))
+}
@@ -0,0 +1,190 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// PluginNameKey is the request metadata naming which of the plugins served
+// by one process a call is for
+const PluginNameKey = "plugin-name"
+
+// ValidateBundle checks the names a process serves its plugins under. The
+// CLI knows each plugin by the binary's name, a dot and its name in the
+// bundle, e.g. media.thumbnail.
+func ValidateBundle(names []string) error {
+	if len(names) == 0 {
+		return errors.New("a bundle needs at least one plugin")
+	}
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, "./\\ ") {
+			return fmt.Errorf("invalid plugin name %q: names must be non-empty without dots, slashes or spaces", name)
+		}
+	}
+	return nil
+}
+
+// bundleServer routes each call to the plugin named in its metadata
+type bundleServer struct {
+	UnimplementedPluginServer
+
+	plugins map[string]*GRPCServer
+	names   []string // sorted; calls naming no plugin go to the first
+}
+
+func newBundleServer(impls map[string]types.VersionedPlugin, version int, host *hostDialer) *bundleServer {
+	s := &bundleServer{plugins: make(map[string]*GRPCServer, len(impls))}
+	for name, impl := range impls {
+		s.plugins[name] = &GRPCServer{Impl: impl, version: version, host: host}
+	}
+	s.names = slices.Sorted(maps.Keys(s.plugins))
+	return s
+}
+
+func (s *bundleServer) plugin(ctx context.Context) (*GRPCServer, error) {
+	name := s.names[0]
+	if values := metadata.ValueFromIncomingContext(ctx, PluginNameKey); len(values) > 0 {
+		name = values[0]
+	}
+	server, ok := s.plugins[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no plugin named %q in this process, it serves %s", name, strings.Join(s.names, ", "))
+	}
+	return server, nil
+}
+
+func (s *bundleServer) ListPlugins(ctx context.Context, req *Empty) (*PluginList, error) {
+	return &PluginList{Names: s.names}, nil
+}
+
+func (s *bundleServer) ShouldExecute(ctx context.Context, req *ContextProto) (*ExecutionDecisionProto, error) {
+	server, err := s.plugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return server.ShouldExecute(ctx, req)
+}
+
+func (s *bundleServer) Process(ctx context.Context, req *ContextProto) (*ContextProto, error) {
+	server, err := s.plugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return server.Process(ctx, req)
+}
+
+func (s *bundleServer) GetMetadata(ctx context.Context, req *Empty) (*Metadata, error) {
+	server, err := s.plugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return server.GetMetadata(ctx, req)
+}
+
+func (s *bundleServer) Configure(ctx context.Context, req *ConfigureRequest) (*Empty, error) {
+	server, err := s.plugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return server.Configure(ctx, req)
+}
+
+func (s *bundleServer) Init(ctx context.Context, req *Empty) (*Empty, error) {
+	server, err := s.plugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return server.Init(ctx, req)
+}
+
+func (s *bundleServer) Health(ctx context.Context, req *Empty) (*HealthResponse, error) {
+	server, err := s.plugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return server.Health(ctx, req)
+}
+
+func (s *bundleServer) Shutdown(ctx context.Context, req *Empty) (*Empty, error) {
+	server, err := s.plugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return server.Shutdown(ctx, req)
+}
+
+func (s *bundleServer) ListCommands(ctx context.Context, req *Empty) (*CommandList, error) {
+	server, err := s.plugin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return server.ListCommands(ctx, req)
+}
+
+func (s *bundleServer) RunCommand(req *CommandRequest, stream grpc.ServerStreamingServer[CommandOutput]) error {
+	server, err := s.plugin(stream.Context())
+	if err != nil {
+		return err
+	}
+	return server.RunCommand(req, stream)
+}
+
+// ListPlugins reports a process serving a single plugin
+func (m *GRPCServer) ListPlugins(ctx context.Context, req *Empty) (*PluginList, error) {
+	return &PluginList{}, nil
+}
+
+// Plugins names the plugins served by the plugin's process when it serves
+// several, and returns nil when it serves just this one
+func (m *GRPCClient) Plugins(ctx context.Context) ([]string, error) {
+	list, err := m.client.ListPlugins(ctx, &Empty{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, statusToError(err)
+	}
+	return list.GetNames(), nil
+}
+
+// Plugin returns a client for one of the plugins listed by Plugins, sharing
+// this client's connection. It offers host services of its own and is
+// closed separately.
+func (m *GRPCClient) Plugin(ctx context.Context, name string) (*GRPCClient, error) {
+	if m.conn == nil {
+		return nil, fmt.Errorf("plugin %s cannot be reached through this client", name)
+	}
+	client, err := newGRPCClient(ctx, NewPluginClient(namedConn{ClientConnInterface: m.conn, name: name}), m.version)
+	if err != nil {
+		return nil, err
+	}
+	client.conn = m.conn
+	if m.host != nil {
+		client.host = &hostBroker{broker: m.host.broker, listen: m.host.listen}
+	}
+	return client, nil
+}
+
+// namedConn names the plugin every call made through it is for
+type namedConn struct {
+	grpc.ClientConnInterface
+	name string
+}
+
+func (c namedConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return c.ClientConnInterface.Invoke(metadata.AppendToOutgoingContext(ctx, PluginNameKey, c.name), method, args, reply, opts...)
+}
+
+func (c namedConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.ClientConnInterface.NewStream(metadata.AppendToOutgoingContext(ctx, PluginNameKey, c.name), desc, method, opts...)
+}
@@ -0,0 +1,109 @@
+package protocol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// describedPlugin is a countingPlugin with its own description
+type describedPlugin struct {
+	countingPlugin
+	description string
+}
+
+func (p describedPlugin) Description() string { return p.description }
+
+func TestBundle(t *testing.T) {
+	impls := map[string]types.VersionedPlugin{
+		"resize": describedPlugin{description: "resizes"},
+		"crop":   describedPlugin{description: "crops"},
+		"upload": hostUser{},
+	}
+
+	for version, set := range BundleSets(impls) {
+		root := dispense(t, set)
+
+		names, err := root.Plugins(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"crop", "resize", "upload"}, names, "version %d", version)
+
+		// Calls naming no plugin go to the first
+		assert.Equal(t, "crops", root.Description())
+
+		resize, err := root.Plugin(context.Background(), "resize")
+		require.NoError(t, err)
+		assert.Equal(t, "resizes", resize.Description())
+		assert.Equal(t, version, resize.ProtocolVersion())
+
+		result, err := resize.Process(context.Background(), &types.Context{Properties: map[string]interface{}{"count": int64(1)}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result.Properties["count"])
+
+		// Each plugin offers host services of its own
+		upload, err := root.Plugin(context.Background(), "upload")
+		require.NoError(t, err)
+		host := &recordingHost{store: map[string]interface{}{}}
+		result, err = upload.Process(types.WithHost(context.Background(), host), &types.Context{Properties: map[string]interface{}{}})
+		require.NoError(t, err)
+		assert.Equal(t, true, result.Properties["host"])
+		assert.Equal(t, []string{"info: uploading"}, host.logs)
+		require.NoError(t, upload.Close())
+
+		_, err = root.Plugin(context.Background(), "rotate")
+		assert.ErrorContains(t, err, `no plugin named "rotate" in this process, it serves crop, resize, upload`)
+	}
+}
+
+func TestBundle_SinglePlugin(t *testing.T) {
+	tests := []struct {
+		name string
+		set  plugin.PluginSet
+	}{
+		{
+			name: "plugin served alone",
+			set:  PluginSets(countingPlugin{})[LatestProtocolVersion],
+		},
+		{
+			name: "plugin built before bundles",
+			set:  plugin.PluginSet{"plugin": &legacyGRPCPlugin{GRPCPlugin{Impl: countingPlugin{}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := dispense(t, tt.set).Plugins(context.Background())
+			require.NoError(t, err)
+			assert.Nil(t, names)
+		})
+	}
+}
+
+func TestValidateBundle(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		wantErr string
+	}{
+		{name: "valid", names: []string{"resize", "crop-v2"}},
+		{name: "empty", wantErr: "a bundle needs at least one plugin"},
+		{name: "empty name", names: []string{""}, wantErr: `invalid plugin name ""`},
+		{name: "dot", names: []string{"image.resize"}, wantErr: `invalid plugin name "image.resize"`},
+		{name: "slash", names: []string{"../resize"}, wantErr: `invalid plugin name "../resize"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBundle(tt.names)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNoCommands is returned by RunCommand for plugins that contribute no
+// commands, including those that predate them
+var ErrNoCommands = errors.New("plugin has no commands")
+
+// ListCommands returns the plugin's commands; plugins that are not
+// types.CommandProvider have none
+func (m *GRPCServer) ListCommands(ctx context.Context, req *Empty) (*CommandList, error) {
+	provider, ok := m.Impl.(types.CommandProvider)
+	if !ok {
+		return &CommandList{}, nil
+	}
+	commands, err := provider.Commands(ctx)
+	if err != nil {
+		return nil, errorToStatus(err)
+	}
+	list := &CommandList{}
+	for _, command := range commands {
+		list.Commands = append(list.Commands, commandToProto(command))
+	}
+	return list, nil
+}
+
+// RunCommand runs one of the plugin's commands, sending what it writes as
+// it is written
+func (m *GRPCServer) RunCommand(req *CommandRequest, stream grpc.ServerStreamingServer[CommandOutput]) error {
+	provider, ok := m.Impl.(types.CommandProvider)
+	if !ok {
+		return status.Error(codes.Unimplemented, ErrNoCommands.Error())
+	}
+
+	ctx := stream.Context()
+	commands, err := provider.Commands(ctx)
+	if err != nil {
+		return errorToStatus(err)
+	}
+	known := false
+	for _, command := range commands {
+		known = known || command.Name == req.GetName()
+	}
+	if !known {
+		return errorToStatus(types.InvalidInput(fmt.Sprintf("unknown command %q", req.GetName())))
+	}
+
+	flags, err := FromValueMap(req.GetFlags())
+	if err != nil {
+		return errorToStatus(types.NewError(types.ErrorCodeInvalidInput, "failed to decode flags", err))
+	}
+	if flags == nil {
+		flags = make(map[string]interface{})
+	}
+
+	out := &outputWriter{stream: stream}
+	if err := provider.RunCommand(ctx, types.CommandRequest{Name: req.GetName(), Args: req.GetArgs(), Flags: flags}, out); err != nil {
+		return errorToStatus(err)
+	}
+	return nil
+}
+
+// Commands fetches the plugin's commands. Plugins that predate commands
+// have none.
+func (m *GRPCClient) Commands(ctx context.Context) ([]types.Command, error) {
+	list, err := m.client.ListCommands(ctx, &Empty{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, statusToError(err)
+	}
+	commands := make([]types.Command, 0, len(list.GetCommands()))
+	for _, command := range list.GetCommands() {
+		commands = append(commands, protoToCommand(command))
+	}
+	return commands, nil
+}
+
+// RunCommand runs a plugin command, copying its output to out as it
+// arrives. Errors are *types.PluginError, or ErrNoCommands.
+func (m *GRPCClient) RunCommand(ctx context.Context, req types.CommandRequest, out io.Writer) error {
+	flags, err := ToValueMap(req.Flags)
+	if err != nil {
+		return types.InvalidInput(fmt.Sprintf("flags cannot be sent: %v", err))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := m.client.RunCommand(ctx, &CommandRequest{Name: req.Name, Args: req.Args, Flags: flags})
+	if err != nil {
+		return commandError(err)
+	}
+	for {
+		output, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return commandError(err)
+		}
+		if _, err := out.Write(output.GetData()); err != nil {
+			return fmt.Errorf("failed to write command output: %w", err)
+		}
+	}
+}
+
+func commandError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return ErrNoCommands
+	}
+	return statusToError(err)
+}
+
+// outputWriter sends a command's output over its stream
+type outputWriter struct {
+	stream grpc.ServerStreamingServer[CommandOutput]
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	for sent := 0; sent < len(p); {
+		n := min(len(p)-sent, BlobChunkSize)
+		// Send may queue the message past Write returning, and the caller
+		// is free to reuse p by then, so each chunk is copied
+		if err := w.stream.Send(&CommandOutput{Data: bytes.Clone(p[sent : sent+n])}); err != nil {
+			return sent, err
+		}
+		sent += n
+	}
+	return len(p), nil
+}
+
+func commandToProto(command types.Command) *Command {
+	proto := &Command{
+		Name:  command.Name,
+		Usage: command.Usage,
+		Short: command.Short,
+		Long:  command.Long,
+	}
+	for _, flag := range command.Flags {
+		proto.Flags = append(proto.Flags, &CommandFlag{
+			Name:         flag.Name,
+			Shorthand:    flag.Shorthand,
+			Type:         string(flag.Type),
+			DefaultValue: flag.Default,
+			Usage:        flag.Usage,
+		})
+	}
+	return proto
+}
+
+func protoToCommand(proto *Command) types.Command {
+	command := types.Command{
+		Name:  proto.GetName(),
+		Usage: proto.GetUsage(),
+		Short: proto.GetShort(),
+		Long:  proto.GetLong(),
+	}
+	for _, flag := range proto.GetFlags() {
+		command.Flags = append(command.Flags, types.CommandFlag{
+			Name:      flag.GetName(),
+			Shorthand: flag.GetShorthand(),
+			Type:      types.FlagType(flag.GetType()),
+			Default:   flag.GetDefaultValue(),
+			Usage:     flag.GetUsage(),
+		})
+	}
+	return command
+}
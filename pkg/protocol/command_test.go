@@ -0,0 +1,124 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// commandPlugin contributes an echo command that writes its args and flags
+type commandPlugin struct {
+	countingPlugin
+}
+
+func (commandPlugin) Commands(ctx context.Context) ([]types.Command, error) {
+	return []types.Command{{
+		Name:  "echo",
+		Usage: "[words...]",
+		Short: "Echo the arguments",
+		Flags: []types.CommandFlag{
+			{Name: "times", Shorthand: "n", Type: types.FlagInt, Default: "1", Usage: "repeat count"},
+			{Name: "upper", Type: types.FlagBool},
+		},
+	}}, nil
+}
+
+func (commandPlugin) RunCommand(ctx context.Context, req types.CommandRequest, out io.Writer) error {
+	if len(req.Args) == 0 {
+		return types.InvalidInput("nothing to echo")
+	}
+	line := strings.Join(req.Args, " ")
+	if req.Flags["upper"] == true {
+		line = strings.ToUpper(line)
+	}
+	for range req.Flags["times"].(int64) {
+		_, _ = fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+func TestCommands(t *testing.T) {
+	p := dispense(t, PluginSets(commandPlugin{})[LatestProtocolVersion])
+
+	commands, err := p.Commands(context.Background())
+	require.NoError(t, err)
+	want, _ := commandPlugin{}.Commands(context.Background())
+	assert.Equal(t, want, commands)
+
+	tests := []struct {
+		name    string
+		req     types.CommandRequest
+		want    string
+		wantErr string
+	}{
+		{
+			name: "flags",
+			req:  types.CommandRequest{Name: "echo", Args: []string{"hi", "there"}, Flags: map[string]interface{}{"times": int64(2), "upper": true}},
+			want: "HI THERE\nHI THERE\n",
+		},
+		{
+			name: "output larger than a chunk",
+			req:  types.CommandRequest{Name: "echo", Args: []string{strings.Repeat("x", BlobChunkSize)}, Flags: map[string]interface{}{"times": int64(3)}},
+			want: strings.Repeat(strings.Repeat("x", BlobChunkSize)+"\n", 3),
+		},
+		{
+			name:    "command error",
+			req:     types.CommandRequest{Name: "echo", Flags: map[string]interface{}{"times": int64(1)}},
+			wantErr: "nothing to echo",
+		},
+		{
+			name:    "unknown command",
+			req:     types.CommandRequest{Name: "shout"},
+			wantErr: `unknown command "shout"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := p.RunCommand(context.Background(), tt.req, &out)
+			if tt.wantErr != "" {
+				assert.Equal(t, types.ErrorCodeInvalidInput, types.AsPluginError(err).Code)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestCommands_None(t *testing.T) {
+	tests := []struct {
+		name string
+		set  plugin.PluginSet
+	}{
+		{
+			name: "plugin without commands",
+			set:  PluginSets(countingPlugin{})[LatestProtocolVersion],
+		},
+		{
+			name: "plugin built before command RPCs",
+			set:  plugin.PluginSet{"plugin": &legacyGRPCPlugin{GRPCPlugin{Impl: commandPlugin{}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := dispense(t, tt.set)
+
+			commands, err := p.Commands(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, commands)
+			assert.ErrorIs(t, p.RunCommand(context.Background(), types.CommandRequest{Name: "echo"}, io.Discard), ErrNoCommands)
+		})
+	}
+}
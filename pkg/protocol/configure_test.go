@@ -0,0 +1,86 @@
+package protocol
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// configurablePlugin keeps the config it was given and rejects a "bad" key
+type configurablePlugin struct {
+	countingPlugin
+	got *map[string]interface{}
+}
+
+func (c configurablePlugin) Configure(ctx context.Context, config map[string]interface{}) error {
+	if _, ok := config["bad"]; ok {
+		return types.InvalidInput("bad is not a setting")
+	}
+	*c.got = config
+	return nil
+}
+
+func TestConfigure(t *testing.T) {
+	var got map[string]interface{}
+
+	tests := []struct {
+		name    string
+		impl    types.VersionedPlugin
+		config  map[string]interface{}
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name:   "values arrive typed",
+			impl:   configurablePlugin{got: &got},
+			config: map[string]interface{}{"bucket": "media", "keywords": []interface{}{"a", "b"}, "quality": int64(90)},
+			want:   map[string]interface{}{"bucket": "media", "keywords": []interface{}{"a", "b"}, "quality": int64(90)},
+		},
+		{
+			name:   "empty config is an empty map",
+			impl:   configurablePlugin{got: &got},
+			config: map[string]interface{}{},
+			want:   map[string]interface{}{},
+		},
+		{
+			name:    "plugin rejects its config",
+			impl:    configurablePlugin{got: &got},
+			config:  map[string]interface{}{"bad": true},
+			wantErr: &types.PluginError{Code: types.ErrorCodeInvalidInput, Message: "bad is not a setting"},
+		},
+		{
+			name:   "plugin without settings accepts an empty config",
+			impl:   countingPlugin{},
+			config: map[string]interface{}{},
+		},
+		{
+			name:    "plugin without settings refuses values",
+			impl:    countingPlugin{},
+			config:  map[string]interface{}{"bucket": "media"},
+			wantErr: ErrNotConfigurable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			client, _ := plugin.TestPluginGRPCConn(t, false, PluginSets(tt.impl)[LatestProtocolVersion])
+			defer func() { _ = client.Close() }()
+
+			raw, err := client.Dispense("plugin")
+			require.NoError(t, err)
+
+			err = raw.(types.Configurable).Configure(context.Background(), tt.config)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
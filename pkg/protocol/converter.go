@@ -2,70 +2,247 @@ package protocol
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
 
-// ContextToProto converts a Context to protobuf format
-func ContextToProto(ctx *types.Context) *ContextProto {
-	propsJSON, _ := json.Marshal(ctx.Properties)
-	metadataJSON, _ := json.Marshal(ctx.Event.Metadata)
+// ContextToProto converts a Context to protobuf format. Properties, metadata
+// and response data are written both as typed values and as legacy JSON so
+// that a peer of any protocol version can read them.
+func ContextToProto(ctx *types.Context) (*ContextProto, error) {
+	return contextToProto(ctx, formatFor(0))
+}
+
+// ContextToProtoVersion converts a Context to the wire format of a
+// negotiated protocol version, writing only the map encoding that version reads
+func ContextToProtoVersion(ctx *types.Context, version int) (*ContextProto, error) {
+	return contextToProto(ctx, formatFor(version))
+}
+
+// wireFormat selects which encodings of a map are written
+type wireFormat int
+
+const (
+	formatJSON wireFormat = 1 << iota
+	formatTyped
+)
+
+// formatFor returns the encodings a protocol version reads; an unknown
+// version gets both
+func formatFor(version int) wireFormat {
+	switch version {
+	case ProtocolVersion1:
+		return formatJSON
+	case ProtocolVersion2:
+		return formatTyped
+	default:
+		return formatJSON | formatTyped
+	}
+}
+
+func contextToProto(ctx *types.Context, format wireFormat) (*ContextProto, error) {
+	props, propsJSON, err := encodeMap(ctx.Properties, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode properties: %w", err)
+	}
+
+	event, err := eventToProto(ctx.Event, format)
+	if err != nil {
+		return nil, err
+	}
 
 	responses := make([]*ResponseProto, len(ctx.Responses))
 	for i, resp := range ctx.Responses {
-		dataJSON, _ := json.Marshal(resp.Data)
+		data, dataJSON, err := encodeMap(resp.Data, format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode data of response %d from %s: %w", i, resp.PluginName, err)
+		}
 		responses[i] = &ResponseProto{
 			PluginName: resp.PluginName,
 			Content:    resp.Content,
 			Type:       resp.Type,
-			DataJson:   string(dataJSON),
+			DataJson:   dataJSON,
+			Data:       data,
+		}
+	}
+
+	var control *ControlProto
+	if ctx.Control != nil {
+		control = &ControlProto{
+			Signal: string(ctx.Control.Signal),
+			Reason: ctx.Control.Reason,
+			Plugin: ctx.Control.Plugin,
 		}
 	}
 
 	return &ContextProto{
-		Event: &EventProto{
-			Type:         string(ctx.Event.Type),
-			Source:       ctx.Event.Source,
-			Content:      ctx.Event.Content,
-			UserId:       ctx.Event.UserID,
-			ChannelId:    ctx.Event.ChannelID,
-			MetadataJson: string(metadataJSON),
-		},
-		PropertiesJson: string(propsJSON),
+		Event:          event,
+		PropertiesJson: propsJSON,
+		Properties:     props,
 		Responses:      responses,
-	}
+		Control:        control,
+	}, nil
 }
 
-// ProtoToContext converts protobuf format to Context
-func ProtoToContext(proto *ContextProto) *types.Context {
-	var props map[string]interface{}
-	_ = json.Unmarshal([]byte(proto.PropertiesJson), &props)
+// ProtoToContext converts protobuf format to Context, reading the typed maps
+// when present and the legacy JSON fields otherwise
+func ProtoToContext(proto *ContextProto) (*types.Context, error) {
+	props, err := decodeMap(proto.GetProperties(), proto.GetPropertiesJson())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode properties: %w", err)
+	}
 
-	var metadata map[string]interface{}
-	_ = json.Unmarshal([]byte(proto.Event.MetadataJson), &metadata)
+	event, err := protoToEvent(proto.GetEvent())
+	if err != nil {
+		return nil, err
+	}
 
-	responses := make([]types.Response, len(proto.Responses))
-	for i, resp := range proto.Responses {
-		var data map[string]interface{}
-		_ = json.Unmarshal([]byte(resp.DataJson), &data)
+	responses := make([]types.Response, len(proto.GetResponses()))
+	for i, resp := range proto.GetResponses() {
+		data, err := decodeMap(resp.GetData(), resp.GetDataJson())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode data of response %d from %s: %w", i, resp.GetPluginName(), err)
+		}
 		responses[i] = types.Response{
-			PluginName: resp.PluginName,
-			Content:    resp.Content,
-			Type:       resp.Type,
+			PluginName: resp.GetPluginName(),
+			Content:    resp.GetContent(),
+			Type:       resp.GetType(),
 			Data:       data,
 		}
 	}
 
+	var control *types.Control
+	if proto.GetControl() != nil {
+		control = &types.Control{
+			Signal: types.Signal(proto.Control.Signal),
+			Reason: proto.Control.Reason,
+			Plugin: proto.Control.Plugin,
+		}
+	}
+
 	return &types.Context{
-		Event: types.Event{
-			Type:      types.EventType(proto.Event.Type),
-			Source:    proto.Event.Source,
-			Content:   proto.Event.Content,
-			UserID:    proto.Event.UserId,
-			ChannelID: proto.Event.ChannelId,
-			Metadata:  metadata,
-		},
+		Event:      event,
 		Properties: props,
 		Responses:  responses,
+		Control:    control,
+	}, nil
+}
+
+func eventToProto(event types.Event, format wireFormat) (*EventProto, error) {
+	metadata, metadataJSON, err := encodeMap(event.Metadata, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event metadata: %w", err)
+	}
+
+	return &EventProto{
+		Type:         string(event.Type),
+		Source:       event.Source,
+		Content:      event.Content,
+		UserId:       event.UserID,
+		ChannelId:    event.ChannelID,
+		MetadataJson: metadataJSON,
+		Metadata:     metadata,
+		Attachments:  blobsToProto(event.Attachments),
+	}, nil
+}
+
+func protoToEvent(event *EventProto) (types.Event, error) {
+	metadata, err := decodeMap(event.GetMetadata(), event.GetMetadataJson())
+	if err != nil {
+		return types.Event{}, fmt.Errorf("failed to decode event metadata: %w", err)
+	}
+
+	return types.Event{
+		Type:        types.EventType(event.GetType()),
+		Source:      event.GetSource(),
+		Content:     event.GetContent(),
+		UserID:      event.GetUserId(),
+		ChannelID:   event.GetChannelId(),
+		Metadata:    metadata,
+		Attachments: protoToBlobs(event.GetAttachments()),
+	}, nil
+}
+
+func blobsToProto(blobs []types.Blob) []*Blob {
+	if len(blobs) == 0 {
+		return nil
+	}
+	out := make([]*Blob, len(blobs))
+	for i, blob := range blobs {
+		out[i] = blobToProto(blob)
+	}
+	return out
+}
+
+func protoToBlobs(blobs []*Blob) []types.Blob {
+	if len(blobs) == 0 {
+		return nil
+	}
+	out := make([]types.Blob, len(blobs))
+	for i, blob := range blobs {
+		out[i] = protoToBlob(blob)
+	}
+	return out
+}
+
+func blobToProto(blob types.Blob) *Blob {
+	return &Blob{Handle: blob.Handle, Name: blob.Name, ContentType: blob.ContentType, Size: blob.Size}
+}
+
+func protoToBlob(blob *Blob) types.Blob {
+	return types.Blob{Handle: blob.GetHandle(), Name: blob.GetName(), ContentType: blob.GetContentType(), Size: blob.GetSize()}
+}
+
+// encodeMap returns the requested wire encodings of a map. A typed map
+// cannot tell an empty map from a nil one, so a typed-only empty map is
+// still marked with a legacy "{}" and decodes as empty rather than nil.
+func encodeMap(m map[string]interface{}, format wireFormat) (map[string]*Value, string, error) {
+	var typed map[string]*Value
+	if format&formatTyped != 0 {
+		var err error
+		if typed, err = ToValueMap(m); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if format&formatJSON == 0 {
+		if m != nil && len(m) == 0 {
+			return typed, "{}", nil
+		}
+		return typed, "", nil
+	}
+
+	legacy, err := json.Marshal(m)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return typed, string(legacy), nil
+}
+
+// decodeMap reads the typed encoding of a map, or the legacy JSON one when
+// the typed map is empty (as it is from older plugins). An empty JSON string
+// decodes to a nil map.
+func decodeMap(typed map[string]*Value, legacy string) (map[string]interface{}, error) {
+	if len(typed) > 0 {
+		return FromValueMap(typed)
+	}
+	if legacy == "" {
+		return nil, nil
+	}
+
+	decoded, err := decodeJSON([]byte(legacy))
+	if err != nil {
+		return nil, err
+	}
+
+	switch m := decoded.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return m, nil
+	default:
+		return nil, fmt.Errorf("expected a JSON object, got %T", decoded)
 	}
 }
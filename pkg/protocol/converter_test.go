@@ -130,7 +130,8 @@ func TestContextToProto(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			proto := ContextToProto(tt.context)
+			proto, err := ContextToProto(tt.context)
+			require.NoError(t, err)
 			tt.verify(t, proto)
 		})
 	}
@@ -170,7 +171,7 @@ func TestProtoToContext(t *testing.T) {
 				assert.Equal(t, "user123", ctx.Event.UserID)
 				assert.Equal(t, "channel456", ctx.Event.ChannelID)
 				assert.Equal(t, "value1", ctx.Event.Metadata["key1"])
-				assert.Equal(t, float64(42), ctx.Event.Metadata["key2"]) // JSON numbers decode as float64
+				assert.Equal(t, int64(42), ctx.Event.Metadata["key2"]) // legacy JSON integers keep integer type
 				assert.Equal(t, "value1", ctx.Properties["prop1"])
 				assert.Len(t, ctx.Responses, 1)
 				assert.Equal(t, "plugin1", ctx.Responses[0].PluginName)
@@ -198,21 +199,18 @@ func TestProtoToContext(t *testing.T) {
 			},
 		},
 		{
-			name: "proto with invalid JSON",
+			name: "proto with unset JSON fields",
 			proto: &ContextProto{
 				Event: &EventProto{
-					Type:         "webhook",
-					Source:       "api",
-					MetadataJson: "invalid json",
+					Type:   "webhook",
+					Source: "api",
 				},
-				PropertiesJson: "also invalid",
-				Responses:      nil,
+				Responses: nil,
 			},
 			verify: func(t *testing.T, ctx *types.Context) {
-				// Should handle invalid JSON gracefully
 				assert.Equal(t, types.EventWebhook, ctx.Event.Type)
-				assert.Nil(t, ctx.Event.Metadata) // Failed to parse
-				assert.Nil(t, ctx.Properties)     // Failed to parse
+				assert.Nil(t, ctx.Event.Metadata)
+				assert.Nil(t, ctx.Properties)
 				assert.NotNil(t, ctx.Responses)
 				assert.Len(t, ctx.Responses, 0)
 			},
@@ -221,7 +219,8 @@ func TestProtoToContext(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ctx := ProtoToContext(tt.proto)
+			ctx, err := ProtoToContext(tt.proto)
+			require.NoError(t, err)
 			tt.verify(t, ctx)
 		})
 	}
@@ -238,7 +237,7 @@ func TestRoundTripConversion(t *testing.T) {
 			ChannelID: "channel",
 			Metadata: map[string]interface{}{
 				"string": "value",
-				"number": float64(42), // Use float64 as JSON decodes to this
+				"number": int64(42),
 				"bool":   true,
 				"array":  []interface{}{"a", "b", "c"},
 				"nested": map[string]interface{}{
@@ -263,8 +262,10 @@ func TestRoundTripConversion(t *testing.T) {
 	}
 
 	// Convert to proto and back
-	proto := ContextToProto(original)
-	result := ProtoToContext(proto)
+	proto, err := ContextToProto(original)
+	require.NoError(t, err)
+	result, err := ProtoToContext(proto)
+	require.NoError(t, err)
 
 	// Verify the result matches original
 	assert.Equal(t, original.Event.Type, result.Event.Type)
@@ -273,7 +274,7 @@ func TestRoundTripConversion(t *testing.T) {
 	assert.Equal(t, original.Event.UserID, result.Event.UserID)
 	assert.Equal(t, original.Event.ChannelID, result.Event.ChannelID)
 
-	// Check metadata (note: numbers will be float64 after JSON round-trip)
+	// Check metadata
 	assert.Equal(t, original.Event.Metadata["string"], result.Event.Metadata["string"])
 	assert.Equal(t, original.Event.Metadata["number"], result.Event.Metadata["number"])
 	assert.Equal(t, original.Event.Metadata["bool"], result.Event.Metadata["bool"])
@@ -288,3 +289,69 @@ func TestRoundTripConversion(t *testing.T) {
 	assert.Equal(t, original.Responses[0].Content, result.Responses[0].Content)
 	assert.Equal(t, original.Responses[0].Type, result.Responses[0].Type)
 }
+
+func TestControlConversion(t *testing.T) {
+	ctx := &types.Context{
+		Event:      types.Event{Type: types.EventMessage},
+		Properties: map[string]interface{}{},
+	}
+
+	// No control travels as an unset field
+	proto, err := ContextToProto(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, proto.Control)
+	result, err := ProtoToContext(proto)
+	require.NoError(t, err)
+	assert.Nil(t, result.Control)
+
+	ctx.Reject("spam detected")
+	proto, err = ContextToProto(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, proto.Control)
+	assert.Equal(t, "reject", proto.Control.Signal)
+
+	result, err = ProtoToContext(proto)
+	require.NoError(t, err)
+	require.NotNil(t, result.Control)
+	assert.Equal(t, types.Control{Signal: types.SignalReject, Reason: "spam detected"}, *result.Control)
+	assert.True(t, result.Halted())
+}
+
+func TestContextToProtoVersion(t *testing.T) {
+	ctx := &types.Context{
+		Event:      types.Event{Type: types.EventMessage, Metadata: map[string]interface{}{"k": "v"}},
+		Properties: map[string]interface{}{"count": 7},
+		Responses:  []types.Response{{PluginName: "p", Data: map[string]interface{}{}}},
+	}
+
+	t.Run("version 1 writes only JSON", func(t *testing.T) {
+		proto, err := ContextToProtoVersion(ctx, ProtocolVersion1)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"count": 7}`, proto.PropertiesJson)
+		assert.Empty(t, proto.Properties)
+		assert.Empty(t, proto.Event.Metadata)
+	})
+
+	t.Run("version 2 writes only typed values", func(t *testing.T) {
+		proto, err := ContextToProtoVersion(ctx, ProtocolVersion2)
+		require.NoError(t, err)
+		assert.Empty(t, proto.PropertiesJson)
+		assert.Empty(t, proto.Event.MetadataJson)
+		assert.Equal(t, int64(7), proto.Properties["count"].GetIntValue())
+
+		// An empty map stays empty rather than becoming nil
+		assert.Equal(t, "{}", proto.Responses[0].DataJson)
+		result, err := ProtoToContext(proto)
+		require.NoError(t, err)
+		assert.NotNil(t, result.Responses[0].Data)
+		assert.Empty(t, result.Responses[0].Data)
+		assert.Equal(t, int64(7), result.Properties["count"])
+	})
+
+	t.Run("unknown version writes both", func(t *testing.T) {
+		proto, err := ContextToProtoVersion(ctx, 0)
+		require.NoError(t, err)
+		assert.NotEmpty(t, proto.PropertiesJson)
+		assert.NotEmpty(t, proto.Properties)
+	})
+}
@@ -0,0 +1,76 @@
+package protocol
+
+import (
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcCodes maps error codes to the closest gRPC status code, so hosts that
+// ignore the ErrorDetail still see a sensible status
+var grpcCodes = map[types.ErrorCode]codes.Code{
+	types.ErrorCodeInternal:     codes.Internal,
+	types.ErrorCodeInvalidInput: codes.InvalidArgument,
+	types.ErrorCodeRejected:     codes.FailedPrecondition,
+	types.ErrorCodeUnavailable:  codes.Unavailable,
+	types.ErrorCodeTimeout:      codes.DeadlineExceeded,
+}
+
+// errorToStatus turns a plugin's error into a gRPC status error carrying an
+// ErrorDetail. Plain errors are reported as internal errors.
+func errorToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	pe := types.AsPluginError(err)
+	code, ok := grpcCodes[pe.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+
+	st := status.New(code, pe.Error())
+	detailed, detailErr := st.WithDetails(&ErrorDetail{
+		Code:      string(pe.Code),
+		Message:   pe.Message,
+		Retryable: pe.Retryable,
+		Detail:    pe.Detail,
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// statusToError turns the error of a plugin RPC back into a PluginError.
+// Plugins that predate ErrorDetail return bare statuses: a broken connection
+// or deadline is classified as a transport failure, anything else as an
+// internal error of the plugin.
+func statusToError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return types.NewError(types.ErrorCodeTransport, "plugin call failed", err)
+	}
+
+	for _, d := range st.Details() {
+		if detail, ok := d.(*ErrorDetail); ok {
+			return &types.PluginError{
+				Code:      types.ErrorCode(detail.Code),
+				Message:   detail.Message,
+				Retryable: detail.Retryable,
+				Detail:    detail.Detail,
+			}
+		}
+	}
+
+	switch st.Code() {
+	case codes.Unavailable, codes.Canceled, codes.DeadlineExceeded:
+		return types.NewError(types.ErrorCodeTransport, st.Message(), err)
+	default:
+		return types.NewError(types.ErrorCodeInternal, st.Message(), nil)
+	}
+}
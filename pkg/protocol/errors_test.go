@@ -0,0 +1,95 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failingPlugin returns err from every Process call
+type failingPlugin struct {
+	countingPlugin
+	err error
+}
+
+func (f failingPlugin) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	return nil, f.err
+}
+
+func TestPluginErrors_OverGRPC(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want *types.PluginError
+	}{
+		{
+			name: "plain errors are internal",
+			err:  errors.New("nil map write"),
+			want: &types.PluginError{Code: types.ErrorCodeInternal, Message: "nil map write"},
+		},
+		{
+			name: "rejection",
+			err:  types.Rejected("not my event"),
+			want: &types.PluginError{Code: types.ErrorCodeRejected, Message: "not my event"},
+		},
+		{
+			name: "retryable with detail",
+			err:  types.Unavailable("storage is down", errors.New("dial tcp: connection refused")),
+			want: &types.PluginError{Code: types.ErrorCodeUnavailable, Message: "storage is down", Retryable: true, Detail: "dial tcp: connection refused"},
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("upload: %w", types.InvalidInput("file_path is empty")),
+			want: &types.PluginError{Code: types.ErrorCodeInvalidInput, Message: "file_path is empty"},
+		},
+	}
+
+	for version := range VersionedPlugins {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				client, _ := plugin.TestPluginGRPCConn(t, false, PluginSets(failingPlugin{err: tt.err})[version])
+				defer func() { _ = client.Close() }()
+
+				raw, err := client.Dispense("plugin")
+				require.NoError(t, err)
+
+				_, err = raw.(types.VersionedPlugin).Process(context.Background(), &types.Context{Properties: map[string]interface{}{}})
+				var pe *types.PluginError
+				require.ErrorAs(t, err, &pe, "protocol v%d", version)
+				assert.Equal(t, tt.want, pe)
+			})
+		}
+	}
+}
+
+func TestStatusToError_BareStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantCode      types.ErrorCode
+		wantMessage   string
+		wantRetryable bool
+	}{
+		{"plugin that predates error details", status.Error(codes.Unknown, "boom"), types.ErrorCodeInternal, "boom", false},
+		{"broken connection", status.Error(codes.Unavailable, "connection closed"), types.ErrorCodeTransport, "connection closed", true},
+		{"not a status", errors.New("eof"), types.ErrorCodeTransport, "plugin call failed", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pe *types.PluginError
+			require.ErrorAs(t, statusToError(tt.err), &pe)
+			assert.Equal(t, tt.wantCode, pe.Code)
+			assert.Equal(t, tt.wantMessage, pe.Message)
+			assert.Equal(t, tt.wantRetryable, pe.Retryable)
+		})
+	}
+}
@@ -2,95 +2,217 @@ package protocol
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// GRPCClient implements the gRPC client
+// ErrNotConfigurable is returned by Configure for plugins that take no configuration
+var ErrNotConfigurable = errors.New("plugin does not accept configuration")
+
+// MetadataTimeout bounds the GetMetadata call made when a plugin is dispensed
+const MetadataTimeout = 5 * time.Second
+
+// GRPCClient implements the gRPC client. Plugin metadata is fetched once when
+// the client is created, so the getters below never make an RPC.
 type GRPCClient struct {
-	client PluginClient
+	client   PluginClient
+	version  int
+	metadata *Metadata
+	examples map[string]interface{} // decoded from metadata
+
+	// host offers the types.Host in each call's context to the plugin
+	host *hostBroker
+
+	// conn reaches the other plugins of a bundle; see Plugin
+	conn grpc.ClientConnInterface
+}
+
+// newGRPCClient fetches the plugin's metadata and returns a client caching it
+func newGRPCClient(ctx context.Context, client PluginClient, version int) (*GRPCClient, error) {
+	ctx, cancel := context.WithTimeout(ctx, MetadataTimeout)
+	defer cancel()
+
+	metadata, err := client.GetMetadata(ctx, &Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch plugin metadata: %w", err)
+	}
+	examples, err := FromValueMap(metadata.GetExamples())
+	if err != nil {
+		return nil, fmt.Errorf("plugin sent invalid example values: %w", err)
+	}
+
+	return &GRPCClient{client: client, version: version, metadata: metadata, examples: examples}, nil
 }
 
-// ShouldExecute checks if the plugin should execute
+// ShouldExecute checks if the plugin should execute. A failed call is
+// reported as a decision not to execute; use Decide to see the error.
 func (m *GRPCClient) ShouldExecute(ctx context.Context, context *types.Context) types.ExecutionDecision {
-	resp, err := m.client.ShouldExecute(ctx, ContextToProto(context))
+	decision, err := m.Decide(ctx, context)
 	if err != nil {
 		return types.ExecutionDecision{ShouldExecute: false, Reason: err.Error()}
 	}
+	return decision
+}
+
+// Decide calls ShouldExecute, returning failures as *types.PluginError
+func (m *GRPCClient) Decide(ctx context.Context, context *types.Context) (types.ExecutionDecision, error) {
+	req, err := ContextToProtoVersion(context, m.version)
+	if err != nil {
+		return types.ExecutionDecision{}, types.InvalidInput(err.Error())
+	}
+	ref, release, err := m.host.ref(ctx)
+	if err != nil {
+		return types.ExecutionDecision{}, types.Unavailable("host services are unavailable", err)
+	}
+	defer release()
+	req.Host = ref
+	resp, err := m.client.ShouldExecute(ctx, req)
+	if err != nil {
+		return types.ExecutionDecision{}, statusToError(err)
+	}
 	return types.ExecutionDecision{
 		ShouldExecute: resp.ShouldExecute,
 		Reason:        resp.Reason,
-	}
+	}, nil
 }
 
-// Process executes the plugin processing
+// Process executes the plugin processing. Errors are *types.PluginError.
 func (m *GRPCClient) Process(ctx context.Context, context *types.Context) (*types.Context, error) {
-	resp, err := m.client.Process(ctx, ContextToProto(context))
+	req, err := ContextToProtoVersion(context, m.version)
+	if err != nil {
+		return nil, types.InvalidInput(err.Error())
+	}
+	ref, release, err := m.host.ref(ctx)
+	if err != nil {
+		return nil, types.Unavailable("host services are unavailable", err)
+	}
+	defer release()
+	req.Host = ref
+	resp, err := m.client.Process(ctx, req)
+	if err != nil {
+		return nil, statusToError(err)
+	}
+	output, err := ProtoToContext(resp)
 	if err != nil {
-		return nil, err
+		return nil, types.NewError(types.ErrorCodeInternal, "plugin returned an unreadable context", err)
 	}
-	return ProtoToContext(resp), nil
+	return output, nil
 }
 
-// Name returns the plugin name
-func (m *GRPCClient) Name() string {
-	metadata, err := m.client.GetMetadata(context.Background(), &Empty{})
+// Configure sends the plugin its settings. It returns ErrNotConfigurable
+// when the plugin takes no configuration (or predates the Configure RPC);
+// a plugin rejecting its configuration returns a *types.PluginError.
+func (m *GRPCClient) Configure(ctx context.Context, config map[string]interface{}) error {
+	values, err := ToValueMap(config)
 	if err != nil {
-		return ""
+		return types.NewError(types.ErrorCodeInvalidInput, "configuration cannot be sent", err)
+	}
+	_, err = m.client.Configure(ctx, &ConfigureRequest{Values: values})
+	if status.Code(err) == codes.Unimplemented {
+		return ErrNotConfigurable
 	}
-	return metadata.Name
+	return statusToError(err)
+}
+
+// Init asks the plugin to acquire its resources. Plugins that predate the
+// lifecycle RPCs have nothing to initialize.
+func (m *GRPCClient) Init(ctx context.Context) error {
+	_, err := m.client.Init(ctx, &Empty{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil
+	}
+	return statusToError(err)
+}
+
+// Health asks the plugin for its health. A plugin that cannot be reached is
+// unhealthy; one that predates health checks is unknown.
+func (m *GRPCClient) Health(ctx context.Context) types.Health {
+	resp, err := m.client.Health(ctx, &Empty{})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		return types.Health{Status: types.HealthUnknown, Message: "plugin does not report health"}
+	case err != nil:
+		return types.Health{Status: types.HealthUnhealthy, Message: types.AsPluginError(statusToError(err)).Message}
+	}
+	return types.Health{Status: types.HealthStatus(resp.GetStatus()), Message: resp.GetMessage()}
+}
+
+// Shutdown asks the plugin to release its resources; ctx's deadline is the
+// grace period it gets before being killed
+func (m *GRPCClient) Shutdown(ctx context.Context) error {
+	_, err := m.client.Shutdown(ctx, &Empty{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil
+	}
+	return statusToError(err)
+}
+
+// Close stops the host services offered to a plugin the host attached to.
+// The connection itself belongs to go-plugin's client.
+func (m *GRPCClient) Close() error {
+	return m.host.close()
+}
+
+// ProtocolVersion returns the protocol version negotiated with the plugin
+func (m *GRPCClient) ProtocolVersion() int {
+	return m.version
+}
+
+// Name returns the plugin name
+func (m *GRPCClient) Name() string {
+	return m.metadata.Name
 }
 
 // Version returns the plugin version
 func (m *GRPCClient) Version() string {
-	metadata, err := m.client.GetMetadata(context.Background(), &Empty{})
-	if err != nil {
-		return ""
-	}
-	return metadata.Version
+	return m.metadata.Version
 }
 
 // BuildTime returns the build time
 func (m *GRPCClient) BuildTime() string {
-	metadata, err := m.client.GetMetadata(context.Background(), &Empty{})
-	if err != nil {
-		return ""
-	}
-	return metadata.BuildTime
+	return m.metadata.BuildTime
 }
 
 // MinCLIVersion returns the minimum CLI version
 func (m *GRPCClient) MinCLIVersion() string {
-	metadata, err := m.client.GetMetadata(context.Background(), &Empty{})
-	if err != nil {
-		return ""
-	}
-	return metadata.MinCliVersion
+	return m.metadata.MinCliVersion
 }
 
 // MaxCLIVersion returns the maximum CLI version
 func (m *GRPCClient) MaxCLIVersion() string {
-	metadata, err := m.client.GetMetadata(context.Background(), &Empty{})
-	if err != nil {
-		return ""
-	}
-	return metadata.MaxCliVersion
+	return m.metadata.MaxCliVersion
 }
 
 // Description returns the plugin description
 func (m *GRPCClient) Description() string {
-	metadata, err := m.client.GetMetadata(context.Background(), &Empty{})
-	if err != nil {
-		return ""
-	}
-	return metadata.Description
+	return m.metadata.Description
 }
 
 // Priority returns the plugin priority
 func (m *GRPCClient) Priority() int {
-	metadata, err := m.client.GetMetadata(context.Background(), &Empty{})
-	if err != nil {
-		return 100
+	return int(m.metadata.Priority)
+}
+
+// Timeouts returns the RPC deadlines declared by the plugin
+func (m *GRPCClient) Timeouts() types.Timeouts {
+	return types.Timeouts{
+		ShouldExecute: time.Duration(m.metadata.ShouldExecuteTimeoutMs) * time.Millisecond,
+		Process:       time.Duration(m.metadata.ProcessTimeoutMs) * time.Millisecond,
+	}
+}
+
+// Dependencies returns the Properties keys the plugin requires and provides
+func (m *GRPCClient) Dependencies() types.Dependencies {
+	return types.Dependencies{
+		Requires:     m.metadata.Requires,
+		Provides:     m.metadata.Provides,
+		Examples:     m.examples,
+		RequiresHost: m.metadata.RequiresHost,
 	}
-	return int(metadata.Priority)
 }
@@ -8,19 +8,71 @@ import (
 	"google.golang.org/grpc"
 )
 
-// GRPCPlugin is the gRPC plugin implementation
+// GRPCPlugin is the gRPC plugin implementation for the latest protocol version
 type GRPCPlugin struct {
 	plugin.Plugin
 	Impl types.VersionedPlugin
+	// Impls is served instead of Impl by a process serving several plugins
+	Impls map[string]types.VersionedPlugin
+
+	// attached offers host services on a socket of the host's own
+	attached bool
 }
 
 // GRPCServer registers the gRPC server
 func (p *GRPCPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
-	RegisterPluginServer(s, &GRPCServer{Impl: p.Impl})
+	registerServer(s, p.Impl, p.Impls, LatestProtocolVersion, &hostDialer{broker: broker})
 	return nil
 }
 
-// GRPCClient returns the gRPC client
+// GRPCClient returns the gRPC client, failing if the plugin's metadata
+// cannot be fetched
 func (p *GRPCPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
-	return &GRPCClient{client: NewPluginClient(c)}, nil
+	client, err := newGRPCClient(ctx, NewPluginClient(c), LatestProtocolVersion)
+	if err != nil {
+		return nil, err
+	}
+	client.conn = c
+	client.host = &hostBroker{broker: broker, listen: p.attached}
+	return client, nil
+}
+
+// GRPCPluginV1 is the gRPC plugin implementation for protocol version 1,
+// used with plugins and hosts built before version negotiation
+type GRPCPluginV1 struct {
+	plugin.Plugin
+	Impl types.VersionedPlugin
+	// Impls is served instead of Impl by a process serving several plugins
+	Impls map[string]types.VersionedPlugin
+
+	// attached offers host services on a socket of the host's own
+	attached bool
+}
+
+// GRPCServer registers the gRPC server
+func (p *GRPCPluginV1) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	registerServer(s, p.Impl, p.Impls, ProtocolVersion1, &hostDialer{broker: broker})
+	return nil
+}
+
+// GRPCClient returns the gRPC client, failing if the plugin's metadata
+// cannot be fetched
+func (p *GRPCPluginV1) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	client, err := newGRPCClient(ctx, NewPluginClient(c), ProtocolVersion1)
+	if err != nil {
+		return nil, err
+	}
+	client.conn = c
+	client.host = &hostBroker{broker: broker, listen: p.attached}
+	return client, nil
+}
+
+// registerServer registers impl, or every plugin in impls when set. The
+// plugins of a bundle share one connection to the host services.
+func registerServer(s *grpc.Server, impl types.VersionedPlugin, impls map[string]types.VersionedPlugin, version int, host *hostDialer) {
+	if impls != nil {
+		RegisterPluginServer(s, newBundleServer(impls, version, host))
+		return
+	}
+	RegisterPluginServer(s, &GRPCServer{Impl: impl, version: version, host: host})
 }
@@ -0,0 +1,144 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+)
+
+type countingPlugin struct{}
+
+func (countingPlugin) ShouldExecute(ctx context.Context, c *types.Context) types.ExecutionDecision {
+	return types.ExecutionDecision{ShouldExecute: true}
+}
+
+func (countingPlugin) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	c.Properties["count"] = c.Properties["count"].(int64) + 1
+	return c, nil
+}
+
+func (countingPlugin) Name() string          { return "counting" }
+func (countingPlugin) Description() string   { return "counts" }
+func (countingPlugin) Priority() int         { return 10 }
+func (countingPlugin) Version() string       { return "1.0.0" }
+func (countingPlugin) BuildTime() string     { return "" }
+func (countingPlugin) MinCLIVersion() string { return "" }
+func (countingPlugin) MaxCLIVersion() string { return "" }
+
+func TestPluginSets_EveryVersion(t *testing.T) {
+	sets := PluginSets(countingPlugin{})
+	require.Len(t, sets, len(VersionedPlugins))
+
+	for version, set := range sets {
+		client, _ := plugin.TestPluginGRPCConn(t, false, set)
+		defer func() { _ = client.Close() }()
+
+		raw, err := client.Dispense("plugin")
+		require.NoError(t, err)
+
+		p, ok := raw.(types.VersionedPlugin)
+		require.True(t, ok)
+		assert.Equal(t, "counting", p.Name())
+		assert.Equal(t, version, raw.(ProtocolVersioned).ProtocolVersion())
+
+		result, err := p.Process(context.Background(), &types.Context{
+			Event:      types.Event{Type: types.EventMessage},
+			Properties: map[string]interface{}{"count": 1},
+		})
+		require.NoError(t, err, "version %d", version)
+		assert.Equal(t, int64(2), result.Properties["count"], "version %d", version)
+	}
+}
+
+func TestVersionedPlugins_MixedAdapters(t *testing.T) {
+	// A plugin still serving the latest adapter under the version 1 handshake
+	// is read by the version 1 client, since decoding accepts either encoding
+	client, _ := plugin.TestPluginGRPCConn(t, false, map[string]plugin.Plugin{
+		"plugin": &GRPCPlugin{Impl: countingPlugin{}},
+	})
+	defer func() { _ = client.Close() }()
+
+	conn := client.Conn
+	v1, err := (&GRPCPluginV1{}).GRPCClient(context.Background(), nil, conn)
+	require.NoError(t, err)
+
+	result, err := v1.(types.VersionedPlugin).Process(context.Background(), &types.Context{
+		Event:      types.Event{Type: types.EventMessage},
+		Properties: map[string]interface{}{"count": 41},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), result.Properties["count"])
+}
+
+// metadataClient counts GetMetadata calls; the other RPCs are not used
+type metadataClient struct {
+	PluginClient
+	calls    int
+	metadata *Metadata
+	err      error
+	deadline bool
+}
+
+func (c *metadataClient) GetMetadata(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Metadata, error) {
+	c.calls++
+	_, c.deadline = ctx.Deadline()
+	return c.metadata, c.err
+}
+
+func TestGRPCClient_CachesMetadata(t *testing.T) {
+	fake := &metadataClient{metadata: &Metadata{
+		Name:             "cached",
+		Version:          "1.2.3",
+		Priority:         20,
+		ProcessTimeoutMs: 1500,
+		Requires:         []string{"action"},
+	}}
+
+	client, err := newGRPCClient(context.Background(), fake, LatestProtocolVersion)
+	require.NoError(t, err)
+	assert.True(t, fake.deadline, "metadata fetch should be bounded")
+
+	for range 3 {
+		assert.Equal(t, "cached", client.Name())
+		assert.Equal(t, 20, client.Priority())
+	}
+	assert.Equal(t, "1.2.3", client.Version())
+	assert.Equal(t, 1500*time.Millisecond, client.Timeouts().Process)
+	assert.Equal(t, []string{"action"}, client.Dependencies().Requires)
+	assert.Equal(t, 1, fake.calls)
+}
+
+func TestGRPCClient_MetadataError(t *testing.T) {
+	fake := &metadataClient{err: errors.New("connection refused")}
+
+	client, err := newGRPCClient(context.Background(), fake, LatestProtocolVersion)
+	require.Error(t, err)
+	assert.Nil(t, client)
+	assert.Contains(t, err.Error(), "failed to fetch plugin metadata")
+	assert.Contains(t, err.Error(), "connection refused")
+}
+
+// examplePlugin declares the keys it provides with example values
+type examplePlugin struct{ countingPlugin }
+
+func (examplePlugin) Dependencies() types.Dependencies {
+	return types.Dependencies{
+		Provides: []string{"media_type", "size"},
+		Examples: map[string]interface{}{"media_type": "video", "size": int64(1024)},
+	}
+}
+
+func TestGRPCClient_DependencyExamples(t *testing.T) {
+	client := dispense(t, PluginSets(examplePlugin{})[LatestProtocolVersion])
+
+	deps := client.Dependencies()
+	assert.Equal(t, []string{"media_type", "size"}, deps.Provides)
+	assert.Equal(t, map[string]interface{}{"media_type": "video", "size": int64(1024)}, deps.Examples)
+}
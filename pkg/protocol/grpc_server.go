@@ -4,17 +4,33 @@ import (
 	"context"
 
 	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // GRPCServer implements the gRPC server
 type GRPCServer struct {
 	Impl types.VersionedPlugin
 	UnimplementedPluginServer
+
+	// version is the negotiated protocol version replies are encoded for
+	version int
+
+	// host connects to the host services offered with each request
+	host *hostDialer
 }
 
 // ShouldExecute decides if the plugin should run
 func (m *GRPCServer) ShouldExecute(ctx context.Context, req *ContextProto) (*ExecutionDecisionProto, error) {
-	context := ProtoToContext(req)
+	ctx, release, err := m.host.withHost(ctx, req.GetHost())
+	if err != nil {
+		return nil, errorToStatus(types.Unavailable("host services are unreachable", err))
+	}
+	defer release()
+	context, err := ProtoToContext(req)
+	if err != nil {
+		return nil, errorToStatus(types.NewError(types.ErrorCodeInvalidInput, "failed to decode context", err))
+	}
 	decision := m.Impl.ShouldExecute(ctx, context)
 	return &ExecutionDecisionProto{
 		ShouldExecute: decision.ShouldExecute,
@@ -24,17 +40,83 @@ func (m *GRPCServer) ShouldExecute(ctx context.Context, req *ContextProto) (*Exe
 
 // Process handles the event processing
 func (m *GRPCServer) Process(ctx context.Context, req *ContextProto) (*ContextProto, error) {
-	inputContext := ProtoToContext(req)
+	ctx, release, err := m.host.withHost(ctx, req.GetHost())
+	if err != nil {
+		return nil, errorToStatus(types.Unavailable("host services are unreachable", err))
+	}
+	defer release()
+	inputContext, err := ProtoToContext(req)
+	if err != nil {
+		return nil, errorToStatus(types.NewError(types.ErrorCodeInvalidInput, "failed to decode context", err))
+	}
 	outputContext, err := m.Impl.Process(ctx, inputContext)
 	if err != nil {
-		return nil, err
+		return nil, errorToStatus(err)
+	}
+
+	resp, err := ContextToProtoVersion(outputContext, m.version)
+	if err != nil {
+		return nil, errorToStatus(types.NewError(types.ErrorCodeInternal, "plugin returned a context that cannot be sent", err))
+	}
+	return resp, nil
+}
+
+// Configure passes the plugin its settings. Plugins that are not
+// types.Configurable only accept an empty configuration.
+func (m *GRPCServer) Configure(ctx context.Context, req *ConfigureRequest) (*Empty, error) {
+	configurable, ok := m.Impl.(types.Configurable)
+	if !ok {
+		if len(req.GetValues()) > 0 {
+			return nil, status.Error(codes.Unimplemented, "plugin does not accept configuration")
+		}
+		return &Empty{}, nil
+	}
+
+	config, err := FromValueMap(req.GetValues())
+	if err != nil {
+		return nil, errorToStatus(types.NewError(types.ErrorCodeInvalidInput, "failed to decode configuration", err))
 	}
-	return ContextToProto(outputContext), nil
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	if err := configurable.Configure(ctx, config); err != nil {
+		return nil, errorToStatus(err)
+	}
+	return &Empty{}, nil
+}
+
+// Init lets the plugin acquire its resources
+func (m *GRPCServer) Init(ctx context.Context, req *Empty) (*Empty, error) {
+	if initializer, ok := m.Impl.(types.Initializer); ok {
+		if err := initializer.Init(ctx); err != nil {
+			return nil, errorToStatus(err)
+		}
+	}
+	return &Empty{}, nil
+}
+
+// Health reports the plugin's health; plugins without a check are serving
+func (m *GRPCServer) Health(ctx context.Context, req *Empty) (*HealthResponse, error) {
+	health := types.Health{Status: types.HealthServing}
+	if checker, ok := m.Impl.(types.HealthChecker); ok {
+		health = checker.Health(ctx)
+	}
+	return &HealthResponse{Status: string(health.Status), Message: health.Message}, nil
+}
+
+// Shutdown lets the plugin release its resources before the process is killed
+func (m *GRPCServer) Shutdown(ctx context.Context, req *Empty) (*Empty, error) {
+	if shutdowner, ok := m.Impl.(types.Shutdowner); ok {
+		if err := shutdowner.Shutdown(ctx); err != nil {
+			return nil, errorToStatus(err)
+		}
+	}
+	return &Empty{}, nil
 }
 
 // GetMetadata returns plugin metadata
 func (m *GRPCServer) GetMetadata(ctx context.Context, req *Empty) (*Metadata, error) {
-	return &Metadata{
+	metadata := &Metadata{
 		Name:          m.Impl.Name(),
 		Version:       m.Impl.Version(),
 		BuildTime:     m.Impl.BuildTime(),
@@ -42,5 +124,27 @@ func (m *GRPCServer) GetMetadata(ctx context.Context, req *Empty) (*Metadata, er
 		MaxCliVersion: m.Impl.MaxCLIVersion(),
 		Description:   m.Impl.Description(),
 		Priority:      int32(m.Impl.Priority()),
-	}, nil
+	}
+
+	// Timeouts are optional; plugins that don't declare them get host defaults
+	if tp, ok := m.Impl.(types.TimeoutProvider); ok {
+		timeouts := tp.Timeouts()
+		metadata.ShouldExecuteTimeoutMs = timeouts.ShouldExecute.Milliseconds()
+		metadata.ProcessTimeoutMs = timeouts.Process.Milliseconds()
+	}
+
+	if dp, ok := m.Impl.(types.DependencyProvider); ok {
+		deps := dp.Dependencies()
+		metadata.Requires = deps.Requires
+		metadata.Provides = deps.Provides
+		metadata.RequiresHost = deps.RequiresHost
+
+		examples, err := ToValueMap(deps.Examples)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid example values: %v", err)
+		}
+		metadata.Examples = examples
+	}
+
+	return metadata, nil
 }
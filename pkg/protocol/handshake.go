@@ -1,15 +1,75 @@
 package protocol
 
-import "github.com/hashicorp/go-plugin"
+import (
+	"github.com/hashicorp/go-plugin"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
 
-// Handshake is the shared handshake config for plugins
+// Protocol versions the CLI and plugins can negotiate. go-plugin picks the
+// highest version both sides support, so a protocol change only needs a new
+// version here and plugins can move to it one at a time.
+const (
+	// ProtocolVersion1 sends Properties, metadata and response data as JSON strings
+	ProtocolVersion1 = 1
+	// ProtocolVersion2 sends them as typed values
+	ProtocolVersion2 = 2
+
+	// LatestProtocolVersion is the newest version this module speaks
+	LatestProtocolVersion = ProtocolVersion2
+)
+
+// Handshake is the shared handshake config for plugins. Its ProtocolVersion
+// only applies to peers that don't negotiate, which all speak version 1.
 var Handshake = plugin.HandshakeConfig{
-	ProtocolVersion:  1,
+	ProtocolVersion:  ProtocolVersion1,
 	MagicCookieKey:   "BASIC_PLUGIN",
 	MagicCookieValue: "hello-plugin",
 }
 
-// PluginMap is the map of plugins we support
+// PluginMap is the map of plugins we support over protocol version 1
 var PluginMap = map[string]plugin.Plugin{
-	"plugin": &GRPCPlugin{},
+	"plugin": &GRPCPluginV1{},
+}
+
+// VersionedPlugins is the plugin set the host dispenses from for each
+// protocol version it supports
+var VersionedPlugins = map[int]plugin.PluginSet{
+	ProtocolVersion1: {"plugin": &GRPCPluginV1{}},
+	ProtocolVersion2: {"plugin": &GRPCPlugin{}},
+}
+
+// AttachedPlugins is the plugin set the host dispenses from for a plugin it
+// attaches to instead of starting, by the protocol version the plugin
+// serves. go-plugin's broker only serves the first host to connect to a
+// plugin process, so these clients offer host services on a socket of their
+// own, and must be closed to stop it.
+var AttachedPlugins = map[int]plugin.PluginSet{
+	ProtocolVersion1: {"plugin": &GRPCPluginV1{attached: true}},
+	ProtocolVersion2: {"plugin": &GRPCPlugin{attached: true}},
+}
+
+// PluginSets returns the plugin sets serving impl over every supported
+// protocol version, for use as ServeConfig.VersionedPlugins
+func PluginSets(impl types.VersionedPlugin) map[int]plugin.PluginSet {
+	return map[int]plugin.PluginSet{
+		ProtocolVersion1: {"plugin": &GRPCPluginV1{Impl: impl}},
+		ProtocolVersion2: {"plugin": &GRPCPlugin{Impl: impl}},
+	}
+}
+
+// BundleSets returns the plugin sets serving several plugins from one
+// process, for use as ServeConfig.VersionedPlugins. The CLI lists them with
+// ListPlugins and loads each as a plugin of its own; CLIs that predate
+// bundles only see the first by name.
+func BundleSets(impls map[string]types.VersionedPlugin) map[int]plugin.PluginSet {
+	return map[int]plugin.PluginSet{
+		ProtocolVersion1: {"plugin": &GRPCPluginV1{Impls: impls}},
+		ProtocolVersion2: {"plugin": &GRPCPlugin{Impls: impls}},
+	}
+}
+
+// ProtocolVersioned is implemented by plugin clients that know which
+// protocol version was negotiated with the plugin process
+type ProtocolVersioned interface {
+	ProtocolVersion() int
 }
@@ -0,0 +1,351 @@
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// hostServer serves HostService for one plugin process. Each in-flight
+// ShouldExecute or Process call registers the types.Host it was given under
+// a call ID, and the plugin names that ID when it calls back.
+type hostServer struct {
+	UnimplementedHostServiceServer
+
+	mu    sync.Mutex
+	calls map[uint64]types.Host
+	next  uint64
+}
+
+func newHostServer() *hostServer {
+	return &hostServer{calls: make(map[uint64]types.Host)}
+}
+
+// register makes host reachable for the duration of one call
+func (s *hostServer) register(host types.Host) (callID uint64, release func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next++
+	callID = s.next
+	s.calls[callID] = host
+
+	return callID, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.calls, callID)
+	}
+}
+
+func (s *hostServer) host(callID uint64) (types.Host, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	host, ok := s.calls[callID]
+	if !ok {
+		return nil, fmt.Errorf("host call %d is not active", callID)
+	}
+	return host, nil
+}
+
+func (s *hostServer) Log(ctx context.Context, req *LogRequest) (*Empty, error) {
+	host, err := s.host(req.GetCallId())
+	if err != nil {
+		return nil, err
+	}
+	fields, err := FromValueMap(req.GetFields())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode log fields: %w", err)
+	}
+	host.Log(req.GetLevel(), req.GetMessage(), fields)
+	return &Empty{}, nil
+}
+
+func (s *hostServer) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	host, err := s.host(req.GetCallId())
+	if err != nil {
+		return nil, err
+	}
+	value, found, err := host.Get(req.GetKey())
+	if err != nil || !found {
+		return &GetResponse{}, err
+	}
+	encoded, err := ToValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value of %q: %w", req.GetKey(), err)
+	}
+	return &GetResponse{Found: true, Value: encoded}, nil
+}
+
+func (s *hostServer) Set(ctx context.Context, req *SetRequest) (*Empty, error) {
+	host, err := s.host(req.GetCallId())
+	if err != nil {
+		return nil, err
+	}
+	value, err := FromValue(req.GetValue())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode value of %q: %w", req.GetKey(), err)
+	}
+	return &Empty{}, host.Set(req.GetKey(), value)
+}
+
+func (s *hostServer) Delete(ctx context.Context, req *DeleteRequest) (*Empty, error) {
+	host, err := s.host(req.GetCallId())
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, host.Delete(req.GetKey())
+}
+
+func (s *hostServer) Emit(ctx context.Context, req *EmitRequest) (*Empty, error) {
+	host, err := s.host(req.GetCallId())
+	if err != nil {
+		return nil, err
+	}
+	event, err := protoToEvent(req.GetEvent())
+	if err != nil {
+		return nil, err
+	}
+	return &Empty{}, host.Emit(event)
+}
+
+func (s *hostServer) Progress(ctx context.Context, req *ProgressRequest) (*Empty, error) {
+	host, err := s.host(req.GetCallId())
+	if err != nil {
+		return nil, err
+	}
+	host.Progress(req.GetPercent(), req.GetMessage())
+	return &Empty{}, nil
+}
+
+// hostBroker starts the host service for a plugin client the first time a
+// call carries a types.Host. go-plugin's broker only serves the first host
+// to connect to a plugin process, so for plugins the host attached to (see
+// AttachedPlugins) the service listens on a unix socket of its own instead,
+// as it does for remote plugins, which have no broker at all.
+type hostBroker struct {
+	broker *plugin.GRPCBroker
+	listen bool
+
+	once   sync.Once
+	id     uint32
+	server *hostServer
+
+	// address, grpcServer and dir are set when listening on a socket
+	address    string
+	grpcServer *grpc.Server
+	dir        string
+	err        error
+}
+
+// ref registers the host carried by ctx, if any, and returns the HostRef to
+// send with the request; release must be called once the request is done
+func (b *hostBroker) ref(ctx context.Context) (*HostRef, func(), error) {
+	host, ok := types.HostFrom(ctx)
+	if !ok || b == nil || (b.broker == nil && !b.listen) {
+		return nil, func() {}, nil
+	}
+
+	b.once.Do(func() {
+		b.server = newHostServer()
+		if b.listen {
+			b.err = b.serveSocket()
+			return
+		}
+		b.id = b.broker.NextId()
+		go b.broker.AcceptAndServe(b.id, func(opts []grpc.ServerOption) *grpc.Server {
+			s := grpc.NewServer(opts...)
+			RegisterHostServiceServer(s, b.server)
+			return s
+		})
+	})
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+
+	callID, release := b.server.register(host)
+	return &HostRef{BrokerId: b.id, CallId: callID, Address: b.address}, release, nil
+}
+
+// serveSocket serves the host service on a unix socket in a private
+// temporary directory
+func (b *hostBroker) serveSocket() error {
+	dir, err := os.MkdirTemp("", "plugin-host")
+	if err != nil {
+		return fmt.Errorf("failed to create host services socket: %w", err)
+	}
+	path := filepath.Join(dir, "host.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return fmt.Errorf("failed to listen for host services: %w", err)
+	}
+
+	b.grpcServer = grpc.NewServer()
+	RegisterHostServiceServer(b.grpcServer, b.server)
+	go func() { _ = b.grpcServer.Serve(listener) }()
+
+	b.dir = dir
+	b.address = "unix:" + path
+	return nil
+}
+
+// close stops a host service listening on its own socket
+func (b *hostBroker) close() error {
+	if b == nil || b.grpcServer == nil {
+		return nil
+	}
+	b.grpcServer.Stop()
+	return os.RemoveAll(b.dir)
+}
+
+// hostDialer connects a plugin to the host services named in a request. The
+// broker hands out each connection once, so connections are kept per broker
+// ID, or per address for hosts serving on their own socket.
+type hostDialer struct {
+	broker *plugin.GRPCBroker
+
+	// remote serves a plugin on a unix socket (see ServeRemote): it only
+	// connects to hosts on unix sockets, for each request, disconnecting once
+	// it is done as it outlives the many hosts calling it
+	remote bool
+
+	mu    sync.Mutex
+	conns map[uint32]*grpc.ClientConn
+	addrs map[string]*grpc.ClientConn
+}
+
+// withHost returns ctx carrying a client for the host services in ref;
+// release must be called once the request is done
+func (d *hostDialer) withHost(ctx context.Context, ref *HostRef) (context.Context, func(), error) {
+	if ref == nil || d == nil || (d.broker == nil && ref.GetAddress() == "") {
+		return ctx, func() {}, nil
+	}
+
+	release := func() {}
+	var conn *grpc.ClientConn
+	var err error
+	switch {
+	case d.remote && !strings.HasPrefix(ref.GetAddress(), "unix:"):
+		// Anyone able to call the plugin picks the address, so it must not
+		// make the plugin connect elsewhere
+		err = fmt.Errorf("host address %q is not a unix socket", ref.GetAddress())
+	case d.remote:
+		conn, err = grpc.NewClient(ref.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err == nil {
+			release = func() { _ = conn.Close() }
+		}
+	default:
+		conn, err = d.dial(ref)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to host services: %w", err)
+	}
+
+	return types.WithHost(ctx, &hostClient{
+		ctx:    ctx,
+		client: NewHostServiceClient(conn),
+		callID: ref.GetCallId(),
+	}), release, nil
+}
+
+func (d *hostDialer) dial(ref *HostRef) (*grpc.ClientConn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if address := ref.GetAddress(); address != "" {
+		if conn, ok := d.addrs[address]; ok {
+			return conn, nil
+		}
+		conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		if d.addrs == nil {
+			d.addrs = make(map[string]*grpc.ClientConn)
+		}
+		d.addrs[address] = conn
+		return conn, nil
+	}
+
+	if conn, ok := d.conns[ref.GetBrokerId()]; ok {
+		return conn, nil
+	}
+	conn, err := d.broker.Dial(ref.GetBrokerId())
+	if err != nil {
+		return nil, err
+	}
+	if d.conns == nil {
+		d.conns = make(map[uint32]*grpc.ClientConn)
+	}
+	d.conns[ref.GetBrokerId()] = conn
+	return conn, nil
+}
+
+// hostClient is the types.Host a plugin sees; every method is an RPC back to
+// the host, bounded by the context of the request being handled
+type hostClient struct {
+	ctx    context.Context
+	client HostServiceClient
+	callID uint64
+}
+
+func (h *hostClient) Log(level, message string, fields map[string]interface{}) {
+	encoded, err := ToValueMap(fields)
+	if err != nil {
+		encoded = nil
+		message = fmt.Sprintf("%s (fields dropped: %v)", message, err)
+	}
+	_, _ = h.client.Log(h.ctx, &LogRequest{CallId: h.callID, Level: level, Message: message, Fields: encoded})
+}
+
+func (h *hostClient) Get(key string) (interface{}, bool, error) {
+	resp, err := h.client.Get(h.ctx, &GetRequest{CallId: h.callID, Key: key})
+	if err != nil {
+		return nil, false, err
+	}
+	if !resp.GetFound() {
+		return nil, false, nil
+	}
+	value, err := FromValue(resp.GetValue())
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (h *hostClient) Set(key string, value interface{}) error {
+	encoded, err := ToValue(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value of %q: %w", key, err)
+	}
+	_, err = h.client.Set(h.ctx, &SetRequest{CallId: h.callID, Key: key, Value: encoded})
+	return err
+}
+
+func (h *hostClient) Delete(key string) error {
+	_, err := h.client.Delete(h.ctx, &DeleteRequest{CallId: h.callID, Key: key})
+	return err
+}
+
+func (h *hostClient) Emit(event types.Event) error {
+	encoded, err := eventToProto(event, formatTyped)
+	if err != nil {
+		return err
+	}
+	_, err = h.client.Emit(h.ctx, &EmitRequest{CallId: h.callID, Event: encoded})
+	return err
+}
+
+func (h *hostClient) Progress(percent float64, message string) {
+	_, _ = h.client.Progress(h.ctx, &ProgressRequest{CallId: h.callID, Percent: percent, Message: message})
+}
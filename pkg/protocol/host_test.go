@@ -0,0 +1,157 @@
+package protocol
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/blobstore"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// recordingHost is the host side of the services, keeping what plugins sent
+type recordingHost struct {
+	mu       sync.Mutex
+	logs     []string
+	fields   map[string]interface{}
+	store    map[string]interface{}
+	events   []types.Event
+	progress []float64
+	blobs    *blobstore.Store
+}
+
+func (h *recordingHost) Log(level, message string, fields map[string]interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.logs = append(h.logs, level+": "+message)
+	h.fields = fields
+}
+
+func (h *recordingHost) Get(key string) (interface{}, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	value, ok := h.store[key]
+	return value, ok, nil
+}
+
+func (h *recordingHost) Set(key string, value interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.store[key] = value
+	return nil
+}
+
+func (h *recordingHost) Delete(key string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.store, key)
+	return nil
+}
+
+func (h *recordingHost) Emit(event types.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+	return nil
+}
+
+func (h *recordingHost) Progress(percent float64, message string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.progress = append(h.progress, percent)
+}
+
+func (h *recordingHost) PutBlob(name, contentType string, r io.Reader) (types.Blob, error) {
+	return h.blobs.Put(name, contentType, r)
+}
+
+func (h *recordingHost) OpenBlob(handle string) (io.ReadCloser, types.Blob, error) {
+	return h.blobs.Open(handle)
+}
+
+// hostUser calls every host service from Process
+type hostUser struct{ countingPlugin }
+
+func (hostUser) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	host, ok := types.HostFrom(ctx)
+	if !ok {
+		c.Properties["host"] = false
+		return c, nil
+	}
+
+	host.Log(types.LogInfo, "uploading", map[string]interface{}{"size": 42})
+	host.Progress(50, "halfway")
+
+	previous, found, err := host.Get("last")
+	if err != nil {
+		return nil, err
+	}
+	if err := host.Set("last", c.Event.Content); err != nil {
+		return nil, err
+	}
+	if err := host.Delete("stale"); err != nil {
+		return nil, err
+	}
+	if err := host.Emit(types.Event{Type: types.EventCommand, Content: "done", Metadata: map[string]interface{}{"n": 1}}); err != nil {
+		return nil, err
+	}
+
+	c.Properties["host"] = true
+	c.Properties["previous"] = previous
+	c.Properties["found"] = found
+	return c, nil
+}
+
+func TestHostServices_OverBroker(t *testing.T) {
+	for version, set := range PluginSets(hostUser{}) {
+		client, _ := plugin.TestPluginGRPCConn(t, false, set)
+		defer func() { _ = client.Close() }()
+
+		raw, err := client.Dispense("plugin")
+		require.NoError(t, err)
+		p := raw.(types.VersionedPlugin)
+
+		host := &recordingHost{store: map[string]interface{}{"last": "earlier", "stale": true}}
+		input := func(content string) *types.Context {
+			return &types.Context{
+				Event:      types.Event{Type: types.EventMessage, Content: content},
+				Properties: map[string]interface{}{},
+			}
+		}
+
+		// Two calls reuse the same broker connection
+		for _, content := range []string{"first", "second"} {
+			result, err := p.Process(types.WithHost(context.Background(), host), input(content))
+			require.NoError(t, err, "version %d", version)
+			assert.Equal(t, true, result.Properties["host"])
+			assert.Equal(t, true, result.Properties["found"])
+		}
+
+		assert.Equal(t, []string{"info: uploading", "info: uploading"}, host.logs)
+		assert.Equal(t, int64(42), host.fields["size"])
+		assert.Equal(t, []float64{50, 50}, host.progress)
+		assert.Equal(t, "second", host.store["last"])
+		assert.NotContains(t, host.store, "stale")
+		require.Len(t, host.events, 2)
+		assert.Equal(t, types.EventCommand, host.events[0].Type)
+		assert.Equal(t, int64(1), host.events[0].Metadata["n"])
+
+		// Without a host in the context the plugin sees none
+		result, err := p.Process(context.Background(), input("third"))
+		require.NoError(t, err)
+		assert.Equal(t, false, result.Properties["host"])
+	}
+}
+
+func TestHostServer_UnknownCall(t *testing.T) {
+	server := newHostServer()
+	callID, release := server.register(&recordingHost{})
+	release()
+
+	_, err := server.Log(context.Background(), &LogRequest{CallId: callID, Message: "late"})
+	assert.ErrorContains(t, err, "is not active")
+}
@@ -0,0 +1,114 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+)
+
+// lifecyclePlugin records lifecycle calls and reports the health it is given
+type lifecyclePlugin struct {
+	countingPlugin
+	calls   *[]string
+	health  types.Health
+	initErr error
+}
+
+func (l lifecyclePlugin) Init(ctx context.Context) error {
+	*l.calls = append(*l.calls, "init")
+	return l.initErr
+}
+
+func (l lifecyclePlugin) Health(ctx context.Context) types.Health {
+	return l.health
+}
+
+func (l lifecyclePlugin) Shutdown(ctx context.Context) error {
+	*l.calls = append(*l.calls, "shutdown")
+	return nil
+}
+
+// legacyGRPCPlugin serves a plugin built before the lifecycle RPCs existed
+type legacyGRPCPlugin struct {
+	GRPCPlugin
+}
+
+type legacyServer struct {
+	UnimplementedPluginServer
+	metadata *GRPCServer
+}
+
+func (s legacyServer) GetMetadata(ctx context.Context, req *Empty) (*Metadata, error) {
+	return s.metadata.GetMetadata(ctx, req)
+}
+
+func (p *legacyGRPCPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	RegisterPluginServer(s, legacyServer{metadata: &GRPCServer{Impl: p.Impl}})
+	return nil
+}
+
+func dispense(t *testing.T, set plugin.PluginSet) *GRPCClient {
+	t.Helper()
+
+	client, _ := plugin.TestPluginGRPCConn(t, false, set)
+	t.Cleanup(func() { _ = client.Close() })
+
+	raw, err := client.Dispense("plugin")
+	require.NoError(t, err)
+	return raw.(*GRPCClient)
+}
+
+func TestLifecycle(t *testing.T) {
+	var calls []string
+	degraded := types.Health{Status: types.HealthDegraded, Message: "cache is cold"}
+	p := dispense(t, PluginSets(lifecyclePlugin{calls: &calls, health: degraded})[LatestProtocolVersion])
+
+	require.NoError(t, p.Init(context.Background()))
+	assert.Equal(t, degraded, p.Health(context.Background()))
+	require.NoError(t, p.Shutdown(context.Background()))
+	assert.Equal(t, []string{"init", "shutdown"}, calls)
+}
+
+func TestLifecycle_InitFailure(t *testing.T) {
+	var calls []string
+	p := dispense(t, PluginSets(lifecyclePlugin{calls: &calls, initErr: types.Unavailable("database is down", errors.New("connection refused"))})[LatestProtocolVersion])
+
+	err := p.Init(context.Background())
+	assert.Equal(t, types.ErrorCodeUnavailable, types.AsPluginError(err).Code)
+	assert.ErrorContains(t, err, "database is down")
+}
+
+func TestLifecycle_Defaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		set    plugin.PluginSet
+		health types.HealthStatus
+	}{
+		{
+			name:   "plugin without lifecycle methods",
+			set:    PluginSets(countingPlugin{})[LatestProtocolVersion],
+			health: types.HealthServing,
+		},
+		{
+			name:   "plugin built before lifecycle RPCs",
+			set:    plugin.PluginSet{"plugin": &legacyGRPCPlugin{GRPCPlugin{Impl: countingPlugin{}}}},
+			health: types.HealthUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := dispense(t, tt.set)
+
+			assert.NoError(t, p.Init(context.Background()))
+			assert.Equal(t, tt.health, p.Health(context.Background()).Status)
+			assert.NoError(t, p.Shutdown(context.Background()))
+		})
+	}
+}
@@ -10,6 +10,7 @@ package protocol
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -58,33 +59,1535 @@ func (*Empty) Descriptor() ([]byte, []int) {
 	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{0}
 }
 
+// Value is a typed value that survives the trip between host and plugin
+// without losing integer precision, bytes or timestamps
+type Value struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Value_NullValue
+	//	*Value_BoolValue
+	//	*Value_IntValue
+	//	*Value_UintValue
+	//	*Value_DoubleValue
+	//	*Value_StringValue
+	//	*Value_BytesValue
+	//	*Value_TimestampValue
+	//	*Value_ListValue
+	//	*Value_MapValue
+	Kind          isValue_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Value) GetKind() isValue_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Value) GetNullValue() bool {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_NullValue); ok {
+			return x.NullValue
+		}
+	}
+	return false
+}
+
+func (x *Value) GetBoolValue() bool {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_BoolValue); ok {
+			return x.BoolValue
+		}
+	}
+	return false
+}
+
+func (x *Value) GetIntValue() int64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetUintValue() uint64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_UintValue); ok {
+			return x.UintValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetDoubleValue() float64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_DoubleValue); ok {
+			return x.DoubleValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *Value) GetBytesValue() []byte {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_BytesValue); ok {
+			return x.BytesValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetTimestampValue() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_TimestampValue); ok {
+			return x.TimestampValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetListValue() *ListValue {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_ListValue); ok {
+			return x.ListValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetMapValue() *MapValue {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_MapValue); ok {
+			return x.MapValue
+		}
+	}
+	return nil
+}
+
+type isValue_Kind interface {
+	isValue_Kind()
+}
+
+type Value_NullValue struct {
+	NullValue bool `protobuf:"varint,1,opt,name=null_value,json=nullValue,proto3,oneof"` // set (to true) for a nil value
+}
+
+type Value_BoolValue struct {
+	BoolValue bool `protobuf:"varint,2,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type Value_IntValue struct {
+	IntValue int64 `protobuf:"varint,3,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type Value_UintValue struct {
+	UintValue uint64 `protobuf:"varint,4,opt,name=uint_value,json=uintValue,proto3,oneof"`
+}
+
+type Value_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,5,opt,name=double_value,json=doubleValue,proto3,oneof"`
+}
+
+type Value_StringValue struct {
+	StringValue string `protobuf:"bytes,6,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Value_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,7,opt,name=bytes_value,json=bytesValue,proto3,oneof"`
+}
+
+type Value_TimestampValue struct {
+	TimestampValue *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=timestamp_value,json=timestampValue,proto3,oneof"`
+}
+
+type Value_ListValue struct {
+	ListValue *ListValue `protobuf:"bytes,9,opt,name=list_value,json=listValue,proto3,oneof"`
+}
+
+type Value_MapValue struct {
+	MapValue *MapValue `protobuf:"bytes,10,opt,name=map_value,json=mapValue,proto3,oneof"`
+}
+
+func (*Value_NullValue) isValue_Kind() {}
+
+func (*Value_BoolValue) isValue_Kind() {}
+
+func (*Value_IntValue) isValue_Kind() {}
+
+func (*Value_UintValue) isValue_Kind() {}
+
+func (*Value_DoubleValue) isValue_Kind() {}
+
+func (*Value_StringValue) isValue_Kind() {}
+
+func (*Value_BytesValue) isValue_Kind() {}
+
+func (*Value_TimestampValue) isValue_Kind() {}
+
+func (*Value_ListValue) isValue_Kind() {}
+
+func (*Value_MapValue) isValue_Kind() {}
+
+type ListValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []*Value               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListValue) Reset() {
+	*x = ListValue{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListValue) ProtoMessage() {}
+
+func (x *ListValue) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListValue.ProtoReflect.Descriptor instead.
+func (*ListValue) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListValue) GetValues() []*Value {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type MapValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fields        map[string]*Value      `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MapValue) Reset() {
+	*x = MapValue{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MapValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MapValue) ProtoMessage() {}
+
+func (x *MapValue) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MapValue.ProtoReflect.Descriptor instead.
+func (*MapValue) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MapValue) GetFields() map[string]*Value {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
 type EventProto struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
-	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
-	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
-	UserId        string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	ChannelId     string                 `protobuf:"bytes,5,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
-	MetadataJson  string                 `protobuf:"bytes,6,opt,name=metadata_json,json=metadataJson,proto3" json:"metadata_json,omitempty"` // JSON serialized map
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	UserId        string                 `protobuf:"bytes,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ChannelId     string                 `protobuf:"bytes,5,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	MetadataJson  string                 `protobuf:"bytes,6,opt,name=metadata_json,json=metadataJson,proto3" json:"metadata_json,omitempty"` // JSON serialized map (legacy)
+	Metadata      map[string]*Value      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Attachments   []*Blob                `protobuf:"bytes,8,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventProto) Reset() {
+	*x = EventProto{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventProto) ProtoMessage() {}
+
+func (x *EventProto) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventProto.ProtoReflect.Descriptor instead.
+func (*EventProto) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EventProto) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EventProto) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *EventProto) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *EventProto) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *EventProto) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *EventProto) GetMetadataJson() string {
+	if x != nil {
+		return x.MetadataJson
+	}
+	return ""
+}
+
+func (x *EventProto) GetMetadata() map[string]*Value {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *EventProto) GetAttachments() []*Blob {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+type ResponseProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PluginName    string                 `protobuf:"bytes,1,opt,name=plugin_name,json=pluginName,proto3" json:"plugin_name,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	DataJson      string                 `protobuf:"bytes,4,opt,name=data_json,json=dataJson,proto3" json:"data_json,omitempty"` // JSON serialized map (legacy)
+	Data          map[string]*Value      `protobuf:"bytes,5,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResponseProto) Reset() {
+	*x = ResponseProto{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResponseProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseProto) ProtoMessage() {}
+
+func (x *ResponseProto) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseProto.ProtoReflect.Descriptor instead.
+func (*ResponseProto) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ResponseProto) GetPluginName() string {
+	if x != nil {
+		return x.PluginName
+	}
+	return ""
+}
+
+func (x *ResponseProto) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ResponseProto) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ResponseProto) GetDataJson() string {
+	if x != nil {
+		return x.DataJson
+	}
+	return ""
+}
+
+func (x *ResponseProto) GetData() map[string]*Value {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ControlProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signal        string                 `protobuf:"bytes,1,opt,name=signal,proto3" json:"signal,omitempty"` // "continue", "stop" or "reject"
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Plugin        string                 `protobuf:"bytes,3,opt,name=plugin,proto3" json:"plugin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlProto) Reset() {
+	*x = ControlProto{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlProto) ProtoMessage() {}
+
+func (x *ControlProto) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlProto.ProtoReflect.Descriptor instead.
+func (*ControlProto) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ControlProto) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *ControlProto) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ControlProto) GetPlugin() string {
+	if x != nil {
+		return x.Plugin
+	}
+	return ""
+}
+
+type ContextProto struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Event          *EventProto            `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	PropertiesJson string                 `protobuf:"bytes,2,opt,name=properties_json,json=propertiesJson,proto3" json:"properties_json,omitempty"` // JSON serialized map (legacy)
+	Responses      []*ResponseProto       `protobuf:"bytes,3,rep,name=responses,proto3" json:"responses,omitempty"`
+	Control        *ControlProto          `protobuf:"bytes,4,opt,name=control,proto3" json:"control,omitempty"` // unset means continue
+	Properties     map[string]*Value      `protobuf:"bytes,5,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Host           *HostRef               `protobuf:"bytes,6,opt,name=host,proto3" json:"host,omitempty"` // unset when the host offers no services
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ContextProto) Reset() {
+	*x = ContextProto{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContextProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextProto) ProtoMessage() {}
+
+func (x *ContextProto) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextProto.ProtoReflect.Descriptor instead.
+func (*ContextProto) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ContextProto) GetEvent() *EventProto {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ContextProto) GetPropertiesJson() string {
+	if x != nil {
+		return x.PropertiesJson
+	}
+	return ""
+}
+
+func (x *ContextProto) GetResponses() []*ResponseProto {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+func (x *ContextProto) GetControl() *ControlProto {
+	if x != nil {
+		return x.Control
+	}
+	return nil
+}
+
+func (x *ContextProto) GetProperties() map[string]*Value {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+func (x *ContextProto) GetHost() *HostRef {
+	if x != nil {
+		return x.Host
+	}
+	return nil
+}
+
+// HostRef tells a plugin where to reach the host services for one request
+type HostRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BrokerId      uint32                 `protobuf:"varint,1,opt,name=broker_id,json=brokerId,proto3" json:"broker_id,omitempty"`
+	CallId        uint64                 `protobuf:"varint,2,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"` // set when the host serves HostService itself rather than through the broker
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostRef) Reset() {
+	*x = HostRef{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostRef) ProtoMessage() {}
+
+func (x *HostRef) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostRef.ProtoReflect.Descriptor instead.
+func (*HostRef) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *HostRef) GetBrokerId() uint32 {
+	if x != nil {
+		return x.BrokerId
+	}
+	return 0
+}
+
+func (x *HostRef) GetCallId() uint64 {
+	if x != nil {
+		return x.CallId
+	}
+	return 0
+}
+
+func (x *HostRef) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type ExecutionDecisionProto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShouldExecute bool                   `protobuf:"varint,1,opt,name=should_execute,json=shouldExecute,proto3" json:"should_execute,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecutionDecisionProto) Reset() {
+	*x = ExecutionDecisionProto{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecutionDecisionProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionDecisionProto) ProtoMessage() {}
+
+func (x *ExecutionDecisionProto) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionDecisionProto.ProtoReflect.Descriptor instead.
+func (*ExecutionDecisionProto) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExecutionDecisionProto) GetShouldExecute() bool {
+	if x != nil {
+		return x.ShouldExecute
+	}
+	return false
+}
+
+func (x *ExecutionDecisionProto) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type Metadata struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Name                   string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version                string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	BuildTime              string                 `protobuf:"bytes,3,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"`
+	MinCliVersion          string                 `protobuf:"bytes,4,opt,name=min_cli_version,json=minCliVersion,proto3" json:"min_cli_version,omitempty"`
+	MaxCliVersion          string                 `protobuf:"bytes,5,opt,name=max_cli_version,json=maxCliVersion,proto3" json:"max_cli_version,omitempty"`
+	Description            string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	Priority               int32                  `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	ShouldExecuteTimeoutMs int64                  `protobuf:"varint,8,opt,name=should_execute_timeout_ms,json=shouldExecuteTimeoutMs,proto3" json:"should_execute_timeout_ms,omitempty"`             // 0 = use host default
+	ProcessTimeoutMs       int64                  `protobuf:"varint,9,opt,name=process_timeout_ms,json=processTimeoutMs,proto3" json:"process_timeout_ms,omitempty"`                                 // 0 = use host default
+	Requires               []string               `protobuf:"bytes,10,rep,name=requires,proto3" json:"requires,omitempty"`                                                                           // Properties keys read by the plugin
+	Provides               []string               `protobuf:"bytes,11,rep,name=provides,proto3" json:"provides,omitempty"`                                                                           // Properties keys written by the plugin
+	Examples               map[string]*Value      `protobuf:"bytes,12,rep,name=examples,proto3" json:"examples,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Typical values of provided keys
+	RequiresHost           bool                   `protobuf:"varint,13,opt,name=requires_host,json=requiresHost,proto3" json:"requires_host,omitempty"`                                              // Needs host services, e.g. for blobs
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Metadata) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Metadata) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Metadata) GetBuildTime() string {
+	if x != nil {
+		return x.BuildTime
+	}
+	return ""
+}
+
+func (x *Metadata) GetMinCliVersion() string {
+	if x != nil {
+		return x.MinCliVersion
+	}
+	return ""
+}
+
+func (x *Metadata) GetMaxCliVersion() string {
+	if x != nil {
+		return x.MaxCliVersion
+	}
+	return ""
+}
+
+func (x *Metadata) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Metadata) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Metadata) GetShouldExecuteTimeoutMs() int64 {
+	if x != nil {
+		return x.ShouldExecuteTimeoutMs
+	}
+	return 0
+}
+
+func (x *Metadata) GetProcessTimeoutMs() int64 {
+	if x != nil {
+		return x.ProcessTimeoutMs
+	}
+	return 0
+}
+
+func (x *Metadata) GetRequires() []string {
+	if x != nil {
+		return x.Requires
+	}
+	return nil
+}
+
+func (x *Metadata) GetProvides() []string {
+	if x != nil {
+		return x.Provides
+	}
+	return nil
+}
+
+func (x *Metadata) GetExamples() map[string]*Value {
+	if x != nil {
+		return x.Examples
+	}
+	return nil
+}
+
+func (x *Metadata) GetRequiresHost() bool {
+	if x != nil {
+		return x.RequiresHost
+	}
+	return false
+}
+
+type ConfigureRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        map[string]*Value      `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureRequest) Reset() {
+	*x = ConfigureRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureRequest) ProtoMessage() {}
+
+func (x *ConfigureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureRequest.ProtoReflect.Descriptor instead.
+func (*ConfigureRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ConfigureRequest) GetValues() map[string]*Value {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // serving, degraded or unhealthy
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *HealthResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Command is a CLI subcommand a plugin contributes, run as
+// plugin-cli <plugin> <name>
+type Command struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Usage         string                 `protobuf:"bytes,2,opt,name=usage,proto3" json:"usage,omitempty"` // arguments after the name, e.g. "[prefix]"
+	Short         string                 `protobuf:"bytes,3,opt,name=short,proto3" json:"short,omitempty"`
+	Long          string                 `protobuf:"bytes,4,opt,name=long,proto3" json:"long,omitempty"`
+	Flags         []*CommandFlag         `protobuf:"bytes,5,rep,name=flags,proto3" json:"flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Command) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Command) GetUsage() string {
+	if x != nil {
+		return x.Usage
+	}
+	return ""
+}
+
+func (x *Command) GetShort() string {
+	if x != nil {
+		return x.Short
+	}
+	return ""
+}
+
+func (x *Command) GetLong() string {
+	if x != nil {
+		return x.Long
+	}
+	return ""
+}
+
+func (x *Command) GetFlags() []*CommandFlag {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+type CommandFlag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Shorthand     string                 `protobuf:"bytes,2,opt,name=shorthand,proto3" json:"shorthand,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // string, bool or int
+	DefaultValue  string                 `protobuf:"bytes,4,opt,name=default_value,json=defaultValue,proto3" json:"default_value,omitempty"`
+	Usage         string                 `protobuf:"bytes,5,opt,name=usage,proto3" json:"usage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandFlag) Reset() {
+	*x = CommandFlag{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandFlag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandFlag) ProtoMessage() {}
+
+func (x *CommandFlag) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandFlag.ProtoReflect.Descriptor instead.
+func (*CommandFlag) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CommandFlag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CommandFlag) GetShorthand() string {
+	if x != nil {
+		return x.Shorthand
+	}
+	return ""
+}
+
+func (x *CommandFlag) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CommandFlag) GetDefaultValue() string {
+	if x != nil {
+		return x.DefaultValue
+	}
+	return ""
+}
+
+func (x *CommandFlag) GetUsage() string {
+	if x != nil {
+		return x.Usage
+	}
+	return ""
+}
+
+type CommandList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commands      []*Command             `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandList) Reset() {
+	*x = CommandList{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandList) ProtoMessage() {}
+
+func (x *CommandList) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandList.ProtoReflect.Descriptor instead.
+func (*CommandList) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CommandList) GetCommands() []*Command {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+type CommandRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Args          []string               `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	Flags         map[string]*Value      `protobuf:"bytes,3,rep,name=flags,proto3" json:"flags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // every declared flag, typed by its flag type
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandRequest) Reset() {
+	*x = CommandRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandRequest) ProtoMessage() {}
+
+func (x *CommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandRequest.ProtoReflect.Descriptor instead.
+func (*CommandRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CommandRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CommandRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *CommandRequest) GetFlags() map[string]*Value {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+type CommandOutput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandOutput) Reset() {
+	*x = CommandOutput{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandOutput) ProtoMessage() {}
+
+func (x *CommandOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandOutput.ProtoReflect.Descriptor instead.
+func (*CommandOutput) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CommandOutput) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// PluginList is empty for a process serving a single plugin
+type PluginList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Names         []string               `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PluginList) Reset() {
+	*x = PluginList{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PluginList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PluginList) ProtoMessage() {}
+
+func (x *PluginList) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PluginList.ProtoReflect.Descriptor instead.
+func (*PluginList) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PluginList) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+// ErrorDetail is attached to the gRPC status of a failed call so the host
+// can tell plugin bugs, rejections and retryable failures apart
+type ErrorDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"` // shown to users
+	Retryable     bool                   `protobuf:"varint,3,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	Detail        string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"` // for logs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorDetail) Reset() {
+	*x = ErrorDetail{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorDetail) ProtoMessage() {}
+
+func (x *ErrorDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorDetail.ProtoReflect.Descriptor instead.
+func (*ErrorDetail) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ErrorDetail) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorDetail) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *ErrorDetail) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type LogRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Level         string                 `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"` // debug, info, warn or error
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Fields        map[string]*Value      `protobuf:"bytes,4,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRequest) Reset() {
+	*x = LogRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRequest) ProtoMessage() {}
+
+func (x *LogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
+func (*LogRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LogRequest) GetCallId() uint64 {
+	if x != nil {
+		return x.CallId
+	}
+	return 0
+}
+
+func (x *LogRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogRequest) GetFields() map[string]*Value {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetRequest) GetCallId() uint64 {
+	if x != nil {
+		return x.CallId
+	}
+	return 0
+}
+
+func (x *GetRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Found         bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	Value         *Value                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EventProto) Reset() {
-	*x = EventProto{}
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[1]
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EventProto) String() string {
+func (x *GetResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EventProto) ProtoMessage() {}
+func (*GetResponse) ProtoMessage() {}
 
-func (x *EventProto) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[1]
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -95,78 +1598,108 @@ func (x *EventProto) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EventProto.ProtoReflect.Descriptor instead.
-func (*EventProto) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *EventProto) GetType() string {
+func (x *GetResponse) GetFound() bool {
 	if x != nil {
-		return x.Type
+		return x.Found
 	}
-	return ""
+	return false
 }
 
-func (x *EventProto) GetSource() string {
+func (x *GetResponse) GetValue() *Value {
 	if x != nil {
-		return x.Source
+		return x.Value
 	}
-	return ""
+	return nil
 }
 
-func (x *EventProto) GetContent() string {
+type SetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value         *Value                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetRequest) Reset() {
+	*x = SetRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetRequest) ProtoMessage() {}
+
+func (x *SetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[23]
 	if x != nil {
-		return x.Content
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *EventProto) GetUserId() string {
+// Deprecated: Use SetRequest.ProtoReflect.Descriptor instead.
+func (*SetRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SetRequest) GetCallId() uint64 {
 	if x != nil {
-		return x.UserId
+		return x.CallId
 	}
-	return ""
+	return 0
 }
 
-func (x *EventProto) GetChannelId() string {
+func (x *SetRequest) GetKey() string {
 	if x != nil {
-		return x.ChannelId
+		return x.Key
 	}
 	return ""
 }
 
-func (x *EventProto) GetMetadataJson() string {
+func (x *SetRequest) GetValue() *Value {
 	if x != nil {
-		return x.MetadataJson
+		return x.Value
 	}
-	return ""
+	return nil
 }
 
-type ResponseProto struct {
+type DeleteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	PluginName    string                 `protobuf:"bytes,1,opt,name=plugin_name,json=pluginName,proto3" json:"plugin_name,omitempty"`
-	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
-	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
-	DataJson      string                 `protobuf:"bytes,4,opt,name=data_json,json=dataJson,proto3" json:"data_json,omitempty"` // JSON serialized map
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ResponseProto) Reset() {
-	*x = ResponseProto{}
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[2]
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ResponseProto) String() string {
+func (x *DeleteRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResponseProto) ProtoMessage() {}
+func (*DeleteRequest) ProtoMessage() {}
 
-func (x *ResponseProto) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[2]
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -177,63 +1710,101 @@ func (x *ResponseProto) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResponseProto.ProtoReflect.Descriptor instead.
-func (*ResponseProto) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *ResponseProto) GetPluginName() string {
+func (x *DeleteRequest) GetCallId() uint64 {
 	if x != nil {
-		return x.PluginName
+		return x.CallId
 	}
-	return ""
+	return 0
 }
 
-func (x *ResponseProto) GetContent() string {
+func (x *DeleteRequest) GetKey() string {
 	if x != nil {
-		return x.Content
+		return x.Key
 	}
 	return ""
 }
 
-func (x *ResponseProto) GetType() string {
+type EmitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Event         *EventProto            `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmitRequest) Reset() {
+	*x = EmitRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmitRequest) ProtoMessage() {}
+
+func (x *EmitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[25]
 	if x != nil {
-		return x.Type
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *ResponseProto) GetDataJson() string {
+// Deprecated: Use EmitRequest.ProtoReflect.Descriptor instead.
+func (*EmitRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *EmitRequest) GetCallId() uint64 {
 	if x != nil {
-		return x.DataJson
+		return x.CallId
 	}
-	return ""
+	return 0
 }
 
-type ContextProto struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Event          *EventProto            `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
-	PropertiesJson string                 `protobuf:"bytes,2,opt,name=properties_json,json=propertiesJson,proto3" json:"properties_json,omitempty"` // JSON serialized map
-	Responses      []*ResponseProto       `protobuf:"bytes,3,rep,name=responses,proto3" json:"responses,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *EmitRequest) GetEvent() *EventProto {
+	if x != nil {
+		return x.Event
+	}
+	return nil
 }
 
-func (x *ContextProto) Reset() {
-	*x = ContextProto{}
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[3]
+type ProgressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Percent       float64                `protobuf:"fixed64,2,opt,name=percent,proto3" json:"percent,omitempty"` // 0 to 100
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProgressRequest) Reset() {
+	*x = ProgressRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ContextProto) String() string {
+func (x *ProgressRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContextProto) ProtoMessage() {}
+func (*ProgressRequest) ProtoMessage() {}
 
-func (x *ContextProto) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[3]
+func (x *ProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -244,55 +1815,58 @@ func (x *ContextProto) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContextProto.ProtoReflect.Descriptor instead.
-func (*ContextProto) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ProgressRequest.ProtoReflect.Descriptor instead.
+func (*ProgressRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *ContextProto) GetEvent() *EventProto {
+func (x *ProgressRequest) GetCallId() uint64 {
 	if x != nil {
-		return x.Event
+		return x.CallId
 	}
-	return nil
+	return 0
 }
 
-func (x *ContextProto) GetPropertiesJson() string {
+func (x *ProgressRequest) GetPercent() float64 {
 	if x != nil {
-		return x.PropertiesJson
+		return x.Percent
 	}
-	return ""
+	return 0
 }
 
-func (x *ContextProto) GetResponses() []*ResponseProto {
+func (x *ProgressRequest) GetMessage() string {
 	if x != nil {
-		return x.Responses
+		return x.Message
 	}
-	return nil
+	return ""
 }
 
-type ExecutionDecisionProto struct {
+// Blob describes an artifact held by the host and passed around by handle
+type Blob struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ShouldExecute bool                   `protobuf:"varint,1,opt,name=should_execute,json=shouldExecute,proto3" json:"should_execute,omitempty"`
-	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Handle        string                 `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecutionDecisionProto) Reset() {
-	*x = ExecutionDecisionProto{}
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[4]
+func (x *Blob) Reset() {
+	*x = Blob{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecutionDecisionProto) String() string {
+func (x *Blob) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecutionDecisionProto) ProtoMessage() {}
+func (*Blob) ProtoMessage() {}
 
-func (x *ExecutionDecisionProto) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[4]
+func (x *Blob) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -303,53 +1877,63 @@ func (x *ExecutionDecisionProto) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecutionDecisionProto.ProtoReflect.Descriptor instead.
-func (*ExecutionDecisionProto) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use Blob.ProtoReflect.Descriptor instead.
+func (*Blob) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *ExecutionDecisionProto) GetShouldExecute() bool {
+func (x *Blob) GetHandle() string {
 	if x != nil {
-		return x.ShouldExecute
+		return x.Handle
 	}
-	return false
+	return ""
 }
 
-func (x *ExecutionDecisionProto) GetReason() string {
+func (x *Blob) GetName() string {
 	if x != nil {
-		return x.Reason
+		return x.Name
 	}
 	return ""
 }
 
-type Metadata struct {
+func (x *Blob) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Blob) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type BlobChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	BuildTime     string                 `protobuf:"bytes,3,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"`
-	MinCliVersion string                 `protobuf:"bytes,4,opt,name=min_cli_version,json=minCliVersion,proto3" json:"min_cli_version,omitempty"`
-	MaxCliVersion string                 `protobuf:"bytes,5,opt,name=max_cli_version,json=maxCliVersion,proto3" json:"max_cli_version,omitempty"`
-	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
-	Priority      int32                  `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Info          *Blob                  `protobuf:"bytes,2,opt,name=info,proto3" json:"info,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Metadata) Reset() {
-	*x = Metadata{}
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[5]
+func (x *BlobChunk) Reset() {
+	*x = BlobChunk{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Metadata) String() string {
+func (x *BlobChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Metadata) ProtoMessage() {}
+func (*BlobChunk) ProtoMessage() {}
 
-func (x *Metadata) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_protocol_plugin_proto_msgTypes[5]
+func (x *BlobChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -360,66 +1944,115 @@ func (x *Metadata) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
-func (*Metadata) Descriptor() ([]byte, []int) {
-	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use BlobChunk.ProtoReflect.Descriptor instead.
+func (*BlobChunk) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *Metadata) GetName() string {
+func (x *BlobChunk) GetCallId() uint64 {
 	if x != nil {
-		return x.Name
+		return x.CallId
 	}
-	return ""
+	return 0
 }
 
-func (x *Metadata) GetVersion() string {
+func (x *BlobChunk) GetInfo() *Blob {
 	if x != nil {
-		return x.Version
+		return x.Info
 	}
-	return ""
+	return nil
 }
 
-func (x *Metadata) GetBuildTime() string {
+func (x *BlobChunk) GetData() []byte {
 	if x != nil {
-		return x.BuildTime
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-func (x *Metadata) GetMinCliVersion() string {
-	if x != nil {
-		return x.MinCliVersion
-	}
-	return ""
+type OpenBlobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Handle        string                 `protobuf:"bytes,2,opt,name=handle,proto3" json:"handle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Metadata) GetMaxCliVersion() string {
+func (x *OpenBlobRequest) Reset() {
+	*x = OpenBlobRequest{}
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenBlobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenBlobRequest) ProtoMessage() {}
+
+func (x *OpenBlobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_protocol_plugin_proto_msgTypes[29]
 	if x != nil {
-		return x.MaxCliVersion
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Metadata) GetDescription() string {
+// Deprecated: Use OpenBlobRequest.ProtoReflect.Descriptor instead.
+func (*OpenBlobRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_protocol_plugin_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *OpenBlobRequest) GetCallId() uint64 {
 	if x != nil {
-		return x.Description
+		return x.CallId
 	}
-	return ""
+	return 0
 }
 
-func (x *Metadata) GetPriority() int32 {
+func (x *OpenBlobRequest) GetHandle() string {
 	if x != nil {
-		return x.Priority
+		return x.Handle
 	}
-	return 0
+	return ""
 }
 
 var File_pkg_protocol_plugin_proto protoreflect.FileDescriptor
 
 const file_pkg_protocol_plugin_proto_rawDesc = "" +
 	"\n" +
-	"\x19pkg/protocol/plugin.proto\x12\x06shared\"\a\n" +
-	"\x05Empty\"\xaf\x01\n" +
+	"\x19pkg/protocol/plugin.proto\x12\x06shared\x1a\x1fgoogle/protobuf/timestamp.proto\"\a\n" +
+	"\x05Empty\"\xaa\x03\n" +
+	"\x05Value\x12\x1f\n" +
+	"\n" +
+	"null_value\x18\x01 \x01(\bH\x00R\tnullValue\x12\x1f\n" +
+	"\n" +
+	"bool_value\x18\x02 \x01(\bH\x00R\tboolValue\x12\x1d\n" +
+	"\tint_value\x18\x03 \x01(\x03H\x00R\bintValue\x12\x1f\n" +
+	"\n" +
+	"uint_value\x18\x04 \x01(\x04H\x00R\tuintValue\x12#\n" +
+	"\fdouble_value\x18\x05 \x01(\x01H\x00R\vdoubleValue\x12#\n" +
+	"\fstring_value\x18\x06 \x01(\tH\x00R\vstringValue\x12!\n" +
+	"\vbytes_value\x18\a \x01(\fH\x00R\n" +
+	"bytesValue\x12E\n" +
+	"\x0ftimestamp_value\x18\b \x01(\v2\x1a.google.protobuf.TimestampH\x00R\x0etimestampValue\x122\n" +
+	"\n" +
+	"list_value\x18\t \x01(\v2\x11.shared.ListValueH\x00R\tlistValue\x12/\n" +
+	"\tmap_value\x18\n" +
+	" \x01(\v2\x10.shared.MapValueH\x00R\bmapValueB\x06\n" +
+	"\x04kind\"2\n" +
+	"\tListValue\x12%\n" +
+	"\x06values\x18\x01 \x03(\v2\r.shared.ValueR\x06values\"\x8a\x01\n" +
+	"\bMapValue\x124\n" +
+	"\x06fields\x18\x01 \x03(\v2\x1c.shared.MapValue.FieldsEntryR\x06fields\x1aH\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value:\x028\x01\"\xe9\x02\n" +
 	"\n" +
 	"EventProto\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
@@ -428,20 +2061,45 @@ const file_pkg_protocol_plugin_proto_rawDesc = "" +
 	"\auser_id\x18\x04 \x01(\tR\x06userId\x12\x1d\n" +
 	"\n" +
 	"channel_id\x18\x05 \x01(\tR\tchannelId\x12#\n" +
-	"\rmetadata_json\x18\x06 \x01(\tR\fmetadataJson\"{\n" +
+	"\rmetadata_json\x18\x06 \x01(\tR\fmetadataJson\x12<\n" +
+	"\bmetadata\x18\a \x03(\v2 .shared.EventProto.MetadataEntryR\bmetadata\x12.\n" +
+	"\vattachments\x18\b \x03(\v2\f.shared.BlobR\vattachments\x1aJ\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value:\x028\x01\"\xf8\x01\n" +
 	"\rResponseProto\x12\x1f\n" +
 	"\vplugin_name\x18\x01 \x01(\tR\n" +
 	"pluginName\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\tR\acontent\x12\x12\n" +
 	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1b\n" +
-	"\tdata_json\x18\x04 \x01(\tR\bdataJson\"\x96\x01\n" +
+	"\tdata_json\x18\x04 \x01(\tR\bdataJson\x123\n" +
+	"\x04data\x18\x05 \x03(\v2\x1f.shared.ResponseProto.DataEntryR\x04data\x1aF\n" +
+	"\tDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value:\x028\x01\"V\n" +
+	"\fControlProto\x12\x16\n" +
+	"\x06signal\x18\x01 \x01(\tR\x06signal\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06plugin\x18\x03 \x01(\tR\x06plugin\"\xff\x02\n" +
 	"\fContextProto\x12(\n" +
 	"\x05event\x18\x01 \x01(\v2\x12.shared.EventProtoR\x05event\x12'\n" +
 	"\x0fproperties_json\x18\x02 \x01(\tR\x0epropertiesJson\x123\n" +
-	"\tresponses\x18\x03 \x03(\v2\x15.shared.ResponseProtoR\tresponses\"W\n" +
+	"\tresponses\x18\x03 \x03(\v2\x15.shared.ResponseProtoR\tresponses\x12.\n" +
+	"\acontrol\x18\x04 \x01(\v2\x14.shared.ControlProtoR\acontrol\x12D\n" +
+	"\n" +
+	"properties\x18\x05 \x03(\v2$.shared.ContextProto.PropertiesEntryR\n" +
+	"properties\x12#\n" +
+	"\x04host\x18\x06 \x01(\v2\x0f.shared.HostRefR\x04host\x1aL\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value:\x028\x01\"Y\n" +
+	"\aHostRef\x12\x1b\n" +
+	"\tbroker_id\x18\x01 \x01(\rR\bbrokerId\x12\x17\n" +
+	"\acall_id\x18\x02 \x01(\x04R\x06callId\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\"W\n" +
 	"\x16ExecutionDecisionProto\x12%\n" +
 	"\x0eshould_execute\x18\x01 \x01(\bR\rshouldExecute\x12\x16\n" +
-	"\x06reason\x18\x02 \x01(\tR\x06reason\"\xe5\x01\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\xb3\x04\n" +
 	"\bMetadata\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1d\n" +
@@ -450,11 +2108,121 @@ const file_pkg_protocol_plugin_proto_rawDesc = "" +
 	"\x0fmin_cli_version\x18\x04 \x01(\tR\rminCliVersion\x12&\n" +
 	"\x0fmax_cli_version\x18\x05 \x01(\tR\rmaxCliVersion\x12 \n" +
 	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x1a\n" +
-	"\bpriority\x18\a \x01(\x05R\bpriority2\xb6\x01\n" +
+	"\bpriority\x18\a \x01(\x05R\bpriority\x129\n" +
+	"\x19should_execute_timeout_ms\x18\b \x01(\x03R\x16shouldExecuteTimeoutMs\x12,\n" +
+	"\x12process_timeout_ms\x18\t \x01(\x03R\x10processTimeoutMs\x12\x1a\n" +
+	"\brequires\x18\n" +
+	" \x03(\tR\brequires\x12\x1a\n" +
+	"\bprovides\x18\v \x03(\tR\bprovides\x12:\n" +
+	"\bexamples\x18\f \x03(\v2\x1e.shared.Metadata.ExamplesEntryR\bexamples\x12#\n" +
+	"\rrequires_host\x18\r \x01(\bR\frequiresHost\x1aJ\n" +
+	"\rExamplesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value:\x028\x01\"\x9a\x01\n" +
+	"\x10ConfigureRequest\x12<\n" +
+	"\x06values\x18\x01 \x03(\v2$.shared.ConfigureRequest.ValuesEntryR\x06values\x1aH\n" +
+	"\vValuesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value:\x028\x01\"B\n" +
+	"\x0eHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x88\x01\n" +
+	"\aCommand\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05usage\x18\x02 \x01(\tR\x05usage\x12\x14\n" +
+	"\x05short\x18\x03 \x01(\tR\x05short\x12\x12\n" +
+	"\x04long\x18\x04 \x01(\tR\x04long\x12)\n" +
+	"\x05flags\x18\x05 \x03(\v2\x13.shared.CommandFlagR\x05flags\"\x8e\x01\n" +
+	"\vCommandFlag\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tshorthand\x18\x02 \x01(\tR\tshorthand\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12#\n" +
+	"\rdefault_value\x18\x04 \x01(\tR\fdefaultValue\x12\x14\n" +
+	"\x05usage\x18\x05 \x01(\tR\x05usage\":\n" +
+	"\vCommandList\x12+\n" +
+	"\bcommands\x18\x01 \x03(\v2\x0f.shared.CommandR\bcommands\"\xba\x01\n" +
+	"\x0eCommandRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x127\n" +
+	"\x05flags\x18\x03 \x03(\v2!.shared.CommandRequest.FlagsEntryR\x05flags\x1aG\n" +
+	"\n" +
+	"FlagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value:\x028\x01\"#\n" +
+	"\rCommandOutput\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"\"\n" +
+	"\n" +
+	"PluginList\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\"q\n" +
+	"\vErrorDetail\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1c\n" +
+	"\tretryable\x18\x03 \x01(\bR\tretryable\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\"\xd7\x01\n" +
+	"\n" +
+	"LogRequest\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\x04R\x06callId\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x126\n" +
+	"\x06fields\x18\x04 \x03(\v2\x1e.shared.LogRequest.FieldsEntryR\x06fields\x1aH\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value:\x028\x01\"7\n" +
+	"\n" +
+	"GetRequest\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\x04R\x06callId\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"H\n" +
+	"\vGetResponse\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\bR\x05found\x12#\n" +
+	"\x05value\x18\x02 \x01(\v2\r.shared.ValueR\x05value\"\\\n" +
+	"\n" +
+	"SetRequest\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\x04R\x06callId\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12#\n" +
+	"\x05value\x18\x03 \x01(\v2\r.shared.ValueR\x05value\":\n" +
+	"\rDeleteRequest\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\x04R\x06callId\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"P\n" +
+	"\vEmitRequest\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\x04R\x06callId\x12(\n" +
+	"\x05event\x18\x02 \x01(\v2\x12.shared.EventProtoR\x05event\"^\n" +
+	"\x0fProgressRequest\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\x04R\x06callId\x12\x18\n" +
+	"\apercent\x18\x02 \x01(\x01R\apercent\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"i\n" +
+	"\x04Blob\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\tR\x06handle\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"Z\n" +
+	"\tBlobChunk\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\x04R\x06callId\x12 \n" +
+	"\x04info\x18\x02 \x01(\v2\f.shared.BlobR\x04info\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"B\n" +
+	"\x0fOpenBlobRequest\x12\x17\n" +
+	"\acall_id\x18\x01 \x01(\x04R\x06callId\x12\x16\n" +
+	"\x06handle\x18\x02 \x01(\tR\x06handle2\x92\x04\n" +
 	"\x06Plugin\x12E\n" +
 	"\rShouldExecute\x12\x14.shared.ContextProto\x1a\x1e.shared.ExecutionDecisionProto\x125\n" +
 	"\aProcess\x12\x14.shared.ContextProto\x1a\x14.shared.ContextProto\x12.\n" +
-	"\vGetMetadata\x12\r.shared.Empty\x1a\x10.shared.MetadataB?Z=github.com/williamokano/hashicorp-plugin-example/pkg/protocolb\x06proto3"
+	"\vGetMetadata\x12\r.shared.Empty\x1a\x10.shared.Metadata\x124\n" +
+	"\tConfigure\x12\x18.shared.ConfigureRequest\x1a\r.shared.Empty\x12$\n" +
+	"\x04Init\x12\r.shared.Empty\x1a\r.shared.Empty\x12/\n" +
+	"\x06Health\x12\r.shared.Empty\x1a\x16.shared.HealthResponse\x12(\n" +
+	"\bShutdown\x12\r.shared.Empty\x1a\r.shared.Empty\x122\n" +
+	"\fListCommands\x12\r.shared.Empty\x1a\x13.shared.CommandList\x12=\n" +
+	"\n" +
+	"RunCommand\x12\x16.shared.CommandRequest\x1a\x15.shared.CommandOutput0\x01\x120\n" +
+	"\vListPlugins\x12\r.shared.Empty\x1a\x12.shared.PluginList2\x89\x03\n" +
+	"\vHostService\x12(\n" +
+	"\x03Log\x12\x12.shared.LogRequest\x1a\r.shared.Empty\x12.\n" +
+	"\x03Get\x12\x12.shared.GetRequest\x1a\x13.shared.GetResponse\x12(\n" +
+	"\x03Set\x12\x12.shared.SetRequest\x1a\r.shared.Empty\x12.\n" +
+	"\x06Delete\x12\x15.shared.DeleteRequest\x1a\r.shared.Empty\x12*\n" +
+	"\x04Emit\x12\x13.shared.EmitRequest\x1a\r.shared.Empty\x122\n" +
+	"\bProgress\x12\x17.shared.ProgressRequest\x1a\r.shared.Empty\x12,\n" +
+	"\aPutBlob\x12\x11.shared.BlobChunk\x1a\f.shared.Blob(\x01\x128\n" +
+	"\bOpenBlob\x12\x17.shared.OpenBlobRequest\x1a\x11.shared.BlobChunk0\x01B?Z=github.com/williamokano/hashicorp-plugin-example/pkg/protocolb\x06proto3"
 
 var (
 	file_pkg_protocol_plugin_proto_rawDescOnce sync.Once
@@ -468,29 +2236,121 @@ func file_pkg_protocol_plugin_proto_rawDescGZIP() []byte {
 	return file_pkg_protocol_plugin_proto_rawDescData
 }
 
-var file_pkg_protocol_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_pkg_protocol_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
 var file_pkg_protocol_plugin_proto_goTypes = []any{
 	(*Empty)(nil),                  // 0: shared.Empty
-	(*EventProto)(nil),             // 1: shared.EventProto
-	(*ResponseProto)(nil),          // 2: shared.ResponseProto
-	(*ContextProto)(nil),           // 3: shared.ContextProto
-	(*ExecutionDecisionProto)(nil), // 4: shared.ExecutionDecisionProto
-	(*Metadata)(nil),               // 5: shared.Metadata
+	(*Value)(nil),                  // 1: shared.Value
+	(*ListValue)(nil),              // 2: shared.ListValue
+	(*MapValue)(nil),               // 3: shared.MapValue
+	(*EventProto)(nil),             // 4: shared.EventProto
+	(*ResponseProto)(nil),          // 5: shared.ResponseProto
+	(*ControlProto)(nil),           // 6: shared.ControlProto
+	(*ContextProto)(nil),           // 7: shared.ContextProto
+	(*HostRef)(nil),                // 8: shared.HostRef
+	(*ExecutionDecisionProto)(nil), // 9: shared.ExecutionDecisionProto
+	(*Metadata)(nil),               // 10: shared.Metadata
+	(*ConfigureRequest)(nil),       // 11: shared.ConfigureRequest
+	(*HealthResponse)(nil),         // 12: shared.HealthResponse
+	(*Command)(nil),                // 13: shared.Command
+	(*CommandFlag)(nil),            // 14: shared.CommandFlag
+	(*CommandList)(nil),            // 15: shared.CommandList
+	(*CommandRequest)(nil),         // 16: shared.CommandRequest
+	(*CommandOutput)(nil),          // 17: shared.CommandOutput
+	(*PluginList)(nil),             // 18: shared.PluginList
+	(*ErrorDetail)(nil),            // 19: shared.ErrorDetail
+	(*LogRequest)(nil),             // 20: shared.LogRequest
+	(*GetRequest)(nil),             // 21: shared.GetRequest
+	(*GetResponse)(nil),            // 22: shared.GetResponse
+	(*SetRequest)(nil),             // 23: shared.SetRequest
+	(*DeleteRequest)(nil),          // 24: shared.DeleteRequest
+	(*EmitRequest)(nil),            // 25: shared.EmitRequest
+	(*ProgressRequest)(nil),        // 26: shared.ProgressRequest
+	(*Blob)(nil),                   // 27: shared.Blob
+	(*BlobChunk)(nil),              // 28: shared.BlobChunk
+	(*OpenBlobRequest)(nil),        // 29: shared.OpenBlobRequest
+	nil,                            // 30: shared.MapValue.FieldsEntry
+	nil,                            // 31: shared.EventProto.MetadataEntry
+	nil,                            // 32: shared.ResponseProto.DataEntry
+	nil,                            // 33: shared.ContextProto.PropertiesEntry
+	nil,                            // 34: shared.Metadata.ExamplesEntry
+	nil,                            // 35: shared.ConfigureRequest.ValuesEntry
+	nil,                            // 36: shared.CommandRequest.FlagsEntry
+	nil,                            // 37: shared.LogRequest.FieldsEntry
+	(*timestamppb.Timestamp)(nil),  // 38: google.protobuf.Timestamp
 }
 var file_pkg_protocol_plugin_proto_depIdxs = []int32{
-	1, // 0: shared.ContextProto.event:type_name -> shared.EventProto
-	2, // 1: shared.ContextProto.responses:type_name -> shared.ResponseProto
-	3, // 2: shared.Plugin.ShouldExecute:input_type -> shared.ContextProto
-	3, // 3: shared.Plugin.Process:input_type -> shared.ContextProto
-	0, // 4: shared.Plugin.GetMetadata:input_type -> shared.Empty
-	4, // 5: shared.Plugin.ShouldExecute:output_type -> shared.ExecutionDecisionProto
-	3, // 6: shared.Plugin.Process:output_type -> shared.ContextProto
-	5, // 7: shared.Plugin.GetMetadata:output_type -> shared.Metadata
-	5, // [5:8] is the sub-list for method output_type
-	2, // [2:5] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	38, // 0: shared.Value.timestamp_value:type_name -> google.protobuf.Timestamp
+	2,  // 1: shared.Value.list_value:type_name -> shared.ListValue
+	3,  // 2: shared.Value.map_value:type_name -> shared.MapValue
+	1,  // 3: shared.ListValue.values:type_name -> shared.Value
+	30, // 4: shared.MapValue.fields:type_name -> shared.MapValue.FieldsEntry
+	31, // 5: shared.EventProto.metadata:type_name -> shared.EventProto.MetadataEntry
+	27, // 6: shared.EventProto.attachments:type_name -> shared.Blob
+	32, // 7: shared.ResponseProto.data:type_name -> shared.ResponseProto.DataEntry
+	4,  // 8: shared.ContextProto.event:type_name -> shared.EventProto
+	5,  // 9: shared.ContextProto.responses:type_name -> shared.ResponseProto
+	6,  // 10: shared.ContextProto.control:type_name -> shared.ControlProto
+	33, // 11: shared.ContextProto.properties:type_name -> shared.ContextProto.PropertiesEntry
+	8,  // 12: shared.ContextProto.host:type_name -> shared.HostRef
+	34, // 13: shared.Metadata.examples:type_name -> shared.Metadata.ExamplesEntry
+	35, // 14: shared.ConfigureRequest.values:type_name -> shared.ConfigureRequest.ValuesEntry
+	14, // 15: shared.Command.flags:type_name -> shared.CommandFlag
+	13, // 16: shared.CommandList.commands:type_name -> shared.Command
+	36, // 17: shared.CommandRequest.flags:type_name -> shared.CommandRequest.FlagsEntry
+	37, // 18: shared.LogRequest.fields:type_name -> shared.LogRequest.FieldsEntry
+	1,  // 19: shared.GetResponse.value:type_name -> shared.Value
+	1,  // 20: shared.SetRequest.value:type_name -> shared.Value
+	4,  // 21: shared.EmitRequest.event:type_name -> shared.EventProto
+	27, // 22: shared.BlobChunk.info:type_name -> shared.Blob
+	1,  // 23: shared.MapValue.FieldsEntry.value:type_name -> shared.Value
+	1,  // 24: shared.EventProto.MetadataEntry.value:type_name -> shared.Value
+	1,  // 25: shared.ResponseProto.DataEntry.value:type_name -> shared.Value
+	1,  // 26: shared.ContextProto.PropertiesEntry.value:type_name -> shared.Value
+	1,  // 27: shared.Metadata.ExamplesEntry.value:type_name -> shared.Value
+	1,  // 28: shared.ConfigureRequest.ValuesEntry.value:type_name -> shared.Value
+	1,  // 29: shared.CommandRequest.FlagsEntry.value:type_name -> shared.Value
+	1,  // 30: shared.LogRequest.FieldsEntry.value:type_name -> shared.Value
+	7,  // 31: shared.Plugin.ShouldExecute:input_type -> shared.ContextProto
+	7,  // 32: shared.Plugin.Process:input_type -> shared.ContextProto
+	0,  // 33: shared.Plugin.GetMetadata:input_type -> shared.Empty
+	11, // 34: shared.Plugin.Configure:input_type -> shared.ConfigureRequest
+	0,  // 35: shared.Plugin.Init:input_type -> shared.Empty
+	0,  // 36: shared.Plugin.Health:input_type -> shared.Empty
+	0,  // 37: shared.Plugin.Shutdown:input_type -> shared.Empty
+	0,  // 38: shared.Plugin.ListCommands:input_type -> shared.Empty
+	16, // 39: shared.Plugin.RunCommand:input_type -> shared.CommandRequest
+	0,  // 40: shared.Plugin.ListPlugins:input_type -> shared.Empty
+	20, // 41: shared.HostService.Log:input_type -> shared.LogRequest
+	21, // 42: shared.HostService.Get:input_type -> shared.GetRequest
+	23, // 43: shared.HostService.Set:input_type -> shared.SetRequest
+	24, // 44: shared.HostService.Delete:input_type -> shared.DeleteRequest
+	25, // 45: shared.HostService.Emit:input_type -> shared.EmitRequest
+	26, // 46: shared.HostService.Progress:input_type -> shared.ProgressRequest
+	28, // 47: shared.HostService.PutBlob:input_type -> shared.BlobChunk
+	29, // 48: shared.HostService.OpenBlob:input_type -> shared.OpenBlobRequest
+	9,  // 49: shared.Plugin.ShouldExecute:output_type -> shared.ExecutionDecisionProto
+	7,  // 50: shared.Plugin.Process:output_type -> shared.ContextProto
+	10, // 51: shared.Plugin.GetMetadata:output_type -> shared.Metadata
+	0,  // 52: shared.Plugin.Configure:output_type -> shared.Empty
+	0,  // 53: shared.Plugin.Init:output_type -> shared.Empty
+	12, // 54: shared.Plugin.Health:output_type -> shared.HealthResponse
+	0,  // 55: shared.Plugin.Shutdown:output_type -> shared.Empty
+	15, // 56: shared.Plugin.ListCommands:output_type -> shared.CommandList
+	17, // 57: shared.Plugin.RunCommand:output_type -> shared.CommandOutput
+	18, // 58: shared.Plugin.ListPlugins:output_type -> shared.PluginList
+	0,  // 59: shared.HostService.Log:output_type -> shared.Empty
+	22, // 60: shared.HostService.Get:output_type -> shared.GetResponse
+	0,  // 61: shared.HostService.Set:output_type -> shared.Empty
+	0,  // 62: shared.HostService.Delete:output_type -> shared.Empty
+	0,  // 63: shared.HostService.Emit:output_type -> shared.Empty
+	0,  // 64: shared.HostService.Progress:output_type -> shared.Empty
+	27, // 65: shared.HostService.PutBlob:output_type -> shared.Blob
+	28, // 66: shared.HostService.OpenBlob:output_type -> shared.BlobChunk
+	49, // [49:67] is the sub-list for method output_type
+	31, // [31:49] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
 }
 
 func init() { file_pkg_protocol_plugin_proto_init() }
@@ -498,15 +2358,27 @@ func file_pkg_protocol_plugin_proto_init() {
 	if File_pkg_protocol_plugin_proto != nil {
 		return
 	}
+	file_pkg_protocol_plugin_proto_msgTypes[1].OneofWrappers = []any{
+		(*Value_NullValue)(nil),
+		(*Value_BoolValue)(nil),
+		(*Value_IntValue)(nil),
+		(*Value_UintValue)(nil),
+		(*Value_DoubleValue)(nil),
+		(*Value_StringValue)(nil),
+		(*Value_BytesValue)(nil),
+		(*Value_TimestampValue)(nil),
+		(*Value_ListValue)(nil),
+		(*Value_MapValue)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_protocol_plugin_proto_rawDesc), len(file_pkg_protocol_plugin_proto_rawDesc)), //nolint:gosec // G103: generated protobuf code
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   38,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_pkg_protocol_plugin_proto_goTypes,
 		DependencyIndexes: file_pkg_protocol_plugin_proto_depIdxs,
@@ -0,0 +1,94 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/hashicorp/go-plugin"
+)
+
+// EnvReattach holds the plugins the CLI attaches to instead of starting them,
+// as a JSON object of ReattachConfig keyed by plugin name. Plugins started
+// with --debug print the value to use:
+//
+//	PLUGIN_REATTACH='{"converter": {"protocol": "grpc", "protocol_version": 2, "network": "unix", "address": "/tmp/plugin123", "pid": 4242}}'
+const EnvReattach = "PLUGIN_REATTACH"
+
+// ReattachConfig is the JSON form of go-plugin's reattach config for a plugin
+// serving in debug mode
+type ReattachConfig struct {
+	Protocol        string `json:"protocol"`
+	ProtocolVersion int    `json:"protocol_version"`
+	Network         string `json:"network"`
+	Address         string `json:"address"`
+	Pid             int    `json:"pid"`
+}
+
+// NewReattachConfig converts the config go-plugin reports when serving
+func NewReattachConfig(c *plugin.ReattachConfig) ReattachConfig {
+	return ReattachConfig{
+		Protocol:        string(c.Protocol),
+		ProtocolVersion: c.ProtocolVersion,
+		Network:         c.Addr.Network(),
+		Address:         c.Addr.String(),
+		Pid:             c.Pid,
+	}
+}
+
+// ClientConfig returns the config for plugin.ClientConfig.Reattach. The
+// plugin is in go-plugin's test mode, so killing the client leaves the
+// process running for the next CLI run.
+func (c ReattachConfig) ClientConfig() (*plugin.ReattachConfig, error) {
+	if c.Protocol != string(plugin.ProtocolGRPC) {
+		return nil, fmt.Errorf("unsupported plugin protocol %q", c.Protocol)
+	}
+	if _, ok := AttachedPlugins[c.ProtocolVersion]; !ok {
+		return nil, fmt.Errorf("unsupported protocol version %d", c.ProtocolVersion)
+	}
+	if c.Pid <= 0 {
+		return nil, fmt.Errorf("no plugin pid")
+	}
+
+	var addr net.Addr
+	var err error
+	switch c.Network {
+	case "unix":
+		addr, err = net.ResolveUnixAddr(c.Network, c.Address)
+	case "tcp":
+		addr, err = net.ResolveTCPAddr(c.Network, c.Address)
+	default:
+		return nil, fmt.Errorf("unsupported plugin network %q", c.Network)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin address %q: %w", c.Address, err)
+	}
+
+	return &plugin.ReattachConfig{
+		Protocol:        plugin.ProtocolGRPC,
+		ProtocolVersion: c.ProtocolVersion,
+		Addr:            addr,
+		Pid:             c.Pid,
+		Test:            true,
+	}, nil
+}
+
+// ReattachFromEnv returns the plugins in EnvReattach, or nil if it is unset
+func ReattachFromEnv() (map[string]ReattachConfig, error) {
+	raw := os.Getenv(EnvReattach)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var configs map[string]ReattachConfig
+	if err := json.Unmarshal([]byte(raw), &configs); err != nil {
+		return nil, fmt.Errorf("%s is not a JSON object of plugins: %w", EnvReattach, err)
+	}
+	for name, c := range configs {
+		if _, err := c.ClientConfig(); err != nil {
+			return nil, fmt.Errorf("%s: plugin %s: %w", EnvReattach, name, err)
+		}
+	}
+	return configs, nil
+}
@@ -0,0 +1,90 @@
+package protocol
+
+import (
+	"net"
+	"testing"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReattachFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    map[string]ReattachConfig
+		wantErr string
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name: "unix socket",
+			env:  `{"converter": {"protocol": "grpc", "protocol_version": 2, "network": "unix", "address": "/tmp/plugin42", "pid": 42}}`,
+			want: map[string]ReattachConfig{
+				"converter": {Protocol: "grpc", ProtocolVersion: 2, Network: "unix", Address: "/tmp/plugin42", Pid: 42},
+			},
+		},
+		{
+			name: "tcp",
+			env:  `{"converter": {"protocol": "grpc", "protocol_version": 2, "network": "tcp", "address": "127.0.0.1:7000", "pid": 42}}`,
+			want: map[string]ReattachConfig{
+				"converter": {Protocol: "grpc", ProtocolVersion: 2, Network: "tcp", Address: "127.0.0.1:7000", Pid: 42},
+			},
+		},
+		{
+			name:    "not an object",
+			env:     `["converter"]`,
+			wantErr: "PLUGIN_REATTACH is not a JSON object of plugins: json: cannot unmarshal array into Go value of type map[string]protocol.ReattachConfig",
+		},
+		{
+			name:    "net/rpc",
+			env:     `{"converter": {"protocol": "netrpc", "protocol_version": 2, "network": "unix", "address": "/tmp/plugin42", "pid": 42}}`,
+			wantErr: `PLUGIN_REATTACH: plugin converter: unsupported plugin protocol "netrpc"`,
+		},
+		{
+			name:    "unknown protocol version",
+			env:     `{"converter": {"protocol": "grpc", "protocol_version": 9, "network": "unix", "address": "/tmp/plugin42", "pid": 42}}`,
+			wantErr: "PLUGIN_REATTACH: plugin converter: unsupported protocol version 9",
+		},
+		{
+			name:    "no pid",
+			env:     `{"converter": {"protocol": "grpc", "protocol_version": 2, "network": "unix", "address": "/tmp/plugin42"}}`,
+			wantErr: "PLUGIN_REATTACH: plugin converter: no plugin pid",
+		},
+		{
+			name:    "unknown network",
+			env:     `{"converter": {"protocol": "grpc", "protocol_version": 2, "network": "udp", "address": "127.0.0.1:7000", "pid": 42}}`,
+			wantErr: `PLUGIN_REATTACH: plugin converter: unsupported plugin network "udp"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvReattach, tt.env)
+
+			got, err := ReattachFromEnv()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReattachConfig_RoundTrip(t *testing.T) {
+	served := &plugin.ReattachConfig{
+		Protocol:        plugin.ProtocolGRPC,
+		ProtocolVersion: LatestProtocolVersion,
+		Addr:            &net.UnixAddr{Name: "/tmp/plugin42", Net: "unix"},
+		Pid:             42,
+		Test:            true,
+	}
+
+	got, err := NewReattachConfig(served).ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, served, got)
+}
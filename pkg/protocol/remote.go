@@ -0,0 +1,181 @@
+package protocol
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Remote plugins are served as a long-running service instead of a
+// subprocess of the CLI. Many CLIs share one instance, so they speak the
+// latest protocol version over a plain gRPC connection, without go-plugin's
+// handshake or broker. A plugin served on a unix socket runs on the CLI's
+// machine, so the CLI offers it host services on a socket of its own, named
+// in each request's HostRef. One served over TCP may not be able to reach
+// the CLI and gets no host services: every host call fails with
+// types.ErrNoHost, and Dial refuses plugins declaring they require them.
+
+// ParseAddress splits a remote plugin address into a network and address for
+// net.Listen. Addresses are unix:///path/to.sock, tcp://host:port or host:port.
+func ParseAddress(addr string) (network, address string, err error) {
+	switch {
+	case strings.HasPrefix(addr, "unix://"):
+		network, address = "unix", strings.TrimPrefix(addr, "unix://")
+	case strings.HasPrefix(addr, "tcp://"):
+		network, address = "tcp", strings.TrimPrefix(addr, "tcp://")
+	case strings.Contains(addr, "://"):
+		return "", "", fmt.Errorf("unsupported plugin address %q: use unix:// or tcp://", addr)
+	default:
+		network, address = "tcp", addr
+	}
+
+	if address == "" {
+		return "", "", fmt.Errorf("plugin address %q has no %s address", addr, network)
+	}
+	if network == "tcp" {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return "", "", fmt.Errorf("invalid plugin address %q: %w", addr, err)
+		}
+	}
+	return network, address, nil
+}
+
+// RemoteClient is a plugin served at a network address. It implements the
+// same interfaces as the client of a plugin subprocess.
+type RemoteClient struct {
+	*GRPCClient
+	conn *grpc.ClientConn
+}
+
+// Dial connects to the plugin served at addr, over TLS when tlsConfig is not
+// nil, and fetches its metadata. A plugin that cannot be reached fails here
+// rather than on the first event.
+func Dial(ctx context.Context, addr string, tlsConfig *tls.Config) (*RemoteClient, error) {
+	network, address, err := ParseAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	target := address
+	if network == "unix" {
+		target = "unix:" + address
+	}
+
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin at %s: %w", addr, err)
+	}
+
+	client, err := newGRPCClient(ctx, NewPluginClient(conn), LatestProtocolVersion)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("plugin at %s: %w", addr, err)
+	}
+	if network == "unix" {
+		client.host = &hostBroker{listen: true}
+	} else if client.Dependencies().RequiresHost {
+		_ = conn.Close()
+		return nil, fmt.Errorf("plugin %s at %s needs host services, which remote plugins only get on a unix:// address", client.Name(), addr)
+	}
+	return &RemoteClient{GRPCClient: client, conn: conn}, nil
+}
+
+// Close stops the host services and closes the connection; the plugin keeps
+// running for other CLIs
+func (c *RemoteClient) Close() error {
+	return errors.Join(c.GRPCClient.Close(), c.conn.Close())
+}
+
+// NewRemoteServer returns a gRPC server offering impl to remote CLIs. The
+// plugin's lifecycle belongs to the process serving it, so the server
+// refuses the Configure, Init and Shutdown calls CLIs make to plugins they
+// start themselves. The plugin gets no host services: a HostRef in a request
+// is ignored, as the server may listen where anyone can send one.
+func NewRemoteServer(impl types.VersionedPlugin, opts ...grpc.ServerOption) *grpc.Server {
+	return newRemoteServer(impl, nil, opts...)
+}
+
+// newRemoteServer is NewRemoteServer reaching host services through host
+func newRemoteServer(impl types.VersionedPlugin, host *hostDialer, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	RegisterPluginServer(s, &remoteServer{GRPCServer: &GRPCServer{Impl: impl, version: LatestProtocolVersion, host: host}})
+	return s
+}
+
+// ServeRemote serves impl at addr until ctx is cancelled, then waits for
+// in-flight calls to finish. A stale unix socket left by an earlier run is
+// replaced. Only a plugin served on a unix socket gets host services, from
+// CLIs on unix sockets of their own.
+func ServeRemote(ctx context.Context, impl types.VersionedPlugin, addr string, tlsConfig *tls.Config) error {
+	network, address, err := ParseAddress(addr)
+	if err != nil {
+		return err
+	}
+
+	if network == "unix" {
+		if info, err := os.Lstat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(address)
+		}
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	var host *hostDialer
+	if network == "unix" {
+		host = &hostDialer{remote: true}
+	}
+	server := newRemoteServer(impl, host, opts...)
+
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("failed to serve plugin on %s: %w", addr, err)
+	}
+	return nil
+}
+
+// remoteServer is the Plugin service of a plugin shared by many CLIs
+type remoteServer struct {
+	*GRPCServer
+}
+
+func (s *remoteServer) Configure(ctx context.Context, req *ConfigureRequest) (*Empty, error) {
+	if len(req.GetValues()) > 0 {
+		return nil, status.Error(codes.Unimplemented, "remote plugins are configured where they run")
+	}
+	return &Empty{}, nil
+}
+
+func (s *remoteServer) Init(ctx context.Context, req *Empty) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "remote plugins are initialized where they run")
+}
+
+func (s *remoteServer) Shutdown(ctx context.Context, req *Empty) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "remote plugins are shut down where they run")
+}
@@ -0,0 +1,337 @@
+package protocol
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// hostProbe records whether host services were offered to it
+type hostProbe struct{ countingPlugin }
+
+func (hostProbe) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	host, _ := types.HostFrom(ctx)
+	_, _, err := host.Get("key")
+	c.Properties["no_host"] = errors.Is(err, types.ErrNoHost)
+	return c, nil
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		addr        string
+		wantNetwork string
+		wantAddress string
+		wantErr     bool
+	}{
+		{addr: "unix:///run/plugins/converter.sock", wantNetwork: "unix", wantAddress: "/run/plugins/converter.sock"},
+		{addr: "tcp://media.internal:7000", wantNetwork: "tcp", wantAddress: "media.internal:7000"},
+		{addr: "10.0.0.5:7000", wantNetwork: "tcp", wantAddress: "10.0.0.5:7000"},
+		{addr: "[::1]:7000", wantNetwork: "tcp", wantAddress: "[::1]:7000"},
+		{addr: "media.internal", wantErr: true},
+		{addr: "unix://", wantErr: true},
+		{addr: "http://media.internal:7000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			network, address, err := ParseAddress(tt.addr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantNetwork, network)
+			assert.Equal(t, tt.wantAddress, address)
+		})
+	}
+}
+
+func TestServeRemote_UnixSocket(t *testing.T) {
+	addr := "unix://" + filepath.Join(t.TempDir(), "plugin.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- ServeRemote(ctx, hostProbe{}, addr, nil) }()
+
+	var client *RemoteClient
+	require.Eventually(t, func() bool {
+		var err error
+		client, err = Dial(context.Background(), addr, nil)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "counting", client.Name())
+	assert.Equal(t, LatestProtocolVersion, client.ProtocolVersion())
+
+	result, err := client.Process(context.Background(), &types.Context{Properties: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Properties["no_host"])
+
+	// The plugin runs on the CLI's machine, so it reaches the host services
+	host := &recordingHost{store: map[string]interface{}{}}
+	result, err = client.Process(types.WithHost(context.Background(), host), &types.Context{Properties: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, false, result.Properties["no_host"])
+
+	// The lifecycle belongs to whoever serves the plugin, not to each CLI
+	assert.NoError(t, client.Configure(context.Background(), map[string]interface{}{}))
+	assert.ErrorIs(t, client.Configure(context.Background(), map[string]interface{}{"bucket": "media"}), ErrNotConfigurable)
+	assert.NoError(t, client.Init(context.Background()))
+	assert.NoError(t, client.Shutdown(context.Background()))
+
+	// Closing the client leaves the plugin serving others
+	require.NoError(t, client.Close())
+	other, err := Dial(context.Background(), addr, nil)
+	require.NoError(t, err)
+	require.NoError(t, other.Close())
+
+	cancel()
+	require.NoError(t, <-served)
+}
+
+func TestServeRemote_TCPHasNoHost(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := NewRemoteServer(hostProbe{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	client, err := Dial(context.Background(), listener.Addr().String(), nil)
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	host := &recordingHost{store: map[string]interface{}{}}
+	result, err := client.Process(types.WithHost(context.Background(), host), &types.Context{Properties: map[string]interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Properties["no_host"])
+}
+
+func TestServeRemote_HostAddress(t *testing.T) {
+	// bait counts the connections a plugin is talked into opening
+	bait, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = bait.Close() }()
+	var connections atomic.Int32
+	go func() {
+		for {
+			conn, err := bait.Accept()
+			if err != nil {
+				return
+			}
+			connections.Add(1)
+			_ = conn.Close()
+		}
+	}()
+
+	process := func(t *testing.T, conn *grpc.ClientConn) (*ContextProto, error) {
+		req, err := ContextToProtoVersion(&types.Context{Properties: map[string]interface{}{}}, LatestProtocolVersion)
+		require.NoError(t, err)
+		req.Host = &HostRef{CallId: 1, Address: bait.Addr().String()}
+		return NewPluginClient(conn).Process(context.Background(), req)
+	}
+
+	t.Run("tcp listener ignores it", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		server := NewRemoteServer(hostProbe{})
+		go func() { _ = server.Serve(listener) }()
+		defer server.Stop()
+
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		resp, err := process(t, conn)
+		require.NoError(t, err)
+		result, err := ProtoToContext(resp)
+		require.NoError(t, err)
+		assert.Equal(t, true, result.Properties["no_host"])
+	})
+
+	t.Run("unix listener rejects a tcp address", func(t *testing.T) {
+		addr := "unix://" + filepath.Join(t.TempDir(), "plugin.sock")
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() { _ = ServeRemote(ctx, hostProbe{}, addr, nil) }()
+
+		conn, err := grpc.NewClient("unix:"+strings.TrimPrefix(addr, "unix://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		// Calls fail until the plugin is listening, then for the address
+		assert.Eventually(t, func() bool {
+			_, err := process(t, conn)
+			return err != nil && strings.Contains(err.Error(), "is not a unix socket")
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	assert.Zero(t, connections.Load())
+}
+
+// blobPlugin cannot work without host services
+type blobPlugin struct{ countingPlugin }
+
+func (blobPlugin) Dependencies() types.Dependencies {
+	return types.Dependencies{RequiresHost: true}
+}
+
+func TestDial_RequiresHost(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := NewRemoteServer(blobPlugin{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	_, err = Dial(context.Background(), listener.Addr().String(), nil)
+	assert.ErrorContains(t, err, "needs host services, which remote plugins only get on a unix:// address")
+
+	// On a unix socket it gets them
+	addr := "unix://" + filepath.Join(t.TempDir(), "plugin.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = ServeRemote(ctx, blobPlugin{}, addr, nil) }()
+
+	var client *RemoteClient
+	require.Eventually(t, func() bool {
+		client, err = Dial(context.Background(), addr, nil)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, client.Dependencies().RequiresHost)
+	require.NoError(t, client.Close())
+}
+
+func TestDial_Unreachable(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := Dial(ctx, "unix://"+filepath.Join(t.TempDir(), "missing.sock"), nil)
+	assert.Error(t, err)
+}
+
+func TestDial_MutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverTLS := &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "plugin")},
+		ClientCAs:    ca.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := NewRemoteServer(countingPlugin{}, grpc.Creds(credentials.NewTLS(serverTLS)))
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	addr := listener.Addr().String()
+	tests := []struct {
+		name    string
+		tls     *tls.Config
+		wantErr bool
+	}{
+		{
+			name: "trusted client certificate",
+			tls:  &tls.Config{RootCAs: ca.pool, ServerName: "plugin", Certificates: []tls.Certificate{ca.issue(t, "cli")}, MinVersion: tls.VersionTLS12},
+		},
+		{
+			name:    "no client certificate",
+			tls:     &tls.Config{RootCAs: ca.pool, ServerName: "plugin", MinVersion: tls.VersionTLS12},
+			wantErr: true,
+		},
+		{
+			name:    "untrusted server",
+			tls:     &tls.Config{ServerName: "plugin", Certificates: []tls.Certificate{ca.issue(t, "cli")}, MinVersion: tls.VersionTLS12},
+			wantErr: true,
+		},
+		{
+			name:    "plaintext",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			client, err := Dial(ctx, addr, tt.tls)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer func() { _ = client.Close() }()
+			assert.Equal(t, "counting", client.Name())
+		})
+	}
+}
+
+// testCA issues certificates for TLS tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns a certificate for name, valid for both servers and clients
+func (ca *testCA) issue(t *testing.T, name string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
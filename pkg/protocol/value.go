@@ -0,0 +1,184 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToValue converts a Go value to its wire form. Integers keep their
+// precision, []byte and time.Time travel natively, and any other type
+// (such as a struct) is sent through its JSON encoding.
+func ToValue(v interface{}) (*Value, error) {
+	switch val := v.(type) {
+	case nil:
+		return &Value{Kind: &Value_NullValue{NullValue: true}}, nil
+	case bool:
+		return &Value{Kind: &Value_BoolValue{BoolValue: val}}, nil
+	case string:
+		return &Value{Kind: &Value_StringValue{StringValue: val}}, nil
+	case []byte:
+		return &Value{Kind: &Value_BytesValue{BytesValue: val}}, nil
+	case time.Time:
+		return &Value{Kind: &Value_TimestampValue{TimestampValue: timestamppb.New(val)}}, nil
+	case json.Number:
+		return ToValue(types.NormalizeNumbers(val))
+	case map[string]interface{}:
+		fields, err := ToValueMap(val)
+		if err != nil {
+			return nil, err
+		}
+		return &Value{Kind: &Value_MapValue{MapValue: &MapValue{Fields: fields}}}, nil
+	case []interface{}:
+		return toListValue(len(val), func(i int) interface{} { return val[i] })
+	}
+
+	// Named and less common types, by kind
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Value{Kind: &Value_IntValue{IntValue: rv.Int()}}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Value{Kind: &Value_UintValue{UintValue: rv.Uint()}}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Value{Kind: &Value_DoubleValue{DoubleValue: rv.Float()}}, nil
+	case reflect.Bool:
+		return &Value{Kind: &Value_BoolValue{BoolValue: rv.Bool()}}, nil
+	case reflect.String:
+		return &Value{Kind: &Value_StringValue{StringValue: rv.String()}}, nil
+	case reflect.Slice, reflect.Array:
+		return toListValue(rv.Len(), func(i int) interface{} { return rv.Index(i).Interface() })
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			fields := make(map[string]*Value, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				value, err := ToValue(iter.Value().Interface())
+				if err != nil {
+					return nil, fmt.Errorf("key %q: %w", iter.Key().String(), err)
+				}
+				fields[iter.Key().String()] = value
+			}
+			return &Value{Kind: &Value_MapValue{MapValue: &MapValue{Fields: fields}}}, nil
+		}
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return ToValue(nil)
+		}
+	}
+
+	return jsonToValue(v)
+}
+
+// FromValue converts a wire value back to Go. Integers come back as int64
+// (uint64 for unsigned), timestamps as time.Time, lists as []interface{}
+// and maps as map[string]interface{}.
+func FromValue(v *Value) (interface{}, error) {
+	switch kind := v.GetKind().(type) {
+	case nil, *Value_NullValue:
+		return nil, nil
+	case *Value_BoolValue:
+		return kind.BoolValue, nil
+	case *Value_IntValue:
+		return kind.IntValue, nil
+	case *Value_UintValue:
+		return kind.UintValue, nil
+	case *Value_DoubleValue:
+		return kind.DoubleValue, nil
+	case *Value_StringValue:
+		return kind.StringValue, nil
+	case *Value_BytesValue:
+		return kind.BytesValue, nil
+	case *Value_TimestampValue:
+		if err := kind.TimestampValue.CheckValid(); err != nil {
+			return nil, err
+		}
+		return kind.TimestampValue.AsTime(), nil
+	case *Value_ListValue:
+		list := make([]interface{}, len(kind.ListValue.GetValues()))
+		for i, item := range kind.ListValue.GetValues() {
+			value, err := FromValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			list[i] = value
+		}
+		return list, nil
+	case *Value_MapValue:
+		return FromValueMap(kind.MapValue.GetFields())
+	default:
+		return nil, fmt.Errorf("unsupported value kind %T", kind)
+	}
+}
+
+// ToValueMap converts a Go map to its wire form; a nil map stays nil
+func ToValueMap(m map[string]interface{}) (map[string]*Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	fields := make(map[string]*Value, len(m))
+	for k, v := range m {
+		value, err := ToValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		fields[k] = value
+	}
+	return fields, nil
+}
+
+// FromValueMap converts a wire map back to Go
+func FromValueMap(fields map[string]*Value) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		value, err := FromValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		m[k] = value
+	}
+	return m, nil
+}
+
+func toListValue(n int, item func(int) interface{}) (*Value, error) {
+	values := make([]*Value, n)
+	for i := range values {
+		value, err := ToValue(item(i))
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		values[i] = value
+	}
+	return &Value{Kind: &Value_ListValue{ListValue: &ListValue{Values: values}}}, nil
+}
+
+// jsonToValue sends a value the wire format has no type for through JSON
+func jsonToValue(v interface{}) (*Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value of type %T: %w", v, err)
+	}
+
+	decoded, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return ToValue(decoded)
+}
+
+// decodeJSON unmarshals JSON keeping integers as int64 rather than float64
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return types.NormalizeNumbers(v), nil
+}
@@ -0,0 +1,137 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestValue_RoundTrip(t *testing.T) {
+	stamp := time.Date(2024, 5, 1, 12, 30, 0, 123, time.UTC)
+
+	type details struct {
+		Codec    string `json:"codec"`
+		Duration int    `json:"duration"`
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{name: "nil", value: nil, want: nil},
+		{name: "bool", value: true, want: true},
+		{name: "string", value: "hello", want: "hello"},
+		{name: "int keeps integer type", value: 42, want: int64(42)},
+		{name: "large int64", value: int64(1<<62 + 1), want: int64(1<<62 + 1)},
+		{name: "unix timestamp", value: int64(1714566600), want: int64(1714566600)},
+		{name: "uint64", value: uint64(1<<64 - 1), want: uint64(1<<64 - 1)},
+		{name: "float", value: 1.5, want: 1.5},
+		{name: "duration by kind", value: 2 * time.Second, want: int64(2 * time.Second)},
+		{name: "bytes", value: []byte{0, 1, 2, 255}, want: []byte{0, 1, 2, 255}},
+		{name: "timestamp", value: stamp, want: stamp},
+		{name: "string slice", value: []string{"a", "b"}, want: []interface{}{"a", "b"}},
+		{
+			name:  "nested map",
+			value: map[string]interface{}{"n": 1, "list": []interface{}{int64(2), "x"}},
+			want:  map[string]interface{}{"n": int64(1), "list": []interface{}{int64(2), "x"}},
+		},
+		{name: "typed map", value: map[string]int{"a": 1}, want: map[string]interface{}{"a": int64(1)}},
+		{
+			name:  "struct through JSON",
+			value: details{Codec: "h264", Duration: 120},
+			want:  map[string]interface{}{"codec": "h264", "duration": int64(120)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wire, err := ToValue(tt.value)
+			require.NoError(t, err)
+
+			got, err := FromValue(wire)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValue_Unsupported(t *testing.T) {
+	_, err := ToValue(make(chan int))
+	assert.ErrorContains(t, err, "unsupported value of type chan int")
+
+	_, err = ToValueMap(map[string]interface{}{"callback": func() {}})
+	assert.ErrorContains(t, err, `key "callback"`)
+}
+
+func TestConverter_ReturnsErrors(t *testing.T) {
+	_, err := ContextToProto(&types.Context{
+		Properties: map[string]interface{}{"bad": make(chan int)},
+	})
+	assert.ErrorContains(t, err, "failed to encode properties")
+
+	_, err = ProtoToContext(&ContextProto{
+		Event:          &EventProto{Type: "message"},
+		PropertiesJson: "not json",
+	})
+	assert.ErrorContains(t, err, "failed to decode properties")
+
+	_, err = ProtoToContext(&ContextProto{
+		Event: &EventProto{Type: "message", MetadataJson: "[1, 2]"},
+	})
+	assert.ErrorContains(t, err, "failed to decode event metadata: expected a JSON object")
+}
+
+func TestConverter_TypedValues(t *testing.T) {
+	stamp := time.Unix(1714566600, 0).UTC()
+	original := &types.Context{
+		Event: types.Event{Type: types.EventMessage, Metadata: map[string]interface{}{"attempt": 3}},
+		Properties: map[string]interface{}{
+			"upload_timestamp": stamp.Unix(),
+			"uploaded_at":      stamp,
+			"checksum":         []byte{0xde, 0xad},
+		},
+		Responses: []types.Response{{PluginName: "uploader", Data: map[string]interface{}{"size": int64(1 << 40)}}},
+	}
+
+	proto, err := ContextToProto(original)
+	require.NoError(t, err)
+	result, err := ProtoToContext(proto)
+	require.NoError(t, err)
+
+	assert.Equal(t, stamp.Unix(), result.Properties["upload_timestamp"])
+	assert.Equal(t, stamp, result.Properties["uploaded_at"])
+	assert.Equal(t, []byte{0xde, 0xad}, result.Properties["checksum"])
+	assert.Equal(t, int64(3), result.Event.Metadata["attempt"])
+	assert.Equal(t, int64(1<<40), result.Responses[0].Data["size"])
+}
+
+func TestConverter_LegacyPlugins(t *testing.T) {
+	// A plugin built before typed values only reads and writes the JSON fields
+	original := &types.Context{
+		Event:      types.Event{Type: types.EventMessage, Metadata: map[string]interface{}{"k": "v"}},
+		Properties: map[string]interface{}{"count": 7},
+	}
+
+	proto, err := ContextToProto(original)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count": 7}`, proto.PropertiesJson)
+	assert.JSONEq(t, `{"k": "v"}`, proto.Event.MetadataJson)
+
+	legacyReply := &ContextProto{
+		Event:          &EventProto{Type: "message", MetadataJson: proto.Event.MetadataJson},
+		PropertiesJson: `{"count": 7, "upload_timestamp": 1714566600, "ratio": 0.5}`,
+		Responses:      []*ResponseProto{{PluginName: "old", DataJson: `{"n": 1}`}},
+	}
+
+	result, err := ProtoToContext(legacyReply)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), result.Properties["count"])
+	assert.Equal(t, int64(1714566600), result.Properties["upload_timestamp"])
+	assert.Equal(t, 0.5, result.Properties["ratio"])
+	assert.Equal(t, "v", result.Event.Metadata["k"])
+	assert.Equal(t, int64(1), result.Responses[0].Data["n"])
+}
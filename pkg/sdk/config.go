@@ -0,0 +1,86 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Config is the configuration a plugin receives in Configure. Its getters
+// return the fallback for missing keys and an invalid input error for values
+// of the wrong type, so Configure can return that error to reject the config:
+//
+//	func (p *MyPlugin) Configure(ctx context.Context, values map[string]interface{}) error {
+//		bucket, err := sdk.Config(values).String("bucket", "uploads")
+//		if err != nil {
+//			return err
+//		}
+//		p.bucket = bucket
+//		return nil
+//	}
+type Config map[string]interface{}
+
+// String returns the string under key
+func (c Config) String(key, fallback string) (string, error) {
+	value, ok := c[key]
+	if !ok {
+		return fallback, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", invalid(key, "a string", value)
+	}
+	return s, nil
+}
+
+// Strings returns the list of strings under key
+func (c Config) Strings(key string, fallback []string) ([]string, error) {
+	value, ok := c[key]
+	if !ok {
+		return fallback, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, invalid(key, "a list of strings", value)
+	}
+	out := make([]string, len(list))
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, invalid(fmt.Sprintf("%s[%d]", key, i), "a string", item)
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// Int returns the integer under key; whole floats are accepted since JSON
+// config numbers arrive as float64
+func (c Config) Int(key string, fallback int64) (int64, error) {
+	value, ok := c[key]
+	if !ok {
+		return fallback, nil
+	}
+	n, ok := Int(&types.Context{Properties: c}, key)
+	if !ok {
+		return 0, invalid(key, "an integer", value)
+	}
+	return n, nil
+}
+
+// Bool returns the bool under key
+func (c Config) Bool(key string, fallback bool) (bool, error) {
+	value, ok := c[key]
+	if !ok {
+		return fallback, nil
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, invalid(key, "a boolean", value)
+	}
+	return b, nil
+}
+
+func invalid(key, want string, got interface{}) error {
+	return types.InvalidInput(fmt.Sprintf("config %s must be %s, got %T", key, want, got))
+}
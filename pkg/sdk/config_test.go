@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestConfig(t *testing.T) {
+	config := Config{
+		"bucket":   "media",
+		"keywords": []interface{}{"convert", "upload"},
+		"quality":  float64(90),
+		"verbose":  true,
+		"mixed":    []interface{}{"convert", 3},
+	}
+
+	bucket, err := config.String("bucket", "uploads")
+	require.NoError(t, err)
+	assert.Equal(t, "media", bucket)
+
+	region, err := config.String("region", "eu-west-1")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", region)
+
+	keywords, err := config.Strings("keywords", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"convert", "upload"}, keywords)
+
+	quality, err := config.Int("quality", 75)
+	require.NoError(t, err)
+	assert.Equal(t, int64(90), quality)
+
+	verbose, err := config.Bool("verbose", false)
+	require.NoError(t, err)
+	assert.True(t, verbose)
+
+	tests := []struct {
+		name    string
+		get     func() error
+		wantErr string
+	}{
+		{"string", func() error { _, err := config.String("quality", ""); return err }, "config quality must be a string, got float64"},
+		{"strings", func() error { _, err := config.Strings("bucket", nil); return err }, "config bucket must be a list of strings, got string"},
+		{"strings item", func() error { _, err := config.Strings("mixed", nil); return err }, "config mixed[1] must be a string, got int"},
+		{"int", func() error { _, err := config.Int("bucket", 0); return err }, "config bucket must be an integer, got string"},
+		{"bool", func() error { _, err := config.Bool("bucket", false); return err }, "config bucket must be a boolean, got string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.get()
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, types.ErrorCodeInvalidInput, types.AsPluginError(err).Code)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+)
+
+// DebugFlag makes Serve run the plugin on its own, typically under a
+// debugger, and print the protocol.EnvReattach setting that makes the CLI
+// use this process instead of starting one:
+//
+//	dlv exec .plugins/plugin-converter -- --debug
+const DebugFlag = "--debug"
+
+// serveDebugFromArgs runs serveDebug until the process is interrupted
+func serveDebugFromArgs(sets map[int]plugin.PluginSet, fallback string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return serveDebug(ctx, sets, debugName(fallback), os.Stdout)
+}
+
+// serveDebug serves sets in go-plugin's test mode until ctx is cancelled, so
+// CLIs attaching to it leave it running when they exit. The setting that
+// attaches a CLI to it is written to out once it is serving.
+func serveDebug(ctx context.Context, sets map[int]plugin.PluginSet, name string, out io.Writer) error {
+	// With no CLI to negotiate with, go-plugin would fall back to the oldest
+	// version, so only the latest is served
+	latest := protocol.LatestProtocolVersion
+	reattachCh := make(chan *plugin.ReattachConfig, 1)
+	closeCh := make(chan struct{})
+	go plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig:  protocol.Handshake,
+		VersionedPlugins: map[int]plugin.PluginSet{latest: sets[latest]},
+		GRPCServer:       plugin.DefaultGRPCServer,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   name,
+			Output: os.Stderr,
+			Level:  hclog.Info,
+		}),
+		Test: &plugin.ServeTestConfig{
+			Context:          ctx,
+			ReattachConfigCh: reattachCh,
+			CloseCh:          closeCh,
+		},
+	})
+
+	select {
+	case reattach := <-reattachCh:
+		value, err := json.Marshal(map[string]protocol.ReattachConfig{name: protocol.NewReattachConfig(reattach)})
+		if err != nil {
+			return fmt.Errorf("failed to encode reattach config: %w", err)
+		}
+		_, _ = fmt.Fprintf(out, "Plugin %s is serving in debug mode. Run the CLI with:\n\n\t%s='%s'\n\n", name, protocol.EnvReattach, value)
+	case <-closeCh:
+		return errors.New("plugin failed to start serving")
+	}
+
+	<-closeCh
+	return nil
+}
+
+// debugName is the name the CLI knows the process by: the one discovery
+// gives its binary, or fallback for a binary without the plugin- prefix,
+// such as one built by dlv debug
+func debugName(fallback string) string {
+	if strings.HasPrefix(filepath.Base(os.Args[0]), discovery.PluginPrefix) {
+		return discovery.NameFromPath(os.Args[0])
+	}
+	return fallback
+}
@@ -0,0 +1,82 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/plugintest"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// visitor counts its runs in the host's store, so it needs host services
+type visitor struct{ greeter }
+
+func (p *visitor) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	host, _ := types.HostFrom(ctx)
+	visits, _, err := host.Get("visits")
+	if err != nil {
+		return nil, err
+	}
+	n, _ := visits.(int64)
+	if err := host.Set("visits", n+1); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func TestServeDebug(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	out, w := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- serveDebug(ctx, protocol.PluginSets(&visitor{greeter{Base: Base{Info: Info{Name: "visitor"}}}}), "visitor", w)
+	}()
+
+	var setting string
+	scanner := bufio.NewScanner(out)
+	for setting == "" && scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, protocol.EnvReattach+"=") {
+			setting = strings.Trim(strings.TrimPrefix(line, protocol.EnvReattach+"="), "'")
+		}
+	}
+	var configs map[string]protocol.ReattachConfig
+	require.NoError(t, json.Unmarshal([]byte(setting), &configs))
+	require.Contains(t, configs, "visitor")
+	reattach, err := configs["visitor"].ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, protocol.LatestProtocolVersion, reattach.ProtocolVersion)
+
+	// Every CLI run attaches to the same process and leaves it running
+	host := plugintest.NewHost()
+	for run := 1; run <= 2; run++ {
+		client := plugin.NewClient(&plugin.ClientConfig{
+			HandshakeConfig:  protocol.Handshake,
+			Plugins:          protocol.AttachedPlugins[reattach.ProtocolVersion],
+			Reattach:         reattach,
+			AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+			Logger:           hclog.NewNullLogger(),
+		})
+		rpcClient, err := client.Client()
+		require.NoError(t, err)
+		raw, err := rpcClient.Dispense("plugin")
+		require.NoError(t, err)
+
+		_, err = raw.(types.VersionedPlugin).Process(types.WithHost(context.Background(), host), &types.Context{})
+		require.NoError(t, err, "run %d", run)
+		assert.Equal(t, int64(run), host.Store["visits"])
+		client.Kill()
+		require.NoError(t, raw.(io.Closer).Close())
+	}
+
+	cancel()
+	require.NoError(t, <-served)
+}
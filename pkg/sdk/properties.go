@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Get returns the Properties value under key if it has type T
+func Get[T any](c *types.Context, key string) (T, bool) {
+	value, ok := c.Properties[key].(T)
+	return value, ok
+}
+
+// String returns the string under key, or "" if it is missing or not a string
+func String(c *types.Context, key string) string {
+	value, _ := Get[string](c, key)
+	return value
+}
+
+// Bool returns the bool under key, or false if it is missing or not a bool
+func Bool(c *types.Context, key string) bool {
+	value, _ := Get[bool](c, key)
+	return value
+}
+
+// Int returns the integer under key. Any integer type is accepted, as is a
+// float with no fractional part (older plugins send numbers as float64).
+func Int(c *types.Context, key string) (int64, bool) {
+	rv := reflect.ValueOf(c.Properties[key])
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(rv.Uint()), true //nolint:gosec // bounds checked above
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	default:
+		return 0, false
+	}
+}
+
+// Float returns the number under key as a float64; any numeric type is accepted
+func Float(c *types.Context, key string) (float64, bool) {
+	rv := reflect.ValueOf(c.Properties[key])
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// Has reports whether key is set
+func Has(c *types.Context, key string) bool {
+	_, ok := c.Properties[key]
+	return ok
+}
+
+// Set writes value under key, creating the Properties map if needed
+func Set(c *types.Context, key string, value interface{}) {
+	if c.Properties == nil {
+		c.Properties = make(map[string]interface{})
+	}
+	c.Properties[key] = value
+}
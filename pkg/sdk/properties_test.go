@@ -0,0 +1,61 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestProperties(t *testing.T) {
+	c := &types.Context{}
+	Set(c, "name", "clip.mp4")
+	Set(c, "ready", true)
+	Set(c, "size", int64(1<<40))
+	Set(c, "legacy_count", float64(7))
+	Set(c, "ratio", 0.5)
+	Set(c, "small", uint8(3))
+
+	assert.Equal(t, "clip.mp4", String(c, "name"))
+	assert.Equal(t, "", String(c, "ready"))
+	assert.True(t, Bool(c, "ready"))
+	assert.False(t, Bool(c, "missing"))
+	assert.True(t, Has(c, "name"))
+	assert.False(t, Has(c, "missing"))
+
+	tests := []struct {
+		key       string
+		wantInt   int64
+		intOK     bool
+		wantFloat float64
+		floatOK   bool
+	}{
+		{key: "size", wantInt: 1 << 40, intOK: true, wantFloat: 1 << 40, floatOK: true},
+		{key: "legacy_count", wantInt: 7, intOK: true, wantFloat: 7, floatOK: true},
+		{key: "ratio", intOK: false, wantFloat: 0.5, floatOK: true},
+		{key: "small", wantInt: 3, intOK: true, wantFloat: 3, floatOK: true},
+		{key: "name"},
+		{key: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			i, ok := Int(c, tt.key)
+			assert.Equal(t, tt.intOK, ok)
+			if ok {
+				assert.Equal(t, tt.wantInt, i)
+			}
+
+			f, ok := Float(c, tt.key)
+			assert.Equal(t, tt.floatOK, ok)
+			if ok {
+				assert.Equal(t, tt.wantFloat, f)
+			}
+		})
+	}
+
+	name, ok := Get[string](c, "name")
+	assert.True(t, ok)
+	assert.Equal(t, "clip.mp4", name)
+	_, ok = Get[int](c, "size")
+	assert.False(t, ok)
+}
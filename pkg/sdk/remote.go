@@ -0,0 +1,135 @@
+package sdk
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/config"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Environment variables that make Serve run the plugin as a service many
+// CLIs connect to, instead of as a subprocess of one CLI:
+//
+//	PLUGIN_LISTEN=unix:///run/plugins/converter.sock PLUGIN_CONFIG='{"video_format": "webm"}' ./plugin-converter
+const (
+	// EnvListen is the address to serve on: unix:///path, tcp://host:port or host:port
+	EnvListen = "PLUGIN_LISTEN"
+	// EnvConfig is a JSON object passed to Configure once, at startup
+	EnvConfig = "PLUGIN_CONFIG"
+	// EnvTLSCert and EnvTLSKey serve over TLS with this certificate
+	EnvTLSCert = "PLUGIN_TLS_CERT"
+	EnvTLSKey  = "PLUGIN_TLS_KEY"
+	// EnvTLSClientCA requires CLIs to present a certificate signed by this CA
+	EnvTLSClientCA = "PLUGIN_TLS_CLIENT_CA"
+)
+
+// ServeRemote configures and initializes p, serves it at addr until ctx is
+// cancelled, then shuts it down. CLIs connect to it with the address in
+// their project config; see protocol.ServeRemote.
+func ServeRemote(ctx context.Context, p types.VersionedPlugin, addr string, tlsConfig *tls.Config, values map[string]interface{}) error {
+	if configurable, ok := p.(types.Configurable); ok {
+		if values == nil {
+			values = make(map[string]interface{})
+		}
+		if err := configurable.Configure(ctx, values); err != nil {
+			return fmt.Errorf("plugin rejected its configuration: %w", err)
+		}
+	} else if len(values) > 0 {
+		return errors.New("plugin does not accept configuration")
+	}
+
+	if initializer, ok := p.(types.Initializer); ok {
+		if err := initializer.Init(ctx); err != nil {
+			return fmt.Errorf("plugin failed to initialize: %w", err)
+		}
+	}
+
+	serveErr := protocol.ServeRemote(ctx, p, addr, tlsConfig)
+
+	if shutdowner, ok := p.(types.Shutdowner); ok {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(config.DefaultShutdownGrace))
+		defer cancel()
+		if err := shutdowner.Shutdown(shutdownCtx); err != nil && serveErr == nil {
+			return fmt.Errorf("plugin did not shut down cleanly: %w", err)
+		}
+	}
+	return serveErr
+}
+
+// serveFromEnv runs ServeRemote with the settings in the environment until
+// the process is interrupted
+func serveFromEnv(p types.VersionedPlugin, addr string) error {
+	tlsConfig, err := serverTLSFromEnv()
+	if err != nil {
+		return err
+	}
+
+	values, err := configFromEnv()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "serving plugin %s on %s\n", p.Name(), addr)
+	return ServeRemote(ctx, p, addr, tlsConfig, values)
+}
+
+// configFromEnv decodes EnvConfig the way the CLI's config reaches plugins
+// it starts, so a plugin sees the same value types either way
+func configFromEnv() (map[string]interface{}, error) {
+	raw := os.Getenv(EnvConfig)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return nil, fmt.Errorf("%s is not a JSON object: %w", EnvConfig, err)
+	}
+	values, err := protocol.ToValueMap(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EnvConfig, err)
+	}
+	return protocol.FromValueMap(values)
+}
+
+func serverTLSFromEnv() (*tls.Config, error) {
+	certFile, keyFile, caFile := os.Getenv(EnvTLSCert), os.Getenv(EnvTLSKey), os.Getenv(EnvTLSClientCA)
+	if certFile == "" && keyFile == "" {
+		if caFile != "" {
+			return nil, fmt.Errorf("%s needs %s and %s", EnvTLSClientCA, EnvTLSCert, EnvTLSKey)
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{cert}}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile) //nolint:gosec // G304: path comes from the operator's environment
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		cfg.ClientCAs = x509.NewCertPool()
+		if !cfg.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", caFile)
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
@@ -0,0 +1,103 @@
+package sdk
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// service records its lifecycle calls
+type service struct {
+	greeter
+
+	mu     sync.Mutex
+	calls  []string
+	values map[string]interface{}
+}
+
+func (s *service) record(call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+func (s *service) Configure(ctx context.Context, values map[string]interface{}) error {
+	s.record("configure")
+	s.values = values
+	return nil
+}
+
+func (s *service) Init(ctx context.Context) error {
+	s.record("init")
+	return nil
+}
+
+func (s *service) Shutdown(ctx context.Context) error {
+	s.record("shutdown")
+	return nil
+}
+
+func TestServeRemote(t *testing.T) {
+	p := &service{greeter: greeter{Base: Base{Info: Info{Name: "greeter"}}}}
+	addr := "unix://" + filepath.Join(t.TempDir(), "greeter.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- ServeRemote(ctx, p, addr, nil, map[string]interface{}{"greeting": "hi"}) }()
+
+	var client *protocol.RemoteClient
+	require.Eventually(t, func() bool {
+		var err error
+		client, err = protocol.Dial(context.Background(), addr, nil)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	result, err := client.Process(context.Background(), &types.Context{})
+	require.NoError(t, err)
+	require.Len(t, result.Responses, 1)
+	assert.Equal(t, "hello", result.Responses[0].Content)
+
+	// A CLI connecting does not rerun the lifecycle
+	require.NoError(t, client.Init(context.Background()))
+	require.NoError(t, client.Close())
+
+	cancel()
+	require.NoError(t, <-served)
+	assert.Equal(t, []string{"configure", "init", "shutdown"}, p.calls)
+	assert.Equal(t, map[string]interface{}{"greeting": "hi"}, p.values)
+}
+
+func TestServeRemote_NotConfigurable(t *testing.T) {
+	p := &greeter{Base: Base{Info: Info{Name: "greeter"}}}
+	err := ServeRemote(context.Background(), p, "unix://"+filepath.Join(t.TempDir(), "greeter.sock"), nil, map[string]interface{}{"greeting": "hi"})
+	assert.EqualError(t, err, "plugin does not accept configuration")
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvConfig, `{"format": "webm", "quality": 90, "ratio": 0.5}`)
+
+	values, err := configFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"format": "webm", "quality": float64(90), "ratio": 0.5}, values)
+
+	t.Setenv(EnvConfig, `["not", "an", "object"]`)
+	_, err = configFromEnv()
+	assert.ErrorContains(t, err, "PLUGIN_CONFIG is not a JSON object")
+}
+
+func TestServerTLSFromEnv(t *testing.T) {
+	tlsConfig, err := serverTLSFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig, "no TLS without a certificate")
+
+	t.Setenv(EnvTLSClientCA, "ca.pem")
+	_, err = serverTLSFromEnv()
+	assert.EqualError(t, err, "PLUGIN_TLS_CLIENT_CA needs PLUGIN_TLS_CERT and PLUGIN_TLS_KEY")
+}
@@ -0,0 +1,255 @@
+// Package sdk is the starting point for writing a plugin. Embed Base, write
+// ShouldExecute and Process, and call Serve from main:
+//
+//	type Greeter struct{ sdk.Base }
+//
+//	func (p *Greeter) ShouldExecute(ctx context.Context, c *types.Context) types.ExecutionDecision {
+//		return sdk.Execute("always greets")
+//	}
+//
+//	func (p *Greeter) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+//		p.Respond(c, "text", "hello", nil)
+//		return c, nil
+//	}
+//
+//	func main() {
+//		sdk.Serve(&Greeter{Base: sdk.Base{Info: sdk.Info{Name: "greeter"}}})
+//	}
+package sdk
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/discovery"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// Version and BuildTime are set at build time with
+//
+//	-ldflags "-X github.com/williamokano/hashicorp-plugin-example/pkg/sdk.Version=1.2.0 \
+//	          -X github.com/williamokano/hashicorp-plugin-example/pkg/sdk.BuildTime=2024-05-01_12:00:00"
+//
+// and override what the plugin declares in Info
+var (
+	Version   string
+	BuildTime string
+)
+
+// Defaults for the Info fields a plugin leaves empty
+const (
+	DefaultPriority      = 100
+	DefaultMinCLIVersion = "1.0.0"
+	DefaultMaxCLIVersion = "2.0.0"
+	DefaultVersion       = "0.0.0-dev"
+)
+
+// startedAt stands in for the build time when nothing better is known
+var startedAt = time.Now()
+
+// Info describes a plugin to the CLI. Only Name is required.
+type Info struct {
+	Name        string
+	Description string
+
+	// Priority orders plugins, lower first; zero means DefaultPriority
+	Priority int
+
+	// Version is used when none was set with ldflags
+	Version string
+
+	// CLI versions the plugin works with; empty means the defaults
+	MinCLIVersion string
+	MaxCLIVersion string
+
+	// Timeouts and Dependencies are reported as declared; zero values leave
+	// the host defaults in place
+	Timeouts     types.Timeouts
+	Dependencies types.Dependencies
+}
+
+// Base implements every method of types.VersionedPlugin except
+// ShouldExecute and Process, plus types.TimeoutProvider and
+// types.DependencyProvider, from its Info
+type Base struct {
+	Info Info
+}
+
+// Name returns the plugin name
+func (b Base) Name() string {
+	return b.Info.Name
+}
+
+// Description returns the plugin description
+func (b Base) Description() string {
+	return b.Info.Description
+}
+
+// Priority returns the plugin priority
+func (b Base) Priority() int {
+	if b.Info.Priority == 0 {
+		return DefaultPriority
+	}
+	return b.Info.Priority
+}
+
+// Version returns the version set with ldflags, else the declared one, else
+// the module version the binary was built from
+func (b Base) Version() string {
+	if Version != "" {
+		return Version
+	}
+	if b.Info.Version != "" {
+		return b.Info.Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return DefaultVersion
+}
+
+// BuildTime returns the build time set with ldflags, else the VCS commit
+// time recorded by the Go toolchain, else the time the plugin started
+func (b Base) BuildTime() string {
+	if BuildTime != "" && BuildTime != "unknown" {
+		return BuildTime
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.time" && setting.Value != "" {
+				return setting.Value
+			}
+		}
+	}
+	return startedAt.Format(time.RFC3339)
+}
+
+// MinCLIVersion returns the minimum CLI version
+func (b Base) MinCLIVersion() string {
+	if b.Info.MinCLIVersion == "" {
+		return DefaultMinCLIVersion
+	}
+	return b.Info.MinCLIVersion
+}
+
+// MaxCLIVersion returns the maximum CLI version
+func (b Base) MaxCLIVersion() string {
+	if b.Info.MaxCLIVersion == "" {
+		return DefaultMaxCLIVersion
+	}
+	return b.Info.MaxCLIVersion
+}
+
+// Timeouts returns the declared RPC deadlines
+func (b Base) Timeouts() types.Timeouts {
+	return b.Info.Timeouts
+}
+
+// Dependencies returns the declared Properties keys
+func (b Base) Dependencies() types.Dependencies {
+	return b.Info.Dependencies
+}
+
+// Respond adds a response from this plugin to the context
+func (b Base) Respond(c *types.Context, responseType, content string, data map[string]interface{}) {
+	c.Responses = append(c.Responses, types.Response{
+		PluginName: b.Name(),
+		Type:       responseType,
+		Content:    content,
+		Data:       data,
+	})
+}
+
+// RespondFile adds a "file" response announcing a blob this plugin stored
+// with Host.PutBlob, so the CLI can hand the file to the user
+func (b Base) RespondFile(c *types.Context, content string, blob types.Blob) {
+	b.Respond(c, types.ResponseFile, content, blob.Data())
+}
+
+// Execute is a decision to run the plugin
+func Execute(reason string) types.ExecutionDecision {
+	return types.ExecutionDecision{ShouldExecute: true, Reason: reason}
+}
+
+// Skip is a decision not to run the plugin
+func Skip(reason string) types.ExecutionDecision {
+	return types.ExecutionDecision{ShouldExecute: false, Reason: reason}
+}
+
+// Serve runs p as a plugin process speaking every supported protocol
+// version. It returns once the CLI is done with the plugin. With EnvListen
+// set, p is served at that address until interrupted instead, and with
+// DebugFlag it waits for CLIs to attach to it.
+func Serve(p types.VersionedPlugin) {
+	serve(protocol.PluginSets(p), p.Name(), func(addr string) error {
+		return serveFromEnv(p, addr)
+	})
+}
+
+// BundleFlag makes a binary serving several plugins print their names, one
+// per line, and exit. Install the output next to the binary as its manifest
+// (see discovery.BundleSuffix), so the CLI lists the plugins without
+// starting it:
+//
+//	plugin-text --bundle > .plugins/plugin-text.bundle
+const BundleFlag = "--bundle"
+
+// ServePlugins runs several plugins from one process, keyed by their name
+// in the bundle. The CLI loads each as a plugin of its own, named after the
+// binary, a dot and that name, so a plugin-image binary calling
+//
+//	sdk.ServePlugins(map[string]types.VersionedPlugin{"resize": &Resizer{}, "crop": &Cropper{}})
+//
+// offers image.resize and image.crop. DebugFlag works as with Serve;
+// EnvListen does not, as remote plugins are served one per address. With
+// BundleFlag it prints the manifest the CLI lists the plugins from.
+func ServePlugins(plugins map[string]types.VersionedPlugin) {
+	name := discovery.NameFromPath(os.Args[0])
+	if err := protocol.ValidateBundle(slices.Collect(maps.Keys(plugins))); err != nil {
+		fmt.Fprintf(os.Stderr, "plugin %s: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	if slices.Contains(os.Args[1:], BundleFlag) {
+		for _, member := range slices.Sorted(maps.Keys(plugins)) {
+			fmt.Println(member)
+		}
+		return
+	}
+
+	serve(protocol.BundleSets(plugins), name, func(string) error {
+		return fmt.Errorf("%s cannot serve several plugins, serve each from its own binary with Serve", EnvListen)
+	})
+}
+
+// serve runs sets as Serve describes, serving at an EnvListen address with
+// listen. name identifies the process in errors.
+func serve(sets map[int]plugin.PluginSet, name string, listen func(addr string) error) {
+	if slices.Contains(os.Args[1:], DebugFlag) {
+		if err := serveDebugFromArgs(sets, name); err != nil {
+			fmt.Fprintf(os.Stderr, "plugin %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if addr := os.Getenv(EnvListen); addr != "" {
+		if err := listen(addr); err != nil {
+			fmt.Fprintf(os.Stderr, "plugin %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig:  protocol.Handshake,
+		VersionedPlugins: sets,
+		GRPCServer:       plugin.DefaultGRPCServer,
+	})
+}
@@ -0,0 +1,96 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+type greeter struct {
+	Base
+}
+
+func (p *greeter) ShouldExecute(ctx context.Context, c *types.Context) types.ExecutionDecision {
+	return Execute("always greets")
+}
+
+func (p *greeter) Process(ctx context.Context, c *types.Context) (*types.Context, error) {
+	p.Respond(c, "text", "hello", nil)
+	return c, nil
+}
+
+var (
+	_ types.VersionedPlugin    = (*greeter)(nil)
+	_ types.TimeoutProvider    = (*greeter)(nil)
+	_ types.DependencyProvider = (*greeter)(nil)
+)
+
+func TestBase_Defaults(t *testing.T) {
+	p := &greeter{Base: Base{Info: Info{Name: "greeter"}}}
+
+	assert.Equal(t, "greeter", p.Name())
+	assert.Equal(t, DefaultPriority, p.Priority())
+	assert.Equal(t, DefaultMinCLIVersion, p.MinCLIVersion())
+	assert.Equal(t, DefaultMaxCLIVersion, p.MaxCLIVersion())
+	assert.Equal(t, DefaultVersion, p.Version()) // test binaries have no module version
+	assert.NotEmpty(t, p.BuildTime())
+	assert.Equal(t, types.Timeouts{}, p.Timeouts())
+	assert.Equal(t, types.Dependencies{}, p.Dependencies())
+}
+
+func TestBase_Declared(t *testing.T) {
+	p := &greeter{Base: Base{Info: Info{
+		Name:          "greeter",
+		Description:   "says hello",
+		Priority:      20,
+		Version:       "1.4.0",
+		MinCLIVersion: "1.2.0",
+		MaxCLIVersion: "3.0.0",
+		Timeouts:      types.Timeouts{Process: time.Minute},
+		Dependencies:  types.Dependencies{Provides: []string{"greeting"}},
+	}}}
+
+	assert.Equal(t, "says hello", p.Description())
+	assert.Equal(t, 20, p.Priority())
+	assert.Equal(t, "1.4.0", p.Version())
+	assert.Equal(t, "1.2.0", p.MinCLIVersion())
+	assert.Equal(t, "3.0.0", p.MaxCLIVersion())
+	assert.Equal(t, time.Minute, p.Timeouts().Process)
+	assert.Equal(t, []string{"greeting"}, p.Dependencies().Provides)
+}
+
+func TestBase_LdflagsWin(t *testing.T) {
+	Version, BuildTime = "2.0.1", "2024-05-01_12:00:00"
+	t.Cleanup(func() { Version, BuildTime = "", "" })
+
+	p := &greeter{Base: Base{Info: Info{Name: "greeter", Version: "1.4.0"}}}
+	assert.Equal(t, "2.0.1", p.Version())
+	assert.Equal(t, "2024-05-01_12:00:00", p.BuildTime())
+}
+
+func TestBase_Respond(t *testing.T) {
+	p := &greeter{Base: Base{Info: Info{Name: "greeter"}}}
+	c := &types.Context{}
+
+	_, err := p.Process(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Response{{PluginName: "greeter", Type: "text", Content: "hello"}}, c.Responses)
+}
+
+func TestBase_RespondFile(t *testing.T) {
+	b := Base{Info: Info{Name: "converter"}}
+	c := &types.Context{}
+	blob := types.Blob{Handle: "blob-1", Name: "clip.webm", ContentType: "video/webm", Size: 2048}
+
+	b.RespondFile(c, "converted clip.webm", blob)
+
+	require.Len(t, c.Responses, 1)
+	assert.Equal(t, types.ResponseFile, c.Responses[0].Type)
+	got, ok := c.Responses[0].Blob()
+	assert.True(t, ok)
+	assert.Equal(t, blob, got)
+}
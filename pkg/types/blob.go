@@ -0,0 +1,56 @@
+package types
+
+import "errors"
+
+// ErrBlobNotFound is returned when opening a blob whose handle the host does
+// not know, e.g. one from an earlier run
+var ErrBlobNotFound = errors.New("blob not found")
+
+// Blob describes an artifact the host holds for the length of a pipeline run,
+// such as an event attachment or a file a plugin produced. Plugins pass blobs
+// to each other and back to the CLI by Handle instead of by filesystem path,
+// so they keep working when they do not share a filesystem with the host.
+type Blob struct {
+	Handle      string `json:"handle"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+}
+
+// ResponseFile is the type of a Response announcing a file a plugin produced.
+// Its Data describes the file's blob; read it back with Response.Blob.
+const ResponseFile = "file"
+
+// Data returns the blob as Response data
+func (b Blob) Data() map[string]interface{} {
+	return map[string]interface{}{
+		"handle":       b.Handle,
+		"name":         b.Name,
+		"content_type": b.ContentType,
+		"size":         b.Size,
+	}
+}
+
+// Blob returns the blob a "file" response carries. Responses of other types,
+// and file responses from plugins that predate blobs (which carry a path
+// instead), have none.
+func (r Response) Blob() (Blob, bool) {
+	if r.Type != ResponseFile {
+		return Blob{}, false
+	}
+	handle, _ := r.Data["handle"].(string)
+	if handle == "" {
+		return Blob{}, false
+	}
+
+	blob := Blob{Handle: handle}
+	blob.Name, _ = r.Data["name"].(string)
+	blob.ContentType, _ = r.Data["content_type"].(string)
+	switch size := r.Data["size"].(type) {
+	case int64:
+		blob.Size = size
+	case float64:
+		blob.Size = int64(size)
+	}
+	return blob, true
+}
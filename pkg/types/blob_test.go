@@ -0,0 +1,47 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponse_Blob(t *testing.T) {
+	blob := Blob{Handle: "blob-1", Name: "clip.mp4", ContentType: "video/mp4", Size: 2048}
+
+	tests := []struct {
+		name     string
+		response Response
+		want     Blob
+		wantOK   bool
+	}{
+		{
+			name:     "file response",
+			response: Response{Type: ResponseFile, Data: blob.Data()},
+			want:     blob,
+			wantOK:   true,
+		},
+		{
+			name:     "size decoded from JSON",
+			response: Response{Type: ResponseFile, Data: map[string]interface{}{"handle": "blob-1", "name": "clip.mp4", "content_type": "video/mp4", "size": float64(2048)}},
+			want:     blob,
+			wantOK:   true,
+		},
+		{
+			name:     "file response with a path",
+			response: Response{Type: ResponseFile, Data: map[string]interface{}{"path": "/tmp/clip.mp4"}},
+		},
+		{
+			name:     "other response type",
+			response: Response{Type: "text", Data: blob.Data()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.response.Blob()
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Command is a CLI subcommand a plugin contributes. The CLI mounts it as
+// plugin-cli <plugin> <name>, where <plugin> is the name the plugin was
+// discovered under.
+type Command struct {
+	Name string `json:"name"`
+	// Usage describes the arguments after the name, e.g. "[prefix]"
+	Usage string        `json:"usage,omitempty"`
+	Short string        `json:"short,omitempty"`
+	Long  string        `json:"long,omitempty"`
+	Flags []CommandFlag `json:"flags,omitempty"`
+}
+
+// FlagType is the type of a command flag's value
+type FlagType string
+
+const (
+	FlagString FlagType = "string"
+	FlagBool   FlagType = "bool"
+	FlagInt    FlagType = "int"
+)
+
+// CommandFlag is a flag of a plugin command. Default is written the way it
+// would be on the command line; an empty Type means FlagString.
+type CommandFlag struct {
+	Name      string   `json:"name"`
+	Shorthand string   `json:"shorthand,omitempty"`
+	Type      FlagType `json:"type,omitempty"`
+	Default   string   `json:"default,omitempty"`
+	Usage     string   `json:"usage,omitempty"`
+}
+
+// Parse converts a value written on the command line to the type
+// RunCommand receives the flag as. An empty value is the type's zero value.
+func (f CommandFlag) Parse(value string) (interface{}, error) {
+	switch f.Type {
+	case FlagString, "":
+		return value, nil
+	case FlagBool:
+		if value == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag %s: %w", value, f.Name, err)
+		}
+		return b, nil
+	case FlagInt:
+		if value == "" {
+			return int64(0), nil
+		}
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag %s: %w", value, f.Name, err)
+		}
+		return i, nil
+	default:
+		return nil, fmt.Errorf("flag %s has unknown type %q", f.Name, f.Type)
+	}
+}
+
+// CommandRequest is one run of a plugin command. Flags holds every flag the
+// command declares, as a string, bool or int64 by its type.
+type CommandRequest struct {
+	Name  string
+	Args  []string
+	Flags map[string]interface{}
+}
+
+// CommandProvider is implemented by plugins that contribute CLI
+// subcommands. RunCommand writes the command's output to out; a returned
+// error is shown to the user and fails the command. Commands get no host
+// services.
+type CommandProvider interface {
+	Commands(ctx context.Context) ([]Command, error)
+	RunCommand(ctx context.Context, req CommandRequest, out io.Writer) error
+}
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandFlag_Parse(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    CommandFlag
+		value   string
+		want    interface{}
+		wantErr string
+	}{
+		{name: "string", flag: CommandFlag{Name: "output", Type: FlagString}, value: "json", want: "json"},
+		{name: "untyped is a string", flag: CommandFlag{Name: "output"}, value: "42", want: "42"},
+		{name: "bool", flag: CommandFlag{Name: "all", Type: FlagBool}, value: "true", want: true},
+		{name: "empty bool", flag: CommandFlag{Name: "all", Type: FlagBool}, want: false},
+		{name: "int", flag: CommandFlag{Name: "limit", Type: FlagInt}, value: "-7", want: int64(-7)},
+		{name: "empty int", flag: CommandFlag{Name: "limit", Type: FlagInt}, want: int64(0)},
+		{
+			name:    "bad int",
+			flag:    CommandFlag{Name: "limit", Type: FlagInt},
+			value:   "ten",
+			wantErr: `invalid value "ten" for flag limit`,
+		},
+		{
+			name:    "unknown type",
+			flag:    CommandFlag{Name: "ratio", Type: "float"},
+			value:   "0.5",
+			wantErr: `flag ratio has unknown type "float"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.flag.Parse(tt.value)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
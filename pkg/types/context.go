@@ -1,10 +1,47 @@
 package types
 
+import "slices"
+
 // Context carries data through the plugin pipeline
 type Context struct {
 	Event      Event                  `json:"event"`
-	Properties map[string]interface{} `json:"properties"` // Shared data between plugins
-	Responses  []Response             `json:"responses"`  // Accumulated responses from plugins
+	Properties map[string]interface{} `json:"properties"`        // Shared data between plugins
+	Responses  []Response             `json:"responses"`         // Accumulated responses from plugins
+	Control    *Control               `json:"control,omitempty"` // Set by a plugin to end the pipeline early
+}
+
+// Signal tells the pipeline how to proceed after a plugin processed the event
+type Signal string
+
+const (
+	// SignalContinue passes the context on to the next plugin (the default)
+	SignalContinue Signal = "continue"
+	// SignalStop marks the event as handled; no further plugins run
+	SignalStop Signal = "stop"
+	// SignalReject refuses the event; no further plugins run
+	SignalReject Signal = "reject"
+)
+
+// Control is a plugin's request to stop or reject the event
+type Control struct {
+	Signal Signal `json:"signal"`
+	Reason string `json:"reason,omitempty"`
+	Plugin string `json:"plugin,omitempty"` // filled in by the pipeline
+}
+
+// Stop marks the event as handled so that no further plugins run
+func (c *Context) Stop(reason string) {
+	c.Control = &Control{Signal: SignalStop, Reason: reason}
+}
+
+// Reject refuses the event so that no further plugins run
+func (c *Context) Reject(reason string) {
+	c.Control = &Control{Signal: SignalReject, Reason: reason}
+}
+
+// Halted reports whether a plugin asked the pipeline to stop or reject the event
+func (c *Context) Halted() bool {
+	return c.Control != nil && (c.Control.Signal == SignalStop || c.Control.Signal == SignalReject)
 }
 
 // Response represents what a plugin wants to send back
@@ -20,3 +57,60 @@ type ExecutionDecision struct {
 	ShouldExecute bool   `json:"should_execute"`
 	Reason        string `json:"reason"`
 }
+
+// Clone returns a deep copy of the context so it can be handed to a plugin
+// without sharing maps or slices with other plugins
+func (c *Context) Clone() *Context {
+	if c == nil {
+		return nil
+	}
+
+	clone := &Context{
+		Event:      c.Event,
+		Properties: cloneMap(c.Properties),
+		Responses:  make([]Response, len(c.Responses)),
+	}
+	clone.Event.Metadata = cloneMap(c.Event.Metadata)
+	clone.Event.Attachments = slices.Clone(c.Event.Attachments)
+	if c.Control != nil {
+		control := *c.Control
+		clone.Control = &control
+	}
+
+	for i, resp := range c.Responses {
+		clone.Responses[i] = resp
+		clone.Responses[i].Data = cloneMap(resp.Data)
+	}
+
+	return clone
+}
+
+func cloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = cloneValue(v)
+	}
+	return out
+}
+
+func cloneValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return cloneMap(val)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = cloneValue(item)
+		}
+		return out
+	case []string:
+		return append([]string(nil), val...)
+	case []byte:
+		return append([]byte(nil), val...)
+	default:
+		return v
+	}
+}
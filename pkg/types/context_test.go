@@ -137,3 +137,61 @@ func TestResponse_GetDataValue(t *testing.T) {
 	_, exists := resp.Data["missing"]
 	assert.False(t, exists)
 }
+
+func TestContext_Clone(t *testing.T) {
+	original := &Context{
+		Event: Event{
+			Type:     EventMessage,
+			Content:  "test",
+			Metadata: map[string]interface{}{"tags": []string{"a"}},
+		},
+		Properties: map[string]interface{}{
+			"nested": map[string]interface{}{"key": "value"},
+			"list":   []interface{}{"x", map[string]interface{}{"deep": 1}},
+		},
+		Responses: []Response{
+			{PluginName: "plugin1", Data: map[string]interface{}{"foo": "bar"}},
+		},
+	}
+
+	clone := original.Clone()
+	assert.Equal(t, original, clone)
+
+	// Mutating the clone must not leak into the original
+	clone.Properties["added"] = true
+	clone.Properties["nested"].(map[string]interface{})["key"] = "changed"
+	clone.Properties["list"].([]interface{})[1].(map[string]interface{})["deep"] = 2
+	clone.Event.Metadata["tags"].([]string)[0] = "b"
+	clone.Responses[0].Data["foo"] = "baz"
+	clone.Responses = append(clone.Responses, Response{PluginName: "plugin2"})
+
+	assert.NotContains(t, original.Properties, "added")
+	assert.Equal(t, "value", original.Properties["nested"].(map[string]interface{})["key"])
+	assert.Equal(t, 1, original.Properties["list"].([]interface{})[1].(map[string]interface{})["deep"])
+	assert.Equal(t, "a", original.Event.Metadata["tags"].([]string)[0])
+	assert.Equal(t, "bar", original.Responses[0].Data["foo"])
+	assert.Len(t, original.Responses, 1)
+
+	var nilContext *Context
+	assert.Nil(t, nilContext.Clone())
+}
+
+func TestContext_Control(t *testing.T) {
+	ctx := &Context{Properties: map[string]interface{}{}}
+	assert.False(t, ctx.Halted())
+
+	ctx.Control = &Control{Signal: SignalContinue}
+	assert.False(t, ctx.Halted())
+
+	ctx.Stop("answered")
+	assert.True(t, ctx.Halted())
+	assert.Equal(t, Control{Signal: SignalStop, Reason: "answered"}, *ctx.Control)
+
+	clone := ctx.Clone()
+	clone.Control.Plugin = "filter"
+	assert.Empty(t, ctx.Control.Plugin, "clone must not share the control")
+
+	clone.Reject("spam")
+	assert.Equal(t, SignalReject, clone.Control.Signal)
+	assert.Equal(t, SignalStop, ctx.Control.Signal)
+}
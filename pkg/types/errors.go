@@ -0,0 +1,107 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode classifies why a plugin call failed
+type ErrorCode string
+
+const (
+	// ErrorCodeInternal is a bug in the plugin; plain errors get this code
+	ErrorCodeInternal ErrorCode = "internal"
+	// ErrorCodeInvalidInput means the plugin cannot work with the context it was given
+	ErrorCodeInvalidInput ErrorCode = "invalid_input"
+	// ErrorCodeRejected is a deliberate refusal to process the event
+	ErrorCodeRejected ErrorCode = "rejected"
+	// ErrorCodeUnavailable means something the plugin depends on is down
+	ErrorCodeUnavailable ErrorCode = "unavailable"
+	// ErrorCodeTimeout means the plugin ran out of time
+	ErrorCodeTimeout ErrorCode = "timeout"
+	// ErrorCodeTransport is set by the host when the RPC itself failed
+	ErrorCodeTransport ErrorCode = "transport"
+)
+
+// Retryable reports whether errors with this code are retryable by default
+func (c ErrorCode) Retryable() bool {
+	switch c {
+	case ErrorCodeUnavailable, ErrorCodeTimeout, ErrorCodeTransport:
+		return true
+	default:
+		return false
+	}
+}
+
+// PluginError is a classified plugin failure. It crosses the gRPC boundary
+// intact, so the pipeline can decide whether to retry.
+type PluginError struct {
+	Code ErrorCode
+	// Message is shown to users
+	Message string
+	// Retryable tells the pipeline a later attempt may succeed
+	Retryable bool
+	// Detail is for logs, e.g. the underlying error
+	Detail string
+
+	cause error
+}
+
+// NewError returns an error with the code's default retryability. A non-nil
+// cause becomes the Detail and is available to errors.Is and errors.As on
+// the plugin's side of the connection.
+func NewError(code ErrorCode, message string, cause error) *PluginError {
+	e := &PluginError{Code: code, Message: message, Retryable: code.Retryable(), cause: cause}
+	if cause != nil {
+		e.Detail = cause.Error()
+	}
+	return e
+}
+
+// Rejected returns an error refusing the event. The pipeline records the
+// plugin as rejected rather than failed and carries on without its changes;
+// use Context.Reject to end the event for every plugin.
+func Rejected(message string) *PluginError {
+	return NewError(ErrorCodeRejected, message, nil)
+}
+
+// Unavailable returns a retryable error for a dependency that is down
+func Unavailable(message string, cause error) *PluginError {
+	return NewError(ErrorCodeUnavailable, message, cause)
+}
+
+// InvalidInput returns an error for a context the plugin cannot work with
+func InvalidInput(message string) *PluginError {
+	return NewError(ErrorCodeInvalidInput, message, nil)
+}
+
+func (e *PluginError) Error() string {
+	if e.Detail == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Message, e.Detail)
+}
+
+// Unwrap returns the cause the error was created with
+func (e *PluginError) Unwrap() error {
+	return e.cause
+}
+
+// AsPluginError returns err as a PluginError, classifying anything else as
+// an internal error. It returns nil for a nil error.
+func AsPluginError(err error) *PluginError {
+	if err == nil {
+		return nil
+	}
+	var pe *PluginError
+	if errors.As(err, &pe) {
+		return pe
+	}
+	return &PluginError{Code: ErrorCodeInternal, Message: err.Error(), cause: err}
+}
+
+// IsRetryable reports whether err is a PluginError marked retryable
+func IsRetryable(err error) bool {
+	var pe *PluginError
+	return errors.As(err, &pe) && pe.Retryable
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginError(t *testing.T) {
+	cause := errors.New("connection refused")
+	tests := []struct {
+		name          string
+		err           error
+		wantCode      ErrorCode
+		wantMessage   string
+		wantString    string
+		wantRetryable bool
+	}{
+		{"plain error", errors.New("boom"), ErrorCodeInternal, "boom", "boom", false},
+		{"rejected", Rejected("not mine"), ErrorCodeRejected, "not mine", "not mine", false},
+		{"unavailable", Unavailable("storage is down", cause), ErrorCodeUnavailable, "storage is down", "storage is down: connection refused", true},
+		{"invalid input", InvalidInput("missing file_path"), ErrorCodeInvalidInput, "missing file_path", "missing file_path", false},
+		{"wrapped", fmt.Errorf("upload: %w", Unavailable("storage is down", nil)), ErrorCodeUnavailable, "storage is down", "upload: storage is down", true},
+		{"retryability overridden", &PluginError{Code: ErrorCodeInternal, Message: "flaky", Retryable: true}, ErrorCodeInternal, "flaky", "flaky", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pe := AsPluginError(tt.err)
+			assert.Equal(t, tt.wantCode, pe.Code)
+			assert.Equal(t, tt.wantMessage, pe.Message)
+			assert.Equal(t, tt.wantString, tt.err.Error())
+			assert.Equal(t, tt.wantRetryable, IsRetryable(tt.err))
+		})
+	}
+
+	assert.Nil(t, AsPluginError(nil))
+	assert.ErrorIs(t, Unavailable("storage is down", cause), cause)
+}
@@ -18,4 +18,7 @@ type Event struct {
 	UserID    string                 `json:"user_id"`
 	ChannelID string                 `json:"channel_id"`
 	Metadata  map[string]interface{} `json:"metadata"` // Additional event-specific data
+
+	// Attachments are files that came with the event, held by the host as blobs
+	Attachments []Blob `json:"attachments,omitempty"`
 }
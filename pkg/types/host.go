@@ -0,0 +1,83 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNoHost is returned by the Host from HostFrom when the caller offers no
+// host services, e.g. an older CLI or a plugin run outside the pipeline
+var ErrNoHost = errors.New("host services are not available")
+
+// Log levels accepted by Host.Log
+const (
+	LogDebug = "debug"
+	LogInfo  = "info"
+	LogWarn  = "warn"
+	LogError = "error"
+)
+
+// Host is the set of services the CLI offers a plugin while it handles an
+// event. Plugins get it from the context passed to ShouldExecute and Process.
+type Host interface {
+	// Log writes a structured log line to the CLI's log, tagged with the plugin
+	Log(level, message string, fields map[string]interface{})
+
+	// Get, Set and Delete use a key-value store that persists across events.
+	// Each plugin has its own keys.
+	Get(key string) (value interface{}, found bool, err error)
+	Set(key string, value interface{}) error
+	Delete(key string) error
+
+	// Emit queues a follow-up event that runs through the pipeline after the
+	// current one
+	Emit(event Event) error
+
+	// Progress reports how far along a long-running Process call is, from 0 to 100
+	Progress(percent float64, message string)
+
+	// PutBlob streams what r yields to the host as a blob and returns it.
+	// Pass its Handle to later plugins, e.g. in a property or a "file"
+	// response, instead of a filesystem path. An empty contentType is
+	// guessed from the name.
+	PutBlob(name, contentType string, r io.Reader) (Blob, error)
+
+	// OpenBlob streams the contents of a blob, such as an event attachment
+	OpenBlob(handle string) (io.ReadCloser, Blob, error)
+}
+
+type hostKey struct{}
+
+// WithHost returns a context carrying the host services for one plugin call
+func WithHost(ctx context.Context, host Host) context.Context {
+	return context.WithValue(ctx, hostKey{}, host)
+}
+
+// HostFrom returns the host services carried by ctx. When there are none it
+// returns a Host whose logging and progress are dropped and whose other
+// methods fail with ErrNoHost, so plugins can call it unconditionally.
+func HostFrom(ctx context.Context) (Host, bool) {
+	if host, ok := ctx.Value(hostKey{}).(Host); ok && host != nil {
+		return host, true
+	}
+	return noHost{}, false
+}
+
+type noHost struct{}
+
+func (noHost) Log(level, message string, fields map[string]interface{}) {}
+
+func (noHost) Get(key string) (interface{}, bool, error) { return nil, false, ErrNoHost }
+
+func (noHost) Set(key string, value interface{}) error { return ErrNoHost }
+
+func (noHost) Delete(key string) error { return ErrNoHost }
+
+func (noHost) Emit(event Event) error { return ErrNoHost }
+
+func (noHost) Progress(percent float64, message string) {}
+
+func (noHost) PutBlob(name, contentType string, r io.Reader) (Blob, error) { return Blob{}, ErrNoHost }
+
+func (noHost) OpenBlob(handle string) (io.ReadCloser, Blob, error) { return nil, Blob{}, ErrNoHost }
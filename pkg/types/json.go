@@ -0,0 +1,30 @@
+package types
+
+import "encoding/json"
+
+// NormalizeNumbers turns the json.Numbers in v, a value decoded with
+// json.Decoder.UseNumber, into int64 when they are integers and float64
+// otherwise, matching what gRPC plugins send. Maps and slices are updated in
+// place and returned.
+func NormalizeNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = NormalizeNumbers(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = NormalizeNumbers(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeNumbers(t *testing.T) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(`{"int": 42, "float": 1.5, "big": 1e30, "list": [1, 2.5, "3"], "nested": {"n": -7}}`)))
+	decoder.UseNumber()
+	var v interface{}
+	require.NoError(t, decoder.Decode(&v))
+
+	assert.Equal(t, map[string]interface{}{
+		"int":    int64(42),
+		"float":  1.5,
+		"big":    1e30,
+		"list":   []interface{}{int64(1), 2.5, "3"},
+		"nested": map[string]interface{}{"n": int64(-7)},
+	}, NormalizeNumbers(v))
+
+	assert.Equal(t, "plain", NormalizeNumbers("plain"))
+}
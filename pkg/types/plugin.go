@@ -1,6 +1,10 @@
 package types
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
 
 // Plugin interface for event-driven architecture
 type Plugin interface {
@@ -25,13 +29,124 @@ type VersionedPlugin interface {
 	MaxCLIVersion() string
 }
 
+// Timeouts bounds how long the host waits for each plugin RPC.
+// A zero value means the host default applies.
+type Timeouts struct {
+	ShouldExecute time.Duration `json:"should_execute"`
+	Process       time.Duration `json:"process"`
+}
+
+// timeoutsJSON is Timeouts with durations written as strings, like the
+// timeouts in the project config
+type timeoutsJSON struct {
+	ShouldExecute Duration `json:"should_execute"`
+	Process       Duration `json:"process"`
+}
+
+// MarshalJSON writes the timeouts as duration strings such as "30s"
+func (t Timeouts) MarshalJSON() ([]byte, error) {
+	return json.Marshal(timeoutsJSON{ShouldExecute: Duration(t.ShouldExecute), Process: Duration(t.Process)})
+}
+
+// UnmarshalJSON reads duration strings or numbers of nanoseconds
+func (t *Timeouts) UnmarshalJSON(data []byte) error {
+	var raw timeoutsJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*t = Timeouts{ShouldExecute: time.Duration(raw.ShouldExecute), Process: time.Duration(raw.Process)}
+	return nil
+}
+
+// TimeoutProvider is implemented by plugins that declare their own RPC deadlines
+type TimeoutProvider interface {
+	Timeouts() Timeouts
+}
+
+// Dependencies lists the context Properties keys a plugin reads and writes.
+// The pipeline uses them to order plugins so producers run before consumers.
+type Dependencies struct {
+	Requires []string `json:"requires,omitempty"`
+	Provides []string `json:"provides,omitempty"`
+
+	// Examples holds a typical value for Provides keys, such as "video"
+	// for media_type. Explaining a pipeline sets them in place of running
+	// the plugin, so downstream plugins see values they can decide on.
+	Examples map[string]interface{} `json:"examples,omitempty"`
+
+	// RequiresHost marks plugins that cannot do their work without host
+	// services, e.g. to read attachments. The CLI refuses to load them where
+	// it cannot offer any, such as from a remote plugin served over TCP.
+	RequiresHost bool `json:"requires_host,omitempty"`
+}
+
+// DependencyProvider is implemented by plugins that declare their Properties keys
+type DependencyProvider interface {
+	Dependencies() Dependencies
+}
+
+// Configurable is implemented by plugins that take settings from the
+// project config. Configure is called once, before any event; returning an
+// error fails loading the plugin.
+type Configurable interface {
+	Configure(ctx context.Context, config map[string]interface{}) error
+}
+
+// Initializer is implemented by plugins that acquire resources, such as
+// connections or temp directories, before handling events. Init is called
+// after Configure; returning an error fails loading the plugin.
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// Shutdowner is implemented by plugins that release resources before their
+// process is killed. The context's deadline is the grace period.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// HealthStatus is a plugin's own view of whether it can handle events
+type HealthStatus string
+
+const (
+	HealthServing   HealthStatus = "serving"
+	HealthDegraded  HealthStatus = "degraded"
+	HealthUnhealthy HealthStatus = "unhealthy"
+	// HealthUnknown is reported by the host for plugins that predate health checks
+	HealthUnknown HealthStatus = "unknown"
+)
+
+// Health is the result of a health check
+type Health struct {
+	Status  HealthStatus `json:"status"`
+	Message string       `json:"message,omitempty"`
+}
+
+// HealthChecker is implemented by plugins that report their health; others
+// are considered serving as long as their process answers
+type HealthChecker interface {
+	Health(ctx context.Context) Health
+}
+
+// FallibleDecider is implemented by plugin clients that can report a failed
+// ShouldExecute call instead of folding it into a decision not to execute
+type FallibleDecider interface {
+	Decide(ctx context.Context, context *Context) (ExecutionDecision, error)
+}
+
 // PluginMetadata for serialization
 type PluginMetadata struct {
-	Name          string `json:"name"`
-	Version       string `json:"version"`
-	BuildTime     string `json:"build_time"`
-	MinCLIVersion string `json:"min_cli_version"`
-	MaxCLIVersion string `json:"max_cli_version"`
-	Description   string `json:"description"`
-	Priority      int    `json:"priority"`
+	Name          string       `json:"name"`
+	Version       string       `json:"version"`
+	BuildTime     string       `json:"build_time"`
+	MinCLIVersion string       `json:"min_cli_version"`
+	MaxCLIVersion string       `json:"max_cli_version"`
+	Description   string       `json:"description"`
+	Priority      int          `json:"priority"`
+	Timeouts      Timeouts     `json:"timeouts"`
+	Dependencies  Dependencies `json:"dependencies"`
+
+	// ProtocolVersion is the wire protocol version negotiated with the
+	// plugin process; zero when the plugin was not loaded over gRPC
+	ProtocolVersion int `json:"protocol_version,omitempty"`
 }
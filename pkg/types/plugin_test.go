@@ -0,0 +1,27 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeouts_JSON(t *testing.T) {
+	timeouts := Timeouts{ShouldExecute: 2 * time.Second, Process: 90 * time.Second}
+
+	data, err := json.Marshal(timeouts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"should_execute": "2s", "process": "1m30s"}`, string(data))
+
+	var decoded Timeouts
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, timeouts, decoded)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"process": 5000000000}`), &decoded))
+	assert.Equal(t, Timeouts{Process: 5 * time.Second}, decoded)
+
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"process": "soon"}`), &decoded), `invalid duration "soon"`)
+}
@@ -2,17 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
-	"github.com/hashicorp/go-plugin"
-	"github.com/williamokano/hashicorp-plugin-example/shared"
-)
-
-var (
-	Version   = "1.0.0"
-	BuildTime = "unknown"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/sdk"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
 
 const (
@@ -20,119 +20,250 @@ const (
 	mediaTypeImage = "image"
 )
 
-type ConverterPlugin struct{}
+// Output formats the converter can produce
+var (
+	videoFormats = []string{"mp4", "webm", "mkv"}
+	imageFormats = []string{"jpeg", "png", "webp"}
+)
+
+// contentTypes maps output formats to the content type of converted files
+var contentTypes = map[string]string{
+	"mp4":  "video/mp4",
+	"webm": "video/webm",
+	"mkv":  "video/x-matroska",
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"webp": "image/webp",
+}
+
+type ConverterPlugin struct {
+	sdk.Base
+
+	videoFormat string
+	imageFormat string
+
+	// workDir holds converted files for hosts without blob support until the
+	// plugin shuts down
+	workDir string
+
+	// remote is set when the converter is served to CLIs over the network,
+	// which cannot read the files it writes
+	remote bool
+}
+
+// Configure reads the output formats, rejecting ones the converter cannot produce
+func (p *ConverterPlugin) Configure(ctx context.Context, values map[string]interface{}) error {
+	config := sdk.Config(values)
+
+	videoFormat, err := config.String("video_format", "mp4")
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(videoFormats, videoFormat) {
+		return types.InvalidInput(fmt.Sprintf("unsupported video_format %q, expected one of %v", videoFormat, videoFormats))
+	}
+
+	imageFormat, err := config.String("image_format", "jpeg")
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(imageFormats, imageFormat) {
+		return types.InvalidInput(fmt.Sprintf("unsupported image_format %q, expected one of %v", imageFormat, imageFormats))
+	}
+
+	p.videoFormat, p.imageFormat = videoFormat, imageFormat
+	return nil
+}
+
+// Init creates the directory converted files are written to
+func (p *ConverterPlugin) Init(ctx context.Context) error {
+	dir, err := os.MkdirTemp("", "media-converter-")
+	if err != nil {
+		return types.Unavailable("cannot create work directory", err)
+	}
+	p.workDir = dir
+	return nil
+}
+
+// Health reports the converter unhealthy once its work directory is gone
+func (p *ConverterPlugin) Health(ctx context.Context) types.Health {
+	if p.workDir == "" {
+		return types.Health{Status: types.HealthDegraded, Message: "not initialized, writing to " + os.TempDir()}
+	}
+	if _, err := os.Stat(p.workDir); err != nil {
+		return types.Health{Status: types.HealthUnhealthy, Message: "work directory missing"}
+	}
+	return types.Health{Status: types.HealthServing}
+}
 
-func (p *ConverterPlugin) ShouldExecute(ctx context.Context, context *shared.Context) shared.ExecutionDecision {
+// Shutdown removes the converted files
+func (p *ConverterPlugin) Shutdown(ctx context.Context) error {
+	if p.workDir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(p.workDir); err != nil {
+		return fmt.Errorf("failed to remove work directory: %w", err)
+	}
+	p.workDir = ""
+	return nil
+}
+
+func (p *ConverterPlugin) ShouldExecute(ctx context.Context, context *types.Context) types.ExecutionDecision {
 	// Check if conversion is needed
-	action, hasAction := context.Properties["action"]
-	if !hasAction || action != "convert" {
-		return shared.ExecutionDecision{
-			ShouldExecute: false,
-			Reason:        "No conversion action required",
-		}
+	if sdk.String(context, "action") != "convert" {
+		return sdk.Skip("No conversion action required")
 	}
 
 	// Check media type
-	mediaType, hasMedia := context.Properties["media_type"]
-	if !hasMedia {
-		return shared.ExecutionDecision{
-			ShouldExecute: false,
-			Reason:        "No media type specified",
-		}
+	if !sdk.Has(context, "media_type") {
+		return sdk.Skip("No media type specified")
 	}
 
 	// We can handle video and image
+	mediaType := sdk.String(context, "media_type")
 	if mediaType == mediaTypeVideo || mediaType == mediaTypeImage {
-		return shared.ExecutionDecision{
-			ShouldExecute: true,
-			Reason:        fmt.Sprintf("Ready to convert %s", mediaType),
-		}
+		return sdk.Execute(fmt.Sprintf("Ready to convert %s", mediaType))
 	}
 
-	return shared.ExecutionDecision{
-		ShouldExecute: false,
-		Reason:        fmt.Sprintf("Cannot convert media type: %s", mediaType),
-	}
+	return sdk.Skip(fmt.Sprintf("Cannot convert media type: %v", context.Properties["media_type"]))
 }
 
-func (p *ConverterPlugin) Process(ctx context.Context, context *shared.Context) (*shared.Context, error) {
-	mediaType := context.Properties["media_type"].(string)
+func (p *ConverterPlugin) Process(ctx context.Context, context *types.Context) (*types.Context, error) {
+	mediaType := sdk.String(context, "media_type")
+
+	host, _ := types.HostFrom(ctx)
+	host.Progress(0, "converting "+mediaType)
 
 	// Simulate conversion process
-	var outputFile string
+	var format, ext string
 	var conversionDetails map[string]interface{}
 
 	if mediaType == mediaTypeVideo {
-		outputFile = fmt.Sprintf("/tmp/converted_%d.mp4", time.Now().Unix())
+		format, ext = p.videoFormat, p.videoFormat
 		conversionDetails = map[string]interface{}{
-			"format":     "mp4",
+			"format":     p.videoFormat,
 			"codec":      "h264",
 			"resolution": "1920x1080",
 			"duration":   "120s",
 		}
 	} else {
-		outputFile = fmt.Sprintf("/tmp/converted_%d.jpg", time.Now().Unix())
+		format, ext = p.imageFormat, extension(p.imageFormat)
 		conversionDetails = map[string]interface{}{
-			"format":     "jpeg",
+			"format":     p.imageFormat,
 			"quality":    "95",
 			"resolution": "1920x1080",
 		}
 	}
 
-	// Add file path to context for next plugins
-	context.Properties["file_path"] = outputFile
-	context.Properties["conversion_complete"] = true
-	context.Properties["conversion_details"] = conversionDetails
-
-	// Add response
-	context.Responses = append(context.Responses, shared.Response{
-		PluginName: p.Name(),
-		Type:       "conversion",
-		Content:    fmt.Sprintf("%s converted successfully to %s", mediaType, outputFile),
-		Data:       conversionDetails,
-	})
+	// Convert the event's attachment if it has one; the conversion is a
+	// straight copy streamed from the host and back
+	name := fmt.Sprintf("converted_%d.%s", time.Now().Unix(), ext)
+	var source io.Reader = strings.NewReader("")
+	if len(context.Event.Attachments) > 0 {
+		attachment := context.Event.Attachments[0]
+		r, _, err := host.OpenBlob(attachment.Handle)
+		if errors.Is(err, types.ErrNoHost) {
+			return nil, noHost("cannot read attachment "+attachment.Name, err)
+		}
+		if err != nil {
+			return nil, types.Unavailable("cannot read attachment "+attachment.Name, err)
+		}
+		defer func() { _ = r.Close() }()
+		source = r
+		name = strings.TrimSuffix(attachment.Name, filepath.Ext(attachment.Name)) + "." + ext
+	}
 
-	return context, nil
-}
+	blob, err := host.PutBlob(name, contentTypes[format], source)
+	switch {
+	case errors.Is(err, types.ErrNoHost) && p.remote:
+		// A path on this machine would mean nothing to the CLI
+		return nil, noHost("cannot return the converted file", err)
+	case errors.Is(err, types.ErrNoHost):
+		// Hosts that predate blobs only understand file paths
+		outputFile, err := p.writeFile(name, source)
+		if err != nil {
+			return nil, err
+		}
+		sdk.Set(context, "file_path", outputFile)
+		p.Respond(context, "conversion", fmt.Sprintf("%s converted successfully to %s", mediaType, outputFile), conversionDetails)
+	case err != nil:
+		return nil, types.Unavailable("cannot store converted file", err)
+	default:
+		sdk.Set(context, "artifact", blob.Handle)
+		p.Respond(context, "conversion", fmt.Sprintf("%s converted successfully to %s", mediaType, blob.Name), conversionDetails)
+		p.RespondFile(context, blob.Name, blob)
+	}
 
-func (p *ConverterPlugin) Name() string {
-	return "media-converter"
-}
+	host.Progress(100, "conversion complete")
+	host.Log(types.LogInfo, "converted media", map[string]interface{}{"media_type": mediaType, "output": name})
 
-func (p *ConverterPlugin) Version() string {
-	return Version
+	// Add the result to context for next plugins
+	sdk.Set(context, "conversion_complete", true)
+	sdk.Set(context, "conversion_details", conversionDetails)
+	return context, nil
 }
 
-func (p *ConverterPlugin) BuildTime() string {
-	if BuildTime == "unknown" {
-		return time.Now().Format(time.RFC3339)
+// writeFile writes a converted file into the work directory, or the system
+// temp directory when the host never called Init
+func (p *ConverterPlugin) writeFile(name string, r io.Reader) (string, error) {
+	dir := p.workDir
+	if dir == "" {
+		dir = os.TempDir()
 	}
-	return BuildTime
-}
 
-func (p *ConverterPlugin) MinCLIVersion() string {
-	return "1.0.0"
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec // G304: name is built by the plugin
+	if err != nil {
+		return "", types.Unavailable("cannot write converted file", err)
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", types.Unavailable("cannot write converted file", err)
+	}
+	return path, nil
 }
 
-func (p *ConverterPlugin) MaxCLIVersion() string {
-	return "2.0.0"
+// noHost reports a conversion that needs host services the CLI does not
+// offer; trying again will not help
+func noHost(message string, err error) *types.PluginError {
+	e := types.Unavailable(message+": serve the converter where the CLI offers host services", err)
+	e.Retryable = false
+	return e
 }
 
-func (p *ConverterPlugin) Description() string {
-	return "Converts media files (video/image) to optimized formats"
+// extension returns the file extension for an output format
+func extension(format string) string {
+	if format == "jpeg" {
+		return "jpg"
+	}
+	return format
 }
 
-func (p *ConverterPlugin) Priority() int {
-	return 30 // Runs after filter, before uploader
+func newPlugin() *ConverterPlugin {
+	return &ConverterPlugin{videoFormat: "mp4", imageFormat: "jpeg", Base: sdk.Base{Info: sdk.Info{
+		Name:        "media-converter",
+		Description: "Converts media files (video/image) to optimized formats",
+		Priority:    30, // Runs after filter, before uploader
+		Version:     "1.0.0",
+		Timeouts: types.Timeouts{
+			ShouldExecute: 2 * time.Second,
+			Process:       5 * time.Minute, // Media conversion can be slow
+		},
+		Dependencies: types.Dependencies{
+			Requires: []string{"action", "media_type"},
+			Provides: []string{"artifact", "file_path", "conversion_complete", "conversion_details"},
+			Examples: map[string]interface{}{"file_path": "converted.mp4", "conversion_complete": true},
+			// Attachments and converted files travel as blobs
+			RequiresHost: true,
+		},
+	}}}
 }
 
 func main() {
-	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: shared.Handshake,
-		Plugins: map[string]plugin.Plugin{
-			"plugin": &shared.GRPCPlugin{Impl: &ConverterPlugin{}},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-	})
-	os.Exit(0)
+	p := newPlugin()
+	p.remote = os.Getenv(sdk.EnvListen) != ""
+	sdk.Serve(p)
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/plugintest"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestConverterPlugin(t *testing.T) {
+	h := plugintest.New(t, newPlugin())
+
+	h.Run(plugintest.Message("convert this video"), map[string]interface{}{"action": "convert", "media_type": "video"}).
+		AssertExecuted().
+		AssertProperty("conversion_complete", true).
+		AssertResponse("conversion", "video converted successfully")
+
+	h.Run(plugintest.Message("convert this"), map[string]interface{}{"action": "convert", "media_type": "audio"}).
+		AssertSkipped("Cannot convert media type: audio")
+
+	h.Run(plugintest.Message("hello"), nil).
+		AssertSkipped("No conversion action required")
+
+	assert.Equal(t, []plugintest.ProgressUpdate{{Percent: 0, Message: "converting video"}, {Percent: 100, Message: "conversion complete"}}, h.Host.Updates)
+	assert.Equal(t, "converted media", h.Host.Logs[0].Message)
+}
+
+func TestConverterPlugin_Config(t *testing.T) {
+	h := plugintest.New(t, newPlugin(), plugintest.WithConfig(map[string]interface{}{"video_format": "webm", "image_format": "png"}))
+
+	h.Run(plugintest.Message("convert this image"), map[string]interface{}{"action": "convert", "media_type": "image"}).
+		AssertExecuted().
+		AssertResponse("conversion", ".png")
+
+	err := h.Configure(map[string]interface{}{"video_format": "avi"})
+	assert.ErrorContains(t, err, `unsupported video_format "avi"`)
+	assert.Equal(t, types.ErrorCodeInvalidInput, types.AsPluginError(err).Code)
+}
+
+func TestConverterPlugin_Attachment(t *testing.T) {
+	h := plugintest.New(t, newPlugin(), plugintest.WithConfig(map[string]interface{}{"video_format": "webm"}))
+
+	event := plugintest.Message("convert this video")
+	event.Attachments = []types.Blob{h.Host.AddBlob("holiday.mov", "video/quicktime", []byte("frames"))}
+
+	result := h.Run(event, map[string]interface{}{"action": "convert", "media_type": "video"}).
+		AssertExecuted().
+		AssertResponse("file", "holiday.webm")
+
+	handle := result.Context.Properties["artifact"].(string)
+	data, ok := h.Host.Blob(handle)
+	require.True(t, ok)
+	assert.Equal(t, "frames", string(data))
+
+	blob, ok := result.Context.Responses[len(result.Context.Responses)-1].Blob()
+	require.True(t, ok)
+	assert.Equal(t, types.Blob{Handle: handle, Name: "holiday.webm", ContentType: "video/webm", Size: 6}, blob)
+}
+
+func TestConverterPlugin_Lifecycle(t *testing.T) {
+	h := plugintest.New(t, newPlugin())
+	assert.Equal(t, types.HealthServing, h.Health().Status)
+
+	// Without host services the converted file is written to the work directory
+	c := plugintest.NewContext(plugintest.Message("convert this video"), map[string]interface{}{"action": "convert", "media_type": "video"})
+	result, err := h.Plugin.Process(context.Background(), c)
+	require.NoError(t, err)
+	output := result.Properties["file_path"].(string)
+	assert.FileExists(t, output)
+
+	require.NoError(t, h.Shutdown())
+	assert.NoFileExists(t, output)
+	assert.Equal(t, types.HealthDegraded, h.Health().Status)
+}
+
+func TestConverterPlugin_RemoteWithoutHost(t *testing.T) {
+	p := newPlugin()
+	p.remote = true
+	h := plugintest.New(t, p)
+
+	// The CLI cannot read a file written where a remote converter runs
+	c := plugintest.NewContext(plugintest.Message("convert this video"), map[string]interface{}{"action": "convert", "media_type": "video"})
+	_, err := h.Plugin.Process(context.Background(), c)
+	require.Error(t, err)
+	assert.False(t, types.IsRetryable(err))
+
+	// Nor can it read an attachment
+	c.Event.Attachments = []types.Blob{{Handle: "blob-1", Name: "holiday.mov"}}
+	_, err = h.Plugin.Process(context.Background(), c)
+	assert.ErrorContains(t, err, "cannot read attachment holiday.mov")
+	assert.False(t, types.IsRetryable(err))
+}
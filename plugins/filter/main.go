@@ -2,119 +2,99 @@ package main
 
 import (
 	"context"
-	"os"
 	"strings"
 	"time"
 
-	"github.com/hashicorp/go-plugin"
-	"github.com/williamokano/hashicorp-plugin-example/shared"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/sdk"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
 
-var (
-	Version   = "1.0.0"
-	BuildTime = "unknown"
-)
+// defaultKeywords trigger the pipeline unless the config lists others
+var defaultKeywords = []string{"convert", "upload", "process", "help"}
+
+type FilterPlugin struct {
+	sdk.Base
+
+	keywords []string
+}
 
-type FilterPlugin struct{}
+// Configure reads the keywords that mark a message as actionable
+func (p *FilterPlugin) Configure(ctx context.Context, values map[string]interface{}) error {
+	keywords, err := sdk.Config(values).Strings("keywords", defaultKeywords)
+	if err != nil {
+		return err
+	}
+	if len(keywords) == 0 {
+		return types.InvalidInput("keywords must not be empty")
+	}
+
+	p.keywords = make([]string, len(keywords))
+	for i, keyword := range keywords {
+		p.keywords[i] = strings.ToLower(keyword)
+	}
+	return nil
+}
 
-func (p *FilterPlugin) ShouldExecute(ctx context.Context, context *shared.Context) shared.ExecutionDecision {
+func (p *FilterPlugin) ShouldExecute(ctx context.Context, context *types.Context) types.ExecutionDecision {
 	// Only process message events
-	if context.Event.Type != shared.EventMessage {
-		return shared.ExecutionDecision{
-			ShouldExecute: false,
-			Reason:        "Not a message event",
-		}
+	if context.Event.Type != types.EventMessage {
+		return sdk.Skip("Not a message event")
 	}
 
 	// Check if message contains keywords we care about
-	keywords := []string{"convert", "upload", "process", "help"}
 	content := strings.ToLower(context.Event.Content)
 
-	for _, keyword := range keywords {
+	for _, keyword := range p.keywords {
 		if strings.Contains(content, keyword) {
-			return shared.ExecutionDecision{
-				ShouldExecute: true,
-				Reason:        "Message contains actionable keyword",
-			}
+			return sdk.Execute("Message contains actionable keyword")
 		}
 	}
 
-	return shared.ExecutionDecision{
-		ShouldExecute: false,
-		Reason:        "No actionable keywords found",
-	}
+	return sdk.Skip("No actionable keywords found")
 }
 
-func (p *FilterPlugin) Process(ctx context.Context, context *shared.Context) (*shared.Context, error) {
+func (p *FilterPlugin) Process(ctx context.Context, context *types.Context) (*types.Context, error) {
 	// Extract command from message
 	content := strings.ToLower(context.Event.Content)
 
 	// Set properties based on detected intent
 	if strings.Contains(content, "convert") {
-		context.Properties["action"] = "convert"
+		sdk.Set(context, "action", "convert")
 		if strings.Contains(content, "video") {
-			context.Properties["media_type"] = "video"
+			sdk.Set(context, "media_type", "video")
 		} else if strings.Contains(content, "image") {
-			context.Properties["media_type"] = "image"
+			sdk.Set(context, "media_type", "image")
 		}
 	}
 
 	if strings.Contains(content, "upload") {
-		context.Properties["needs_upload"] = true
+		sdk.Set(context, "needs_upload", true)
+	}
+
+	// A plain help request is answered here; nothing downstream needs to see it
+	if !sdk.Has(context, "action") && strings.Contains(content, "help") && !strings.Contains(content, "upload") {
+		p.Respond(context, "text", "Try: \"convert this video\", \"convert this image\" or \"upload the file\"", nil)
+		context.Stop("help request answered")
+		return context, nil
 	}
 
 	// Add a response indicating the filter processed the message
-	context.Responses = append(context.Responses, shared.Response{
-		PluginName: p.Name(),
-		Type:       "status",
-		Content:    "Message filtered and categorized",
-		Data: map[string]interface{}{
-			"detected_action": context.Properties["action"],
-			"timestamp":       time.Now().Unix(),
-		},
+	p.Respond(context, "status", "Message filtered and categorized", map[string]interface{}{
+		"detected_action": context.Properties["action"],
+		"timestamp":       time.Now().Unix(),
 	})
-
 	return context, nil
 }
 
-func (p *FilterPlugin) Name() string {
-	return "message-filter"
-}
-
-func (p *FilterPlugin) Version() string {
-	return Version
-}
-
-func (p *FilterPlugin) BuildTime() string {
-	if BuildTime == "unknown" {
-		return time.Now().Format(time.RFC3339)
-	}
-	return BuildTime
-}
-
-func (p *FilterPlugin) MinCLIVersion() string {
-	return "1.0.0"
-}
-
-func (p *FilterPlugin) MaxCLIVersion() string {
-	return "2.0.0"
-}
-
-func (p *FilterPlugin) Description() string {
-	return "Filters and categorizes incoming messages"
-}
-
-func (p *FilterPlugin) Priority() int {
-	return 10 // Runs early in the pipeline
-}
-
 func main() {
-	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: shared.Handshake,
-		Plugins: map[string]plugin.Plugin{
-			"plugin": &shared.GRPCPlugin{Impl: &FilterPlugin{}},
+	sdk.Serve(&FilterPlugin{keywords: defaultKeywords, Base: sdk.Base{Info: sdk.Info{
+		Name:        "message-filter",
+		Description: "Filters and categorizes incoming messages",
+		Priority:    10, // Runs early in the pipeline
+		Version:     "1.0.0",
+		Dependencies: types.Dependencies{
+			Provides: []string{"action", "media_type", "needs_upload"},
+			Examples: map[string]interface{}{"action": "convert", "media_type": "video", "needs_upload": true},
 		},
-		GRPCServer: plugin.DefaultGRPCServer,
-	})
-	os.Exit(0)
+	}}})
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/williamokano/hashicorp-plugin-example/pkg/sdk"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+// WordsPlugin counts the words of a message
+type WordsPlugin struct {
+	sdk.Base
+}
+
+func (p *WordsPlugin) ShouldExecute(ctx context.Context, pipelineCtx *types.Context) types.ExecutionDecision {
+	if pipelineCtx.Event.Type != types.EventMessage {
+		return sdk.Skip("Not a message event")
+	}
+	return sdk.Execute("Counting the words of the message")
+}
+
+func (p *WordsPlugin) Process(ctx context.Context, pipelineCtx *types.Context) (*types.Context, error) {
+	sdk.Set(pipelineCtx, "word_count", int64(len(strings.Fields(pipelineCtx.Event.Content))))
+	return pipelineCtx, nil
+}
+
+// MentionsPlugin collects the @mentions of a message
+type MentionsPlugin struct {
+	sdk.Base
+}
+
+func (p *MentionsPlugin) ShouldExecute(ctx context.Context, pipelineCtx *types.Context) types.ExecutionDecision {
+	if pipelineCtx.Event.Type != types.EventMessage {
+		return sdk.Skip("Not a message event")
+	}
+	if len(mentions(pipelineCtx.Event.Content)) == 0 {
+		return sdk.Skip("No one is mentioned")
+	}
+	return sdk.Execute("Message mentions someone")
+}
+
+func (p *MentionsPlugin) Process(ctx context.Context, pipelineCtx *types.Context) (*types.Context, error) {
+	sdk.Set(pipelineCtx, "mentions", mentions(pipelineCtx.Event.Content))
+	return pipelineCtx, nil
+}
+
+// mentions returns the handles mentioned as @handle, without the @
+func mentions(content string) []string {
+	var handles []string
+	for _, word := range strings.Fields(content) {
+		handle := strings.TrimRight(strings.TrimPrefix(word, "@"), ".,;:!?")
+		if strings.HasPrefix(word, "@") && handle != "" {
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
+
+// newPlugins returns the plugins this binary serves, by their name in the
+// bundle; the CLI knows them as text.words and text.mentions
+func newPlugins() map[string]types.VersionedPlugin {
+	return map[string]types.VersionedPlugin{
+		"words": &WordsPlugin{Base: sdk.Base{Info: sdk.Info{
+			Name:        "text-words",
+			Description: "Counts the words of messages",
+			Priority:    20, // Runs after filter
+			Version:     "1.0.0",
+			Dependencies: types.Dependencies{
+				Provides: []string{"word_count"},
+			},
+		}}},
+		"mentions": &MentionsPlugin{Base: sdk.Base{Info: sdk.Info{
+			Name:        "text-mentions",
+			Description: "Collects the @mentions of messages",
+			Priority:    20, // Runs after filter
+			Version:     "1.0.0",
+			Dependencies: types.Dependencies{
+				Provides: []string{"mentions"},
+			},
+		}}},
+	}
+}
+
+func main() {
+	sdk.ServePlugins(newPlugins())
+}
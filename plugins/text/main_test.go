@@ -0,0 +1,30 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/plugintest"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
+)
+
+func TestTextPlugins(t *testing.T) {
+	plugins := newPlugins()
+	assert.NoError(t, protocol.ValidateBundle(slices.Collect(maps.Keys(plugins))))
+
+	words := plugintest.New(t, plugins["words"])
+	words.Run(plugintest.Message("ping @alice and @bob, please"), nil).
+		AssertExecuted().
+		AssertProperty("word_count", int64(5))
+	words.Run(plugintest.Command("/help"), nil).
+		AssertSkipped("Not a message event")
+
+	mentions := plugintest.New(t, plugins["mentions"])
+	mentions.Run(plugintest.Message("ping @alice and @bob, please"), nil).
+		AssertExecuted().
+		AssertProperty("mentions", []interface{}{"alice", "bob"})
+	mentions.Run(plugintest.Message("hello @ everyone"), nil).
+		AssertSkipped("No one is mentioned")
+}
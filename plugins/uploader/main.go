@@ -2,111 +2,187 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 	"time"
 
-	"github.com/hashicorp/go-plugin"
-	"github.com/williamokano/hashicorp-plugin-example/shared"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/sdk"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
 
-var (
-	Version   = "1.0.0"
-	BuildTime = "unknown"
-)
+type UploaderPlugin struct {
+	sdk.Base
+
+	baseURL string
+	bucket  string
+}
+
+// Configure reads where files are uploaded to
+func (p *UploaderPlugin) Configure(ctx context.Context, values map[string]interface{}) error {
+	config := sdk.Config(values)
 
-type UploaderPlugin struct{}
+	baseURL, err := config.String("base_url", "https://s3.example.com")
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(baseURL, "https://") && !strings.HasPrefix(baseURL, "http://") {
+		return types.InvalidInput(fmt.Sprintf("base_url must be an http(s) URL, got %q", baseURL))
+	}
 
-func (p *UploaderPlugin) ShouldExecute(ctx context.Context, context *shared.Context) shared.ExecutionDecision {
+	bucket, err := config.String("bucket", "uploads")
+	if err != nil {
+		return err
+	}
+	if bucket == "" {
+		return types.InvalidInput("bucket must not be empty")
+	}
+
+	p.baseURL, p.bucket = strings.TrimSuffix(baseURL, "/"), bucket
+	return nil
+}
+
+func (p *UploaderPlugin) ShouldExecute(ctx context.Context, context *types.Context) types.ExecutionDecision {
 	// Check if upload is needed based on context properties
-	needsUpload, ok := context.Properties["needs_upload"].(bool)
-	if !ok || !needsUpload {
-		return shared.ExecutionDecision{
-			ShouldExecute: false,
-			Reason:        "Upload not required",
-		}
+	if !sdk.Bool(context, "needs_upload") {
+		return sdk.Skip("Upload not required")
 	}
 
 	// Check if there's a file to upload (set by previous plugin)
-	if _, hasFile := context.Properties["file_path"]; !hasFile {
-		return shared.ExecutionDecision{
-			ShouldExecute: false,
-			Reason:        "No file to upload",
-		}
+	if !sdk.Has(context, "artifact") && !sdk.Has(context, "file_path") {
+		return sdk.Skip("No file to upload")
 	}
 
-	return shared.ExecutionDecision{
-		ShouldExecute: true,
-		Reason:        "File ready for upload",
-	}
+	return sdk.Execute("File ready for upload")
 }
 
-func (p *UploaderPlugin) Process(ctx context.Context, context *shared.Context) (*shared.Context, error) {
-	filePath, _ := context.Properties["file_path"].(string)
+func (p *UploaderPlugin) Process(ctx context.Context, context *types.Context) (*types.Context, error) {
+	host, _ := types.HostFrom(ctx)
 
-	// Simulate uploading to S3
-	uploadedURL := fmt.Sprintf("https://s3.example.com/uploads/%d/%s",
+	file, err := p.open(host, context)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	// Simulate uploading to S3 by streaming the file to nowhere
+	size, err := io.Copy(io.Discard, file)
+	if err != nil {
+		return nil, types.Unavailable("failed to read "+file.name, err)
+	}
+	uploadedURL := fmt.Sprintf("%s/%s/%d/%s",
+		p.baseURL,
+		p.bucket,
 		time.Now().Unix(),
-		filePath)
+		filepath.Base(file.name))
+
+	// Keep a running count of uploads across events
+	uploads, _, _ := host.Get("uploads")
+	count, _ := uploads.(int64)
+	count++
+	if err := host.Set("uploads", count); err != nil && !errors.Is(err, types.ErrNoHost) {
+		host.Log(types.LogWarn, "failed to record upload", map[string]interface{}{"error": err.Error()})
+	}
+	host.Log(types.LogInfo, "uploaded file", map[string]interface{}{"url": uploadedURL, "total_uploads": count})
 
 	// Add upload URL to context for other plugins to use
-	context.Properties["uploaded_url"] = uploadedURL
-	context.Properties["upload_timestamp"] = time.Now().Unix()
-
-	// Add response
-	context.Responses = append(context.Responses, shared.Response{
-		PluginName: p.Name(),
-		Type:       "upload",
-		Content:    fmt.Sprintf("File uploaded successfully to %s", uploadedURL),
-		Data: map[string]interface{}{
-			"url":        uploadedURL,
-			"original":   filePath,
-			"size_bytes": 1024 * 50,   // Simulated
-			"mime_type":  "video/mp4", // Simulated
-		},
+	sdk.Set(context, "uploaded_url", uploadedURL)
+	sdk.Set(context, "upload_timestamp", time.Now().Unix())
+
+	p.Respond(context, "upload", fmt.Sprintf("File uploaded successfully to %s", uploadedURL), map[string]interface{}{
+		"url":        uploadedURL,
+		"original":   file.name,
+		"size_bytes": size,
+		"mime_type":  file.contentType,
 	})
-
 	return context, nil
 }
 
-func (p *UploaderPlugin) Name() string {
-	return "s3-uploader"
+// upload is the file being uploaded
+type upload struct {
+	io.ReadCloser
+	name        string
+	contentType string
 }
 
-func (p *UploaderPlugin) Version() string {
-	return Version
-}
+// open opens the blob named by the artifact property, or the file at
+// file_path when an older converter put one on the shared filesystem
+func (p *UploaderPlugin) open(host types.Host, context *types.Context) (*upload, error) {
+	if handle := sdk.String(context, "artifact"); handle != "" {
+		r, blob, err := host.OpenBlob(handle)
+		if err != nil {
+			return nil, types.Unavailable("cannot read artifact", err)
+		}
+		return &upload{ReadCloser: r, name: blob.Name, contentType: blob.ContentType}, nil
+	}
 
-func (p *UploaderPlugin) BuildTime() string {
-	if BuildTime == "unknown" {
-		return time.Now().Format(time.RFC3339)
+	filePath := sdk.String(context, "file_path")
+	f, err := os.Open(filePath) //nolint:gosec // G304: the path comes from an earlier plugin
+	if err != nil {
+		return nil, types.InvalidInput(fmt.Sprintf("cannot open file_path: %v", err))
 	}
-	return BuildTime
+	return &upload{ReadCloser: f, name: filePath, contentType: mime.TypeByExtension(filepath.Ext(filePath))}, nil
 }
 
-func (p *UploaderPlugin) MinCLIVersion() string {
-	return "1.0.0"
+// Commands contributes plugin-cli uploader list-buckets
+func (p *UploaderPlugin) Commands(ctx context.Context) ([]types.Command, error) {
+	return []types.Command{{
+		Name:  "list-buckets",
+		Short: "List the buckets files can be uploaded to",
+		Long:  "List the buckets at base_url that the uploader can upload to. The default bucket is the one set by the bucket config key.",
+		Flags: []types.CommandFlag{
+			{Name: "output", Shorthand: "o", Default: "table", Usage: "Output format: table or json"},
+		},
+	}}, nil
 }
 
-func (p *UploaderPlugin) MaxCLIVersion() string {
-	return "2.0.0"
+// bucket is one bucket listed by list-buckets
+type bucket struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Default bool   `json:"default"`
 }
 
-func (p *UploaderPlugin) Description() string {
-	return "Uploads files to S3 when needed"
+func (p *UploaderPlugin) RunCommand(ctx context.Context, req types.CommandRequest, out io.Writer) error {
+	// Simulate listing S3 buckets with the one uploads go to
+	buckets := []bucket{{Name: p.bucket, URL: p.baseURL + "/" + p.bucket, Default: true}}
+
+	switch output, _ := req.Flags["output"].(string); output {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(buckets)
+	case "table":
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		_, _ = fmt.Fprintln(w, "NAME\tURL\tDEFAULT")
+		for _, b := range buckets {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%t\n", b.Name, b.URL, b.Default)
+		}
+		return w.Flush()
+	default:
+		return types.InvalidInput(fmt.Sprintf("unknown output format %q, want table or json", output))
+	}
 }
 
-func (p *UploaderPlugin) Priority() int {
-	return 50 // Runs after processing plugins
+func newPlugin() *UploaderPlugin {
+	return &UploaderPlugin{baseURL: "https://s3.example.com", bucket: "uploads", Base: sdk.Base{Info: sdk.Info{
+		Name:        "s3-uploader",
+		Description: "Uploads files to S3 when needed",
+		Priority:    50, // Runs after processing plugins
+		Version:     "1.0.0",
+		Dependencies: types.Dependencies{
+			Requires: []string{"needs_upload", "artifact"},
+			Provides: []string{"uploaded_url", "upload_timestamp"},
+		},
+	}}}
 }
 
 func main() {
-	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: shared.Handshake,
-		Plugins: map[string]plugin.Plugin{
-			"plugin": &shared.GRPCPlugin{Impl: &UploaderPlugin{}},
-		},
-		GRPCServer: plugin.DefaultGRPCServer,
-	})
-	os.Exit(0)
+	sdk.Serve(newPlugin())
 }
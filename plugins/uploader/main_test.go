@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/plugintest"
+	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
+)
+
+func TestUploaderPlugin_ListBuckets(t *testing.T) {
+	h := plugintest.New(t, newPlugin(), plugintest.WithConfig(map[string]interface{}{"base_url": "https://files.example.com/", "bucket": "media"}))
+
+	out, err := h.RunCommand("list-buckets", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "NAME    URL                               DEFAULT\nmedia   https://files.example.com/media   true\n", out)
+
+	out, err = h.RunCommand("list-buckets", nil, map[string]interface{}{"output": "json"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"name": "media", "url": "https://files.example.com/media", "default": true}]`, out)
+
+	_, err = h.RunCommand("list-buckets", nil, map[string]interface{}{"output": "yaml"})
+	assert.ErrorContains(t, err, `unknown output format "yaml"`)
+	assert.Equal(t, types.ErrorCodeInvalidInput, types.AsPluginError(err).Code)
+}
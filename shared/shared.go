@@ -3,6 +3,9 @@
 package shared
 
 import (
+	"context"
+
+	"github.com/hashicorp/go-plugin"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/protocol"
 	"github.com/williamokano/hashicorp-plugin-example/pkg/types"
 )
@@ -17,6 +20,25 @@ type (
 	Plugin            = types.Plugin
 	VersionedPlugin   = types.VersionedPlugin
 	PluginMetadata    = types.PluginMetadata
+	Timeouts          = types.Timeouts
+	Dependencies      = types.Dependencies
+	Control           = types.Control
+	Signal            = types.Signal
+	Host              = types.Host
+	PluginError       = types.PluginError
+	ErrorCode         = types.ErrorCode
+	Configurable      = types.Configurable
+	Initializer       = types.Initializer
+	Shutdowner        = types.Shutdowner
+	HealthChecker     = types.HealthChecker
+	Health            = types.Health
+	HealthStatus      = types.HealthStatus
+	Blob              = types.Blob
+	Command           = types.Command
+	CommandFlag       = types.CommandFlag
+	CommandRequest    = types.CommandRequest
+	CommandProvider   = types.CommandProvider
+	FlagType          = types.FlagType
 )
 
 // Re-export event type constants
@@ -27,10 +49,81 @@ const (
 	EventScheduled = types.EventScheduled
 )
 
+// Re-export control signal constants
+const (
+	SignalContinue = types.SignalContinue
+	SignalStop     = types.SignalStop
+	SignalReject   = types.SignalReject
+)
+
+// Re-export log levels for Host.Log
+const (
+	LogDebug = types.LogDebug
+	LogInfo  = types.LogInfo
+	LogWarn  = types.LogWarn
+	LogError = types.LogError
+)
+
+// Re-export plugin error codes
+const (
+	ErrorCodeInternal     = types.ErrorCodeInternal
+	ErrorCodeInvalidInput = types.ErrorCodeInvalidInput
+	ErrorCodeRejected     = types.ErrorCodeRejected
+	ErrorCodeUnavailable  = types.ErrorCodeUnavailable
+	ErrorCodeTimeout      = types.ErrorCodeTimeout
+	ErrorCodeTransport    = types.ErrorCodeTransport
+)
+
+// Re-export command flag types
+const (
+	FlagString = types.FlagString
+	FlagBool   = types.FlagBool
+	FlagInt    = types.FlagInt
+)
+
+// Re-export health statuses
+const (
+	HealthServing   = types.HealthServing
+	HealthDegraded  = types.HealthDegraded
+	HealthUnhealthy = types.HealthUnhealthy
+	HealthUnknown   = types.HealthUnknown
+)
+
+// NewError returns a classified plugin error
+func NewError(code ErrorCode, message string, cause error) *PluginError {
+	return types.NewError(code, message, cause)
+}
+
+// ErrNoHost is returned by host services when the CLI offers none
+var ErrNoHost = types.ErrNoHost
+
+// ErrBlobNotFound is returned when opening a blob the CLI does not hold
+var ErrBlobNotFound = types.ErrBlobNotFound
+
+// HostFrom returns the host services available to the current plugin call
+func HostFrom(ctx context.Context) (Host, bool) {
+	return types.HostFrom(ctx)
+}
+
 // Re-export protocol elements
 var (
-	Handshake = protocol.Handshake
-	PluginMap = protocol.PluginMap
+	Handshake        = protocol.Handshake
+	PluginMap        = protocol.PluginMap
+	VersionedPlugins = protocol.VersionedPlugins
+)
+
+type (
+	GRPCPlugin   = protocol.GRPCPlugin
+	GRPCPluginV1 = protocol.GRPCPluginV1
 )
 
-type GRPCPlugin = protocol.GRPCPlugin
+// PluginSets returns the plugin sets serving impl over every supported protocol version
+func PluginSets(impl VersionedPlugin) map[int]plugin.PluginSet {
+	return protocol.PluginSets(impl)
+}
+
+// BundleSets returns the plugin sets serving several plugins from one process,
+// keyed by their name in the bundle, over every supported protocol version
+func BundleSets(impls map[string]VersionedPlugin) map[int]plugin.PluginSet {
+	return protocol.BundleSets(impls)
+}